@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+const answersFileFlag = "answers"
+
+var (
+	initAnswersCmd = &cobra.Command{
+		Use:   "init-answers gitRepository",
+		Short: "write an answers file for a template",
+		Long:  `Given gitRepository containing a template, write an answers file listing every prompt of the template with its help text, choices and default answer commented out.  Uncomment and change the answers, then create projects without prompting using --answers.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := args[0]
+			s, err := scafall.NewScafall(url)
+			if err != nil {
+				return err
+			}
+			subPathVal, err := cmd.Flags().GetString(subPath)
+			if err == nil {
+				scafall.WithSubPath(subPathVal)(&s)
+			}
+			gitRefVal, err := cmd.Flags().GetString(gitRefFlag)
+			if err == nil && gitRefVal != "" {
+				scafall.WithGitRef(gitRefVal)(&s)
+			}
+			offlineVal, err := cmd.Flags().GetBool(offlineFlag)
+			if err == nil {
+				scafall.WithOffline(offlineVal)(&s)
+			}
+			submodulesVal, err := cmd.Flags().GetBool(submodulesFlag)
+			if err == nil {
+				scafall.WithSubmodules(submodulesVal)(&s)
+			}
+			mirrorsVal, err := cmd.Flags().GetStringSlice(mirrorFlag)
+			if err == nil && len(mirrorsVal) != 0 {
+				scafall.WithMirrors(mirrorsVal...)(&s)
+			}
+			proxyVal, err := cmd.Flags().GetString(proxyFlag)
+			if err == nil {
+				scafall.WithProxy(proxyVal)(&s)
+			}
+			caBundleVal, err := cmd.Flags().GetString(caBundleFlag)
+			if err == nil {
+				scafall.WithCABundle(caBundleVal)(&s)
+			}
+			hostKeyOpts, err := hostKeyCheckingOptions(cmd)
+			if err != nil {
+				return err
+			}
+			for _, opt := range hostKeyOpts {
+				opt(&s)
+			}
+			answersFile, err := cmd.Flags().GetString(planFileFlag)
+			if err != nil {
+				return err
+			}
+
+			// answers already written by the end-user are never overwritten
+			f, err := os.OpenFile(answersFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			templates, err := s.WriteAnswers(f)
+			if err == nil && templates != nil {
+				err = fmt.Errorf("%s is a collection of templates, choose one of %s with --%s", url, strings.Join(templates, ", "), subPath)
+			}
+			if err != nil {
+				f.Close()
+				os.Remove(answersFile)
+				return err
+			}
+			if jsonMode(cmd) {
+				return writeJSON(jsonAnswers{AnswersFile: answersFile})
+			}
+			fmt.Printf("answers for %s written to %s\n", url, answersFile)
+			return nil
+		},
+	}
+
+	validateAnswersCmd = &cobra.Command{
+		Use:   "validate-answers gitRepository",
+		Short: "check an answers file against a template",
+		Long:  `Given gitRepository containing a template, check the answers file without creating a project.  Every answer of the wrong type, that is not one of the choices or that does not match the pattern of its prompt, every answer to a prompt the template does not have, and every required prompt without an answer or default is reported.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := args[0]
+			s, err := scafall.NewScafall(url, jsonOptions(cmd)...)
+			if err != nil {
+				return err
+			}
+			argumentsVal, err := cmd.Flags().GetStringToString(argumentsFlag)
+			if err == nil {
+				scafall.WithArguments(argumentsVal)(&s)
+			}
+			subPathVal, err := cmd.Flags().GetString(subPath)
+			if err == nil {
+				scafall.WithSubPath(subPathVal)(&s)
+			}
+			gitRefVal, err := cmd.Flags().GetString(gitRefFlag)
+			if err == nil && gitRefVal != "" {
+				scafall.WithGitRef(gitRefVal)(&s)
+			}
+			offlineVal, err := cmd.Flags().GetBool(offlineFlag)
+			if err == nil {
+				scafall.WithOffline(offlineVal)(&s)
+			}
+			submodulesVal, err := cmd.Flags().GetBool(submodulesFlag)
+			if err == nil {
+				scafall.WithSubmodules(submodulesVal)(&s)
+			}
+			mirrorsVal, err := cmd.Flags().GetStringSlice(mirrorFlag)
+			if err == nil && len(mirrorsVal) != 0 {
+				scafall.WithMirrors(mirrorsVal...)(&s)
+			}
+			proxyVal, err := cmd.Flags().GetString(proxyFlag)
+			if err == nil {
+				scafall.WithProxy(proxyVal)(&s)
+			}
+			caBundleVal, err := cmd.Flags().GetString(caBundleFlag)
+			if err == nil {
+				scafall.WithCABundle(caBundleVal)(&s)
+			}
+			hostKeyOpts, err := hostKeyCheckingOptions(cmd)
+			if err != nil {
+				return err
+			}
+			for _, opt := range hostKeyOpts {
+				opt(&s)
+			}
+			providerOpts, err := valueProviderOptions(cmd)
+			if err != nil {
+				return err
+			}
+			for _, opt := range providerOpts {
+				opt(&s)
+			}
+			answersFile, err := cmd.Flags().GetString(answersFileFlag)
+			if err != nil {
+				return err
+			}
+			answers, err := scafall.ReadAnswers(answersFile)
+			if err != nil {
+				return err
+			}
+			scafall.WithAnswers(answers)(&s)
+			for _, opt := range choiceMatchingOptions(cmd) {
+				opt(&s)
+			}
+
+			return reportAnswersCheck(cmd, url, answersFile, s.CheckAnswers())
+		},
+	}
+)
+
+// Report every problem found in an answers file, failing when there are any
+func reportAnswersCheck(cmd *cobra.Command, url string, answersFile string, err error) error {
+	var answerErr scafall.AnswerError
+	if err != nil && !errors.As(err, &answerErr) {
+		return err
+	}
+	if jsonMode(cmd) {
+		if err := writeJSON(jsonAnswersCheck{AnswersFile: answersFile, Valid: err == nil, Problems: answerErr.Problems}); err != nil {
+			return err
+		}
+	} else if err == nil {
+		fmt.Printf("%s answers %s\n", answersFile, url)
+	} else {
+		fmt.Printf("%s does not answer %s:\n", answersFile, url)
+		for _, problem := range answerErr.Problems {
+			fmt.Printf("\t%s\n", problem)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s is not a valid answers file for %s", answersFile, url)
+	}
+	return nil
+}
+
+func init() {
+	initAnswersCmd.Flags().StringP(planFileFlag, "o", "answers.toml", "write the answers to the provided file, which must not exist")
+	initAnswersCmd.Flags().StringP(subPath, "s", "", "use sub directory in template project to scaffold project")
+	initAnswersCmd.Flags().StringP(gitRefFlag, "r", "", "use a git branch, tag or commit of the template repository")
+	initAnswersCmd.Flags().Bool(offlineFlag, false, "use the cached copy of the template rather than fetching it")
+	initAnswersCmd.Flags().Bool(submodulesFlag, true, "clone the git submodules of the template repository")
+	initAnswersCmd.Flags().StringSlice(mirrorFlag, nil, "fetch the template from the provided mirror when it cannot be fetched from the url")
+	initAnswersCmd.Flags().String(proxyFlag, "", "fetch templates through the provided HTTP proxy; defaults to HTTPS_PROXY")
+	initAnswersCmd.Flags().String(caBundleFlag, "", "trust the certificates in the provided PEM file when fetching templates")
+	addHostKeyCheckingFlag(initAnswersCmd)
+	validateAnswersCmd.Flags().StringP(answersFileFlag, "f", "answers.toml", "check the answers in the provided file")
+	validateAnswersCmd.Flags().StringToString(argumentsFlag, map[string]string{}, "provide overrides as key-value pairs")
+	validateAnswersCmd.Flags().StringP(subPath, "s", "", "use sub directory in template project to scaffold project")
+	validateAnswersCmd.Flags().StringP(gitRefFlag, "r", "", "use a git branch, tag or commit of the template repository")
+	validateAnswersCmd.Flags().Bool(offlineFlag, false, "use the cached copy of the template rather than fetching it")
+	validateAnswersCmd.Flags().Bool(submodulesFlag, true, "clone the git submodules of the template repository")
+	validateAnswersCmd.Flags().StringSlice(mirrorFlag, nil, "fetch the template from the provided mirror when it cannot be fetched from the url")
+	validateAnswersCmd.Flags().String(proxyFlag, "", "fetch templates through the provided HTTP proxy; defaults to HTTPS_PROXY")
+	validateAnswersCmd.Flags().String(caBundleFlag, "", "trust the certificates in the provided PEM file when fetching templates")
+	addHostKeyCheckingFlag(validateAnswersCmd)
+	addValueProviderFlag(validateAnswersCmd)
+	addChoiceMatchingFlags(validateAnswersCmd)
+}
+
+// Add the flag that reads answers from an answers file
+func addAnswersFlag(cmd *cobra.Command) {
+	cmd.Flags().String(answersFileFlag, "", "answer prompts with the answers in the provided file, such as a file written by init-answers")
+}
+
+// Read the answers file flag of cmd as options
+func answersOptions(cmd *cobra.Command) ([]scafall.Option, error) {
+	answersFile, err := cmd.Flags().GetString(answersFileFlag)
+	if err != nil || answersFile == "" {
+		return nil, nil
+	}
+	answers, err := scafall.ReadAnswers(answersFile)
+	if err != nil {
+		return nil, err
+	}
+	return []scafall.Option{scafall.WithAnswers(answers)}, nil
+}
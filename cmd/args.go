@@ -24,8 +24,50 @@ var (
 			if err == nil {
 				scafall.WithSubPath(subPathVal)(&s)
 			}
+			gitRefVal, err := cmd.Flags().GetString(gitRefFlag)
+			if err == nil && gitRefVal != "" {
+				scafall.WithGitRef(gitRefVal)(&s)
+			}
+			offlineVal, err := cmd.Flags().GetBool(offlineFlag)
+			if err == nil {
+				scafall.WithOffline(offlineVal)(&s)
+			}
+			submodulesVal, err := cmd.Flags().GetBool(submodulesFlag)
+			if err == nil {
+				scafall.WithSubmodules(submodulesVal)(&s)
+			}
+			mirrorsVal, err := cmd.Flags().GetStringSlice(mirrorFlag)
+			if err == nil && len(mirrorsVal) != 0 {
+				scafall.WithMirrors(mirrorsVal...)(&s)
+			}
+			proxyVal, err := cmd.Flags().GetString(proxyFlag)
+			if err == nil {
+				scafall.WithProxy(proxyVal)(&s)
+			}
+			caBundleVal, err := cmd.Flags().GetString(caBundleFlag)
+			if err == nil {
+				scafall.WithCABundle(caBundleVal)(&s)
+			}
+			hostKeyOpts, err := hostKeyCheckingOptions(cmd)
+			if err != nil {
+				return err
+			}
+			for _, opt := range hostKeyOpts {
+				opt(&s)
+			}
 
-			description, sArgs, _ := s.TemplateArguments()
+			if jsonMode(cmd) {
+				templates, prompts, err := s.TemplatePrompts()
+				if err != nil {
+					return err
+				}
+				return writeJSON(jsonArguments{Templates: templates, Prompts: newJSONPrompts(prompts)})
+			}
+
+			description, sArgs, err := s.TemplateArguments()
+			if err != nil {
+				return err
+			}
 			fmt.Println(description)
 			for _, a := range sArgs {
 				fmt.Printf("\t%s\n", a)
@@ -37,4 +79,11 @@ var (
 
 func init() {
 	argsCmd.Flags().StringP(subPath, "s", "", "use sub directory in template project to scaffold project")
+	argsCmd.Flags().StringP(gitRefFlag, "r", "", "use a git branch, tag or commit of the template repository")
+	argsCmd.Flags().Bool(offlineFlag, false, "use the cached copy of the template rather than fetching it")
+	argsCmd.Flags().Bool(submodulesFlag, true, "clone the git submodules of the template repository")
+	argsCmd.Flags().StringSlice(mirrorFlag, nil, "fetch the template from the provided mirror when it cannot be fetched from the url")
+	argsCmd.Flags().String(proxyFlag, "", "fetch templates through the provided HTTP proxy; defaults to HTTPS_PROXY")
+	argsCmd.Flags().String(caBundleFlag, "", "trust the certificates in the provided PEM file when fetching templates")
+	addHostKeyCheckingFlag(argsCmd)
 }
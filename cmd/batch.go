@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+var (
+	batchCmd = &cobra.Command{
+		Use:   "batch specFile",
+		Short: "scaffold several projects from a spec file",
+		Long:  `Given specFile containing a list of scaffold jobs, create each project and report the outcome of every job.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := scafall.ScaffoldBatch(args[0])
+			if err != nil {
+				return err
+			}
+
+			if jsonMode(cmd) {
+				return reportBatchJSON(results)
+			}
+
+			failed := 0
+			for _, r := range results {
+				if r.Err != nil {
+					failed++
+					fmt.Printf("\tfailed\t%s -> %s: %s\n", r.URL, r.OutputFolder, r.Err)
+				} else {
+					fmt.Printf("\tcreated\t%s -> %s\n", r.URL, r.OutputFolder)
+				}
+			}
+			fmt.Printf("%d of %d scaffolds succeeded\n", len(results)-failed, len(results))
+			if failed > 0 {
+				return fmt.Errorf("%d scaffolds failed", failed)
+			}
+			return nil
+		},
+	}
+)
+
+func reportBatchJSON(results []scafall.BatchResult) error {
+	out := jsonBatch{Results: make([]jsonBatchResult, len(results))}
+	for i, r := range results {
+		out.Results[i] = jsonBatchResult{URL: r.URL, OutputFolder: r.OutputFolder, Error: errorString(r.Err)}
+		if r.Err != nil {
+			out.Failed++
+		} else {
+			out.Succeeded++
+		}
+	}
+	if err := writeJSON(out); err != nil {
+		return err
+	}
+	if out.Failed > 0 {
+		return fmt.Errorf("%d scaffolds failed", out.Failed)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+var (
+	browseCmd = &cobra.Command{
+		Use:   "browse [registry-url]",
+		Short: "browse a registry or collection of templates and scaffold a project",
+		Long:  `Browse the templates listed by registry-url, a .toml registry index or a collection of templates, full-screen.  Type to search the templates, preview the README and prompts of a template and scaffold a project from it.  Without a registry-url, browse the recently used templates.`,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registryURL := ""
+			if len(args) == 1 {
+				registryURL = args[0]
+			}
+			options := jsonOptions(cmd)
+			offlineVal, err := cmd.Flags().GetBool(offlineFlag)
+			if err == nil {
+				options = append(options, scafall.WithOffline(offlineVal))
+			}
+			proxyVal, err := cmd.Flags().GetString(proxyFlag)
+			if err == nil {
+				options = append(options, scafall.WithProxy(proxyVal))
+			}
+			caBundleVal, err := cmd.Flags().GetString(caBundleFlag)
+			if err == nil {
+				options = append(options, scafall.WithCABundle(caBundleVal))
+			}
+
+			entries, err := scafall.ReadRegistry(registryURL, options...)
+			if err != nil {
+				return err
+			}
+			entry, err := scafall.Browse(entries, options...)
+			if err != nil || entry.URL == "" {
+				return err
+			}
+			return scaffold(cmd, entry.URL, scafall.WithTemplate(entry.Template))
+		},
+	}
+)
+
+func init() {
+	browseCmd.Flags().StringP(outputFolderFlag, "p", "", "scaffold project in the provided output directory, which may use template variables; defaults to a directory named after the project")
+	browseCmd.Flags().StringToStringP(argumentsFlag, "o", map[string]string{}, "provide overrides as key-value pairs")
+	browseCmd.Flags().Bool(offlineFlag, false, "use the cached copy of the template rather than fetching it")
+	browseCmd.Flags().String(proxyFlag, "", "fetch templates through the provided HTTP proxy; defaults to HTTPS_PROXY")
+	browseCmd.Flags().String(caBundleFlag, "", "trust the certificates in the provided PEM file when fetching templates")
+	browseCmd.Flags().BoolP(yesFlag, "y", false, "create the project without confirming the summary shown after prompting")
+	browseCmd.Flags().String(languageFlag, "", "ask prompts in the provided language, such as fr or pt-BR, when the template translates them; defaults to LANG")
+	addHostKeyCheckingFlag(browseCmd)
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+const (
+	ignoreCaseFlag = "ignore-case"
+	trimSpaceFlag  = "trim-space"
+)
+
+// Add the flags that control how values are matched against choices
+func addChoiceMatchingFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(ignoreCaseFlag, false, "match arguments and answers that differ from a choice of their prompt only in case")
+	cmd.Flags().Bool(trimSpaceFlag, false, "match arguments and answers with leading or trailing white space against the choices of their prompt")
+}
+
+// Read the choice matching flags of cmd as options
+func choiceMatchingOptions(cmd *cobra.Command) []scafall.Option {
+	matching := scafall.ChoiceMatching{}
+	ignoreCaseVal, err := cmd.Flags().GetBool(ignoreCaseFlag)
+	if err == nil {
+		matching.IgnoreCase = ignoreCaseVal
+	}
+	trimSpaceVal, err := cmd.Flags().GetBool(trimSpaceFlag)
+	if err == nil {
+		matching.TrimSpace = trimSpaceVal
+	}
+	return []scafall.Option{scafall.WithChoiceMatching(matching)}
+}
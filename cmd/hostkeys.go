@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+const hostKeyCheckingFlag = "host-key-checking"
+
+// Add the flag that controls how the keys of SSH servers are checked
+func addHostKeyCheckingFlag(cmd *cobra.Command) {
+	cmd.Flags().String(hostKeyCheckingFlag, scafall.HostKeyStrict, "check the keys of SSH servers against known_hosts: strict, accept-new to add servers not yet known, or off")
+}
+
+// Read the host key checking flag of cmd as options
+func hostKeyCheckingOptions(cmd *cobra.Command) ([]scafall.Option, error) {
+	mode, err := cmd.Flags().GetString(hostKeyCheckingFlag)
+	if err != nil {
+		return nil, nil
+	}
+	switch mode {
+	case scafall.HostKeyStrict, scafall.HostKeyAcceptNew, scafall.HostKeyOff:
+	default:
+		return nil, fmt.Errorf("--%s must be one of %s, %s or %s", hostKeyCheckingFlag, scafall.HostKeyStrict, scafall.HostKeyAcceptNew, scafall.HostKeyOff)
+	}
+	return []scafall.Option{scafall.WithHostKeyChecking(mode)}, nil
+}
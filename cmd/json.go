@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+const jsonFlag = "json"
+
+// jsonWritten records that the command wrote its outcome to stdout, an error
+// is then not written a second time
+var jsonWritten bool
+
+type jsonError struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+	File  string `json:"file,omitempty"`
+}
+
+type jsonResult struct {
+	OutputFolder string            `json:"outputFolder"`
+	Template     string            `json:"template,omitempty"`
+	Variables    map[string]string `json:"variables"`
+	Renames      map[string]string `json:"renames,omitempty"`
+	Warnings     []jsonWarning     `json:"warnings,omitempty"`
+	Tree         []jsonTreeEntry   `json:"tree,omitempty"`
+}
+
+type jsonTreeEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Mode string `json:"mode"`
+}
+
+type jsonWarning struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+type jsonPrompt struct {
+	Name           string   `json:"name"`
+	Prompt         string   `json:"prompt"`
+	Help           string   `json:"help,omitempty"`
+	Required       bool     `json:"required"`
+	Default        string   `json:"default"`
+	Choices        []string `json:"choices,omitempty"`
+	Labels         []string `json:"labels,omitempty"`
+	Type           string   `json:"type,omitempty"`
+	Format         string   `json:"format,omitempty"`
+	Pattern        string   `json:"pattern,omitempty"`
+	PatternMessage string   `json:"patternMessage,omitempty"`
+	ErrorMessage   string   `json:"errorMessage,omitempty"`
+	When           string   `json:"when,omitempty"`
+	Group          string   `json:"group,omitempty"`
+	Record         bool     `json:"record"`
+	Exists         string   `json:"exists,omitempty"`
+	MinLength      int      `json:"minLength,omitempty"`
+	MaxLength      int      `json:"maxLength,omitempty"`
+}
+
+type jsonArguments struct {
+	Templates []string     `json:"templates,omitempty"`
+	Prompts   []jsonPrompt `json:"prompts,omitempty"`
+}
+
+type jsonPlan struct {
+	PlanFile  string            `json:"planFile"`
+	URL       string            `json:"url"`
+	Ref       string            `json:"ref,omitempty"`
+	SubPath   string            `json:"subPath,omitempty"`
+	Template  string            `json:"template,omitempty"`
+	Digest    string            `json:"digest"`
+	Variables map[string]string `json:"variables"`
+}
+
+type jsonAnswers struct {
+	AnswersFile string `json:"answersFile"`
+}
+
+type jsonAnswersCheck struct {
+	AnswersFile string   `json:"answersFile"`
+	Valid       bool     `json:"valid"`
+	Problems    []string `json:"problems,omitempty"`
+}
+
+type jsonBatchResult struct {
+	URL          string `json:"url"`
+	OutputFolder string `json:"outputFolder"`
+	Error        string `json:"error,omitempty"`
+}
+
+type jsonBatch struct {
+	Results   []jsonBatchResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
+
+type jsonWorkspaceComponent struct {
+	URL  string `json:"url"`
+	Path string `json:"path"`
+	jsonResult
+}
+
+type jsonWorkspace struct {
+	Components []jsonWorkspaceComponent `json:"components"`
+}
+
+type jsonMatrixResult struct {
+	Arguments    map[string]string `json:"arguments"`
+	OutputFolder string            `json:"outputFolder"`
+	Output       string            `json:"output,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+type jsonMatrix struct {
+	Results []jsonMatrixResult `json:"results"`
+	Passed  int                `json:"passed"`
+	Failed  int                `json:"failed"`
+}
+
+// Report whether cmd should write its outcome to stdout as JSON
+func jsonMode(cmd *cobra.Command) bool {
+	val, err := cmd.Flags().GetBool(jsonFlag)
+	return err == nil && val
+}
+
+// Options that keep stdout free for JSON output, prompts are written to
+// stderr instead
+func jsonOptions(cmd *cobra.Command) []scafall.Option {
+	if !jsonMode(cmd) {
+		return nil
+	}
+	return []scafall.Option{scafall.WithPromptOutput(os.Stderr)}
+}
+
+func writeJSON(v interface{}) error {
+	jsonWritten = true
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func newJSONError(err error) jsonError {
+	result := jsonError{Error: err.Error()}
+	if code, ok := scafall.CodeOf(err); ok {
+		result.Code = string(code)
+	}
+	var fileErr scafall.FileError
+	if errors.As(err, &fileErr) {
+		result.File = fileErr.FilePath
+	}
+	return result
+}
+
+func newJSONResult(result scafall.Result) (jsonResult, error) {
+	outputFolder, err := filepath.Abs(result.OutputFolder)
+	if err != nil {
+		return jsonResult{}, err
+	}
+	out := jsonResult{OutputFolder: outputFolder, Template: result.Template, Variables: result.Variables, Renames: result.Renames}
+	for _, warning := range result.Warnings {
+		out.Warnings = append(out.Warnings, jsonWarning{Path: warning.Path, Message: warning.Message})
+	}
+	for _, entry := range result.Tree {
+		out.Tree = append(out.Tree, jsonTreeEntry{Path: entry.Path, Size: entry.Size, Mode: entry.Mode.String()})
+	}
+	return out, nil
+}
+
+// Write the outcome of scaffolding a project, errors are written by Execute
+func reportJSON(result scafall.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	out, err := newJSONResult(result)
+	if err != nil {
+		return err
+	}
+	return writeJSON(out)
+}
+
+func newJSONPrompts(prompts []scafall.Prompt) []jsonPrompt {
+	out := make([]jsonPrompt, len(prompts))
+	for i, p := range prompts {
+		out[i] = jsonPrompt{
+			Name:           p.Name,
+			Prompt:         p.Prompt,
+			Help:           p.Help,
+			Required:       p.Required,
+			Default:        p.Default,
+			Choices:        p.Choices,
+			Labels:         p.Labels,
+			Type:           p.Type,
+			Format:         p.Format,
+			Pattern:        p.Pattern,
+			PatternMessage: p.PatternMessage,
+			ErrorMessage:   p.ErrorMessage,
+			When:           p.When,
+			Group:          p.Group,
+			Record:         p.Recorded(),
+			Exists:         p.Exists,
+			MinLength:      p.MinLength,
+			MaxLength:      p.MaxLength,
+		}
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+const (
+	fileModeFlag   = "file-mode"
+	dirModeFlag    = "dir-mode"
+	clampModesFlag = "clamp-modes"
+)
+
+// Add the flags that override the permissions of generated files and folders
+func addModeFlags(cmd *cobra.Command) {
+	cmd.Flags().String(fileModeFlag, "", "set the permissions of every generated file to the provided octal mode, such as 0644")
+	cmd.Flags().String(dirModeFlag, "", "set the permissions of every generated folder to the provided octal mode, such as 0755")
+	cmd.Flags().Bool(clampModesFlag, false, "remove permissions outside of --file-mode and --dir-mode rather than setting the modes")
+}
+
+// Read the permission flags of cmd as options
+func modeOptions(cmd *cobra.Command) ([]scafall.Option, error) {
+	options := []scafall.Option{}
+	fileModeVal, err := cmd.Flags().GetString(fileModeFlag)
+	if err == nil && fileModeVal != "" {
+		mode, err := scafall.ParseMode(fileModeVal)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, scafall.WithFileMode(mode))
+	}
+	dirModeVal, err := cmd.Flags().GetString(dirModeFlag)
+	if err == nil && dirModeVal != "" {
+		mode, err := scafall.ParseMode(dirModeVal)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, scafall.WithDirMode(mode))
+	}
+	clampModesVal, err := cmd.Flags().GetBool(clampModesFlag)
+	if err == nil {
+		options = append(options, scafall.WithClampModes(clampModesVal))
+	}
+	return options, nil
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+const (
+	textOutput   = "text"
+	githubOutput = "github"
+)
+
+var outputFormats = []string{textOutput, githubOutput}
+
+func validateOutputFormat(format string) error {
+	for _, f := range outputFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %s; expected one of %s", format, strings.Join(outputFormats, ", "))
+}
+
+// Report the outcome of scaffolding a project in the requested format
+func reportScaffold(format string, result scafall.Result, err error) error {
+	if format == githubOutput {
+		return reportGitHub(result, err)
+	}
+	if err == nil {
+		reportWarnings(result)
+	}
+	return err
+}
+
+// List the parts of the template that were skipped, last of all so that they
+// are not lost among the other output of the run
+func reportWarnings(result scafall.Result) {
+	if len(result.Warnings) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "warning: the project in %s may be incomplete; the template uses features that were skipped:\n", result.OutputFolder)
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "\t%s\n", warning)
+	}
+}
+
+// Draw the files and folders of a project rendered in a dry run, which is not
+// created
+func reportTree(result scafall.Result) error {
+	if err := scafall.WriteTree(os.Stdout, result.OutputFolder, result.Tree); err != nil {
+		return err
+	}
+	reportWarnings(result)
+	return nil
+}
+
+// List the paths renamed by template variables and the paths they were
+// rendered to
+func reportRenames(result scafall.Result) {
+	paths := make([]string, 0, len(result.Renames))
+	for path := range result.Renames {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Printf("\trenamed\t%s -> %s\n", path, result.Renames[path])
+	}
+}
+
+// Emit GitHub Actions workflow commands and write step outputs to the file
+// named by GITHUB_OUTPUT
+func reportGitHub(result scafall.Result, err error) error {
+	if err != nil {
+		properties := []string{}
+		var fileErr scafall.FileError
+		if errors.As(err, &fileErr) {
+			properties = append(properties, "file="+escapeProperty(fileErr.FilePath))
+		}
+		if code, ok := scafall.CodeOf(err); ok {
+			properties = append(properties, "title="+escapeProperty(string(code)))
+		}
+		command := "::error"
+		if len(properties) != 0 {
+			command += " " + strings.Join(properties, ",")
+		}
+		fmt.Printf("%s::%s\n", command, escapeData(err.Error()))
+		return err
+	}
+
+	outputFolder, err := filepath.Abs(result.OutputFolder)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("::notice::created project in %s\n", escapeData(outputFolder))
+	for _, warning := range result.Warnings {
+		fmt.Printf("::warning title=%s::%s\n", escapeProperty(warning.Path), escapeData(warning.Message))
+	}
+
+	outputFile := os.Getenv("GITHUB_OUTPUT")
+	if outputFile == "" {
+		return nil
+	}
+	f, err := os.OpenFile(outputFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	outputs := map[string]string{"path": outputFolder}
+	if result.Template != "" {
+		outputs["template"] = result.Template
+	}
+	for name, value := range result.Variables {
+		outputs["var_"+name] = value
+	}
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := writeGitHubOutput(f, name, outputs[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Values may span multiple lines so use the delimiter syntax for every output
+func writeGitHubOutput(w io.Writer, name string, value string) error {
+	delimiter := "SCAFALL_EOF"
+	for strings.Contains(value, delimiter) {
+		delimiter += "_"
+	}
+	_, err := fmt.Fprintf(w, "%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter)
+	return err
+}
+
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+const planFileFlag = "output"
+
+var (
+	planCmd = &cobra.Command{
+		Use:   "plan gitRepository",
+		Short: "record answers to a template in a plan file",
+		Long:  `Given gitRepository containing a template, prompt for the template arguments and record them, with a digest of the template, in a plan file.  The plan file can be used by apply to create the project later.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := args[0]
+			s, err := scafall.NewScafall(url, jsonOptions(cmd)...)
+			if err != nil {
+				return err
+			}
+			options, err := scaffoldOptions(cmd)
+			if err != nil {
+				return err
+			}
+			for _, opt := range options {
+				opt(&s)
+			}
+			planFile, err := cmd.Flags().GetString(planFileFlag)
+			if err != nil {
+				return err
+			}
+
+			plan, err := s.Plan()
+			if err != nil {
+				return err
+			}
+			err = scafall.WritePlan(plan, planFile)
+			if err != nil {
+				return err
+			}
+			if jsonMode(cmd) {
+				return writeJSON(jsonPlan{
+					PlanFile:  planFile,
+					URL:       plan.URL,
+					Ref:       plan.Ref,
+					SubPath:   plan.SubPath,
+					Template:  plan.Template,
+					Digest:    plan.Digest,
+					Variables: plan.Variables,
+				})
+			}
+			fmt.Printf("plan for %s (%s) written to %s\n", plan.URL, plan.Digest, planFile)
+			return nil
+		},
+	}
+
+	applyCmd = &cobra.Command{
+		Use:   "apply planFile",
+		Short: "create a project from a plan file",
+		Long:  `Given planFile created by plan, create the project without prompting.  No project is created if the template has changed since the plan was created.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plan, err := scafall.ReadPlan(args[0])
+			if err != nil {
+				return err
+			}
+			options, err := scaffoldOptions(cmd)
+			if err != nil {
+				return err
+			}
+			options = append(options, scafall.WithCheckpoints(true))
+			result, err := scafall.ApplyPlan(plan, options...)
+			if jsonMode(cmd) {
+				return reportJSON(result, err)
+			}
+			if err == nil {
+				reportWarnings(result)
+			}
+			return err
+		},
+	}
+)
+
+func init() {
+	planCmd.Flags().StringP(planFileFlag, "o", "plan.toml", "write the plan to the provided file")
+	planCmd.Flags().StringToString(argumentsFlag, map[string]string{}, "provide overrides as key-value pairs")
+	planCmd.Flags().StringP(subPath, "s", "", "use sub directory in template project to scaffold project")
+	planCmd.Flags().StringP(gitRefFlag, "r", "", "use a git branch, tag or commit of the template repository")
+	planCmd.Flags().Bool(offlineFlag, false, "use the cached copy of the template rather than fetching it")
+	planCmd.Flags().Bool(submodulesFlag, true, "clone the git submodules of the template repository")
+	planCmd.Flags().StringSlice(mirrorFlag, nil, "fetch the template from the provided mirror when it cannot be fetched from the url")
+	planCmd.Flags().String(proxyFlag, "", "fetch templates through the provided HTTP proxy; defaults to HTTPS_PROXY")
+	planCmd.Flags().String(caBundleFlag, "", "trust the certificates in the provided PEM file when fetching templates")
+	planCmd.Flags().String(checksumFlag, "", "fail unless the template matches the provided sha256:<hex> digest")
+	planCmd.Flags().String(languageFlag, "", "ask prompts in the provided language, such as fr or pt-BR, when the template translates them; defaults to LANG")
+	planCmd.Flags().Bool(noInputFlag, false, "never prompt; variables not provided with --arg take their default value and missing required variables are listed")
+	addValueProviderFlag(planCmd)
+	addAnswersFlag(planCmd)
+	addChoiceMatchingFlags(planCmd)
+	addHostKeyCheckingFlag(planCmd)
+	addSeedFlag(planCmd)
+	applyCmd.Flags().StringP(outputFolderFlag, "p", "", "scaffold project in the provided output directory, which may use template variables; defaults to a directory named after the project")
+	applyCmd.Flags().StringToString(argumentsFlag, map[string]string{}, "provide the answers to prompts that are not recorded in the plan as key-value pairs")
+	applyCmd.Flags().Bool(offlineFlag, false, "use the cached copy of the template rather than fetching it")
+	applyCmd.Flags().String(manifestFlag, "", "write a checksum of every created file to the provided manifest file")
+	applyCmd.Flags().Bool(changedOnlyFlag, false, "only write files that have changed since the manifest was written")
+	applyCmd.Flags().StringSlice(mirrorFlag, nil, "fetch the template from the provided mirror when it cannot be fetched from the url")
+	applyCmd.Flags().String(proxyFlag, "", "fetch templates through the provided HTTP proxy; defaults to HTTPS_PROXY")
+	applyCmd.Flags().String(caBundleFlag, "", "trust the certificates in the provided PEM file when fetching templates")
+	applyCmd.Flags().Duration(renderTimeoutFlag, scafall.DefaultRenderTimeout, "give up when any one file takes longer than the provided duration to render; 0 disables the limit")
+	applyCmd.Flags().Bool(hardLinksFlag, false, "write binary files with the same content once and hard link the duplicates")
+	applyCmd.Flags().Bool(strictFlag, false, "fail when the template uses variables that are neither prompted for nor given as arguments")
+	addModeFlags(applyCmd)
+	addHostKeyCheckingFlag(applyCmd)
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+// Show fetch progress on a single line of the terminal, progress is not shown
+// when stderr is redirected
+func fetchProgress() func(scafall.FetchEvent) {
+	if fi, err := os.Stderr.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return func(event scafall.FetchEvent) {
+		switch {
+		case event.Done:
+			fmt.Fprint(os.Stderr, "\r\033[K")
+		case event.Percent >= 0:
+			fmt.Fprintf(os.Stderr, "\r\033[Kfetching %s: %s %d%%", event.URL, event.Stage, event.Percent)
+		default:
+			fmt.Fprintf(os.Stderr, "\r\033[Kfetching %s: %s", event.URL, event.Stage)
+		}
+	}
+}
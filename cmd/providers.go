@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+const valueProviderFlag = "value-provider"
+
+// The built in value providers, by the name used on the command line
+var valueProviders = map[string]scafall.ValueProvider{
+	"env":   scafall.EnvProvider{},
+	"file":  scafall.FileProvider{},
+	"vault": scafall.VaultProvider{},
+}
+
+// Add the flag that enables value providers
+func addValueProviderFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSlice(valueProviderFlag, nil, "resolve arguments and overrides of the form provider:key using the provided providers: env, file or vault")
+}
+
+// Read the value provider flag of cmd as options
+func valueProviderOptions(cmd *cobra.Command) ([]scafall.Option, error) {
+	options := []scafall.Option{}
+	names, err := cmd.Flags().GetStringSlice(valueProviderFlag)
+	if err != nil {
+		return options, nil
+	}
+	for _, name := range names {
+		provider, ok := valueProviders[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown value provider %s; expected one of env, file, vault", name)
+		}
+		options = append(options, scafall.WithValueProvider(name, provider))
+	}
+	return options, nil
+}
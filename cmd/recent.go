@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+var (
+	recentCmd = &cobra.Command{
+		Use:   "recent",
+		Short: "scaffold a project from a recently used template",
+		Long:  `Choose one of the recently used templates and create a project from it.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url, err := scafall.ChooseRecentTemplate(jsonOptions(cmd)...)
+			if err != nil {
+				return err
+			}
+			return scaffold(cmd, url)
+		},
+	}
+)
+
+func init() {
+	recentCmd.Flags().StringP(outputFolderFlag, "p", "", "scaffold project in the provided output directory, which may use template variables; defaults to a directory named after the project")
+	recentCmd.Flags().StringToStringP(argumentsFlag, "o", map[string]string{}, "provide overrides as key-value pairs")
+	recentCmd.Flags().Bool(offlineFlag, false, "use the cached copy of the template rather than fetching it")
+	recentCmd.Flags().String(proxyFlag, "", "fetch templates through the provided HTTP proxy; defaults to HTTPS_PROXY")
+	recentCmd.Flags().String(caBundleFlag, "", "trust the certificates in the provided PEM file when fetching templates")
+	recentCmd.Flags().BoolP(yesFlag, "y", false, "create the project without confirming the summary shown after prompting")
+	recentCmd.Flags().String(languageFlag, "", "ask prompts in the provided language, such as fr or pt-BR, when the template translates them; defaults to LANG")
+	addHostKeyCheckingFlag(recentCmd)
+}
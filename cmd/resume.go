@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+var (
+	resumeCmd = &cobra.Command{
+		Use:   "resume",
+		Short: "finish the last project that failed while it was written",
+		Long:  `When writing a project fails, such as when the disk is full, the fetched template and the values given for it are kept.  Once the cause is fixed, resume writes the rest of the project without fetching the template or prompting again.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			argumentsVal, err := cmd.Flags().GetStringToString(argumentsFlag)
+			if err != nil {
+				return err
+			}
+			manifestVal, err := cmd.Flags().GetString(manifestFlag)
+			if err != nil {
+				return err
+			}
+			renderTimeoutVal, err := cmd.Flags().GetDuration(renderTimeoutFlag)
+			if err != nil {
+				return err
+			}
+			hardLinksVal, err := cmd.Flags().GetBool(hardLinksFlag)
+			if err != nil {
+				return err
+			}
+			strictVal, err := cmd.Flags().GetBool(strictFlag)
+			if err != nil {
+				return err
+			}
+			modeOpts, err := modeOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			options := []scafall.Option{
+				scafall.WithArguments(argumentsVal),
+				scafall.WithManifest(manifestVal),
+				scafall.WithRenderTimeout(renderTimeoutVal),
+				scafall.WithHardLinks(hardLinksVal),
+				scafall.WithStrictVariables(strictVal),
+				scafall.WithCheckpoints(true),
+			}
+			result, err := scafall.Resume(append(options, modeOpts...)...)
+			if jsonMode(cmd) {
+				return reportJSON(result, err)
+			}
+			if err == nil {
+				reportWarnings(result)
+			}
+			return err
+		},
+	}
+)
+
+func init() {
+	resumeCmd.Flags().StringToString(argumentsFlag, map[string]string{}, "provide the answers to prompts that are not recorded as key-value pairs")
+	resumeCmd.Flags().String(manifestFlag, "", "write a checksum of every file written by resume to the provided manifest file")
+	resumeCmd.Flags().Duration(renderTimeoutFlag, scafall.DefaultRenderTimeout, "give up when any one file takes longer than the provided duration to render; 0 disables the limit")
+	resumeCmd.Flags().Bool(hardLinksFlag, false, "write binary files with the same content once and hard link the duplicates")
+	resumeCmd.Flags().Bool(strictFlag, false, "fail when the template uses variables that are neither prompted for nor given as arguments")
+	addModeFlags(resumeCmd)
+}
@@ -1,56 +1,281 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	scafall "github.com/buildpacks/scafall/pkg"
 )
 
 const (
-	outputFolderFlag = "path"
-	argumentsFlag    = "arg"
-	subPath          = "sub-path"
+	outputFolderFlag  = "path"
+	argumentsFlag     = "arg"
+	subPath           = "sub-path"
+	outputFormatFlag  = "output-format"
+	gitRefFlag        = "ref"
+	offlineFlag       = "offline"
+	manifestFlag      = "manifest"
+	changedOnlyFlag   = "changed-only"
+	submodulesFlag    = "submodules"
+	proxyFlag         = "proxy"
+	caBundleFlag      = "ca-bundle"
+	checksumFlag      = "checksum"
+	mirrorFlag        = "mirror"
+	renderTimeoutFlag = "render-timeout"
+	hardLinksFlag     = "hard-links"
+	noInputFlag       = "no-input"
+	yesFlag           = "yes"
+	showRenamesFlag   = "show-renames"
+	languageFlag      = "language"
+	strictFlag        = "strict"
+	treeFlag          = "tree"
+
+	// stdinURL reads a template as a tar stream from stdin
+	stdinURL = "-"
 )
 
 var (
 	rootCmd = &cobra.Command{
-		Use:   "scafall gitRepository",
+		Use:   "scafall [gitRepository]",
 		Short: "A project generation tool",
-		Long:  `Scafall creates new project from project templates.`,
-		Args:  cobra.ExactArgs(1),
+		Long:  `Scafall creates new project from project templates.  Without a gitRepository, choose one of the recently used templates.  A gitRepository of - reads the template as a tar stream from stdin, prompts are then not asked and variables take their default values unless provided with --arg.`,
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			url := args[0]
-
-			s, err := scafall.NewScafall(url)
-			if err != nil {
-				return err
-			}
-			outputDirVal, err := cmd.Flags().GetString(outputFolderFlag)
-			if err == nil {
-				scafall.WithOutputFolder(outputDirVal)(&s)
+			if len(args) == 0 {
+				url, err := scafall.ChooseRecentTemplate(jsonOptions(cmd)...)
+				if err != nil {
+					return err
+				}
+				return scaffold(cmd, url)
 			}
-			argumentsVal, err := cmd.Flags().GetStringToString(argumentsFlag)
-			if err == nil {
-				scafall.WithArguments(argumentsVal)(&s)
-			}
-			subPathVal, err := cmd.Flags().GetString(subPath)
-			if err == nil {
-				scafall.WithSubPath(subPathVal)(&s)
-			}
-
-			return s.Scaffold()
+			return scaffold(cmd, args[0])
 		},
 	}
 )
 
+// Scaffold a project from url using the flags of cmd, and any further options
+func scaffold(cmd *cobra.Command, url string, opts ...scafall.Option) error {
+	outputFormat, err := cmd.Flags().GetString(outputFormatFlag)
+	if err != nil {
+		outputFormat = textOutput
+	}
+	if err := validateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+	if jsonMode(cmd) && outputFormat != textOutput {
+		return fmt.Errorf("--%s cannot be used with --%s %s", jsonFlag, outputFormatFlag, outputFormat)
+	}
+
+	var s scafall.Scafall
+	if url == stdinURL {
+		// prompts cannot be answered while the template is read from stdin
+		s, err = scafall.NewScafallFromReader(os.Stdin, append(jsonOptions(cmd), scafall.WithNoPrompt(true))...)
+	} else {
+		s, err = scafall.NewScafall(url, jsonOptions(cmd)...)
+	}
+	if err != nil {
+		return err
+	}
+	options, err := scaffoldOptions(cmd)
+	if err != nil {
+		return err
+	}
+	for _, opt := range options {
+		opt(&s)
+	}
+	// every command that scaffolds a project asks for confirmation
+	yesVal, err := cmd.Flags().GetBool(yesFlag)
+	if err != nil {
+		return err
+	}
+	scafall.WithConfirmation(!yesVal)(&s)
+	showRenamesVal, _ := cmd.Flags().GetBool(showRenamesFlag)
+	treeVal, _ := cmd.Flags().GetBool(treeFlag)
+
+	scafall.WithFetchProgress(fetchProgress())(&s)
+	scafall.WithCheckpoints(true)(&s)
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	result, err := s.ScaffoldWithResult()
+	if err == nil && url != stdinURL && !treeVal {
+		// failing to remember the template does not fail the scaffold
+		_ = scafall.RememberTemplate(url)
+	}
+	if jsonMode(cmd) {
+		return reportJSON(result, err)
+	}
+	if err == nil && treeVal {
+		return reportTree(result)
+	}
+	if err == nil && showRenamesVal && outputFormat == textOutput {
+		reportRenames(result)
+	}
+	return reportScaffold(outputFormat, result, err)
+}
+
+// The options set by the flags of cmd that choose, fetch and render a
+// template.  Commands define the flags that apply to them, the flags that
+// cmd does not define are left at their defaults.
+func scaffoldOptions(cmd *cobra.Command) ([]scafall.Option, error) {
+	options := []scafall.Option{}
+	outputDirVal, err := cmd.Flags().GetString(outputFolderFlag)
+	if err == nil {
+		options = append(options, scafall.WithOutputFolder(outputDirVal))
+	}
+	argumentsVal, err := cmd.Flags().GetStringToString(argumentsFlag)
+	if err == nil {
+		options = append(options, scafall.WithArguments(argumentsVal))
+	}
+	subPathVal, err := cmd.Flags().GetString(subPath)
+	if err == nil {
+		options = append(options, scafall.WithSubPath(subPathVal))
+	}
+	gitRefVal, err := cmd.Flags().GetString(gitRefFlag)
+	if err == nil && gitRefVal != "" {
+		options = append(options, scafall.WithGitRef(gitRefVal))
+	}
+	offlineVal, err := cmd.Flags().GetBool(offlineFlag)
+	if err == nil {
+		options = append(options, scafall.WithOffline(offlineVal))
+	}
+	submodulesVal, err := cmd.Flags().GetBool(submodulesFlag)
+	if err == nil {
+		options = append(options, scafall.WithSubmodules(submodulesVal))
+	}
+	mirrorsVal, err := cmd.Flags().GetStringSlice(mirrorFlag)
+	if err == nil && len(mirrorsVal) != 0 {
+		options = append(options, scafall.WithMirrors(mirrorsVal...))
+	}
+	proxyVal, err := cmd.Flags().GetString(proxyFlag)
+	if err == nil {
+		options = append(options, scafall.WithProxy(proxyVal))
+	}
+	caBundleVal, err := cmd.Flags().GetString(caBundleFlag)
+	if err == nil {
+		options = append(options, scafall.WithCABundle(caBundleVal))
+	}
+	checksumVal, err := cmd.Flags().GetString(checksumFlag)
+	if err == nil {
+		options = append(options, scafall.WithChecksum(checksumVal))
+	}
+	manifestVal, err := cmd.Flags().GetString(manifestFlag)
+	if err == nil {
+		options = append(options, scafall.WithManifest(manifestVal))
+	}
+	changedOnlyVal, err := cmd.Flags().GetBool(changedOnlyFlag)
+	if err == nil {
+		options = append(options, scafall.WithChangedOnly(changedOnlyVal))
+	}
+	renderTimeoutVal, err := cmd.Flags().GetDuration(renderTimeoutFlag)
+	if err == nil {
+		options = append(options, scafall.WithRenderTimeout(renderTimeoutVal))
+	}
+	hardLinksVal, err := cmd.Flags().GetBool(hardLinksFlag)
+	if err == nil {
+		options = append(options, scafall.WithHardLinks(hardLinksVal))
+	}
+	strictVal, err := cmd.Flags().GetBool(strictFlag)
+	if err == nil {
+		options = append(options, scafall.WithStrictVariables(strictVal))
+	}
+	noInputVal, err := cmd.Flags().GetBool(noInputFlag)
+	if err == nil && noInputVal {
+		options = append(options, scafall.WithNoInput(noInputVal))
+	}
+	showRenamesVal, err := cmd.Flags().GetBool(showRenamesFlag)
+	if err == nil {
+		options = append(options, scafall.WithShowRenames(showRenamesVal))
+	}
+	treeVal, err := cmd.Flags().GetBool(treeFlag)
+	if err == nil {
+		options = append(options, scafall.WithDryRun(treeVal))
+	}
+	languageVal, err := cmd.Flags().GetString(languageFlag)
+	if err == nil && languageVal != "" {
+		options = append(options, scafall.WithLanguage(languageVal))
+	}
+	modeOpts, err := modeOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, modeOpts...)
+	providerOpts, err := valueProviderOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, providerOpts...)
+	answersOpts, err := answersOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, answersOpts...)
+	options = append(options, choiceMatchingOptions(cmd)...)
+	options = append(options, seedOptions(cmd)...)
+	hostKeyOpts, err := hostKeyCheckingOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return append(options, hostKeyOpts...), nil
+}
+
 func init() {
 	rootCmd.AddCommand(argsCmd)
-	rootCmd.Flags().StringP(outputFolderFlag, "p", ".", "scaffold project in the provided output directory")
+	rootCmd.AddCommand(batchCmd)
+	rootCmd.AddCommand(workspaceCmd)
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(testCmd)
+	rootCmd.AddCommand(recentCmd)
+	rootCmd.AddCommand(browseCmd)
+	rootCmd.AddCommand(initAnswersCmd)
+	rootCmd.AddCommand(validateAnswersCmd)
+	rootCmd.PersistentFlags().Bool(jsonFlag, false, "write the outcome of every command to stdout as JSON; prompts and progress are written to stderr")
+	rootCmd.Flags().StringP(outputFolderFlag, "p", "", "scaffold project in the provided output directory, which may use template variables; defaults to a directory named after the project")
 	rootCmd.Flags().StringToStringP(argumentsFlag, "o", map[string]string{}, "provide overrides as key-value pairs")
 	rootCmd.Flags().StringP(subPath, "s", "", "use sub directory in template project to scaffold project")
+	rootCmd.Flags().StringP(gitRefFlag, "r", "", "use a git branch, tag or commit of the template repository")
+	rootCmd.Flags().Bool(offlineFlag, false, "use the cached copy of the template rather than fetching it")
+	rootCmd.Flags().Bool(submodulesFlag, true, "clone the git submodules of the template repository")
+	rootCmd.Flags().StringSlice(mirrorFlag, nil, "fetch the template from the provided mirror when it cannot be fetched from the url")
+	rootCmd.Flags().String(proxyFlag, "", "fetch templates through the provided HTTP proxy; defaults to HTTPS_PROXY")
+	rootCmd.Flags().String(caBundleFlag, "", "trust the certificates in the provided PEM file when fetching templates")
+	rootCmd.Flags().String(checksumFlag, "", "fail unless the template matches the provided sha256:<hex> digest")
+	rootCmd.Flags().String(manifestFlag, "", "write a checksum of every created file to the provided manifest file")
+	rootCmd.Flags().Bool(changedOnlyFlag, false, "only write files that have changed since the manifest was written")
+	rootCmd.Flags().Duration(renderTimeoutFlag, scafall.DefaultRenderTimeout, "give up when any one file takes longer than the provided duration to render; 0 disables the limit")
+	rootCmd.Flags().Bool(hardLinksFlag, false, "write binary files with the same content once and hard link the duplicates")
+	rootCmd.Flags().Bool(strictFlag, false, "fail when the template uses variables that are neither prompted for nor given as arguments")
+	addModeFlags(rootCmd)
+	addValueProviderFlag(rootCmd)
+	addAnswersFlag(rootCmd)
+	addChoiceMatchingFlags(rootCmd)
+	addHostKeyCheckingFlag(rootCmd)
+	addSeedFlag(rootCmd)
+	rootCmd.Flags().Bool(noInputFlag, false, "never prompt; variables not provided with --arg take their default value and missing required variables are listed")
+	rootCmd.Flags().BoolP(yesFlag, "y", false, "create the project without confirming the summary shown after prompting")
+	rootCmd.Flags().String(languageFlag, "", "ask prompts in the provided language, such as fr or pt-BR, when the template translates them; defaults to LANG")
+	rootCmd.Flags().Bool(showRenamesFlag, false, "list every file and folder renamed by template variables, in the summary and once the project is created")
+	rootCmd.Flags().Bool(treeFlag, false, "print the files and folders of the project, with their modes and sizes, without creating the project")
+	rootCmd.Flags().String(outputFormatFlag, textOutput, "report the outcome as text or as github workflow commands")
 }
 
 // Execute executes the root command.
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if err != nil && !jsonWritten {
+		if val, flagErr := rootCmd.PersistentFlags().GetBool(jsonFlag); flagErr == nil && val {
+			_ = writeJSON(newJSONError(err))
+		}
+	}
+	// the code of the message is shown so that it can be searched for
+	if code, ok := scafall.CodeOf(err); ok {
+		return errors.WithMessage(err, string(code))
+	}
+	return err
 }
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+const seedFlag = "seed"
+
+// Add the flag that seeds the random functions of templates
+func addSeedFlag(cmd *cobra.Command) {
+	cmd.Flags().Int64(seedFlag, 0, "seed the random functions of templates, such as randAlphaNum and uuidv4, so that every run creates the same project")
+}
+
+// Read the seed flag of cmd as options, the random functions are only seeded
+// when the flag is given
+func seedOptions(cmd *cobra.Command) []scafall.Option {
+	if !cmd.Flags().Changed(seedFlag) {
+		return nil
+	}
+	seed, err := cmd.Flags().GetInt64(seedFlag)
+	if err != nil {
+		return nil
+	}
+	return []scafall.Option{scafall.WithSeed(seed)}
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+const (
+	matrixFlag = "matrix"
+)
+
+var (
+	testCmd = &cobra.Command{
+		Use:   "test gitRepository",
+		Short: "render a template with a matrix of answers and validate each project",
+		Long:  `Given gitRepository containing a template and a matrix file, render the template with every combination of answers in the matrix, run the validation commands of the matrix in each project, and report the combinations that failed.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := args[0]
+			matrixFile, err := cmd.Flags().GetString(matrixFlag)
+			if err != nil {
+				return err
+			}
+
+			s, err := scafall.NewScafall(url)
+			if err != nil {
+				return err
+			}
+			argumentsVal, err := cmd.Flags().GetStringToString(argumentsFlag)
+			if err == nil {
+				scafall.WithArguments(argumentsVal)(&s)
+			}
+			subPathVal, err := cmd.Flags().GetString(subPath)
+			if err == nil {
+				scafall.WithSubPath(subPathVal)(&s)
+			}
+			gitRefVal, err := cmd.Flags().GetString(gitRefFlag)
+			if err == nil && gitRefVal != "" {
+				scafall.WithGitRef(gitRefVal)(&s)
+			}
+			offlineVal, err := cmd.Flags().GetBool(offlineFlag)
+			if err == nil {
+				scafall.WithOffline(offlineVal)(&s)
+			}
+			submodulesVal, err := cmd.Flags().GetBool(submodulesFlag)
+			if err == nil {
+				scafall.WithSubmodules(submodulesVal)(&s)
+			}
+			mirrorsVal, err := cmd.Flags().GetStringSlice(mirrorFlag)
+			if err == nil && len(mirrorsVal) != 0 {
+				scafall.WithMirrors(mirrorsVal...)(&s)
+			}
+			proxyVal, err := cmd.Flags().GetString(proxyFlag)
+			if err == nil {
+				scafall.WithProxy(proxyVal)(&s)
+			}
+			caBundleVal, err := cmd.Flags().GetString(caBundleFlag)
+			if err == nil {
+				scafall.WithCABundle(caBundleVal)(&s)
+			}
+			hostKeyOpts, err := hostKeyCheckingOptions(cmd)
+			if err != nil {
+				return err
+			}
+			for _, opt := range hostKeyOpts {
+				opt(&s)
+			}
+			renderTimeoutVal, err := cmd.Flags().GetDuration(renderTimeoutFlag)
+			if err == nil {
+				scafall.WithRenderTimeout(renderTimeoutVal)(&s)
+			}
+			strictVal, err := cmd.Flags().GetBool(strictFlag)
+			if err == nil {
+				scafall.WithStrictVariables(strictVal)(&s)
+			}
+			for _, opt := range seedOptions(cmd) {
+				opt(&s)
+			}
+
+			results, err := s.TestMatrix(matrixFile)
+			if err != nil {
+				return err
+			}
+
+			if jsonMode(cmd) {
+				return reportMatrixJSON(results)
+			}
+
+			failed := 0
+			for _, r := range results {
+				if r.Err != nil {
+					failed++
+					fmt.Printf("\tfailed\t%s: %s (output in %s)\n", formatCombination(r.Arguments), r.Err, r.OutputFolder)
+					if r.Output != "" {
+						fmt.Println(r.Output)
+					}
+				} else {
+					fmt.Printf("\tpassed\t%s\n", formatCombination(r.Arguments))
+				}
+			}
+			fmt.Printf("%d of %d combinations passed\n", len(results)-failed, len(results))
+			if failed > 0 {
+				return fmt.Errorf("%d combinations failed", failed)
+			}
+			return nil
+		},
+	}
+)
+
+func formatCombination(arguments map[string]string) string {
+	if len(arguments) == 0 {
+		return "defaults"
+	}
+	pairs := make([]string, 0, len(arguments))
+	for name, value := range arguments {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+func reportMatrixJSON(results []scafall.MatrixResult) error {
+	out := jsonMatrix{Results: make([]jsonMatrixResult, len(results))}
+	for i, r := range results {
+		out.Results[i] = jsonMatrixResult{Arguments: r.Arguments, OutputFolder: r.OutputFolder, Output: r.Output, Error: errorString(r.Err)}
+		if r.Err != nil {
+			out.Failed++
+		} else {
+			out.Passed++
+		}
+	}
+	if err := writeJSON(out); err != nil {
+		return err
+	}
+	if out.Failed > 0 {
+		return fmt.Errorf("%d combinations failed", out.Failed)
+	}
+	return nil
+}
+
+func init() {
+	testCmd.Flags().String(matrixFlag, "matrix.toml", "read combinations of answers and validation commands from the provided matrix file")
+	testCmd.Flags().StringToString(argumentsFlag, map[string]string{}, "provide overrides as key-value pairs")
+	testCmd.Flags().StringP(subPath, "s", "", "use sub directory in template project to scaffold project")
+	testCmd.Flags().StringP(gitRefFlag, "r", "", "use a git branch, tag or commit of the template repository")
+	testCmd.Flags().Bool(offlineFlag, false, "use the cached copy of the template rather than fetching it")
+	testCmd.Flags().Bool(submodulesFlag, true, "clone the git submodules of the template repository")
+	testCmd.Flags().StringSlice(mirrorFlag, nil, "fetch the template from the provided mirror when it cannot be fetched from the url")
+	testCmd.Flags().String(proxyFlag, "", "fetch templates through the provided HTTP proxy; defaults to HTTPS_PROXY")
+	testCmd.Flags().String(caBundleFlag, "", "trust the certificates in the provided PEM file when fetching templates")
+	addHostKeyCheckingFlag(testCmd)
+	addSeedFlag(testCmd)
+	testCmd.Flags().Duration(renderTimeoutFlag, scafall.DefaultRenderTimeout, "give up when any one file takes longer than the provided duration to render; 0 disables the limit")
+	testCmd.Flags().Bool(strictFlag, false, "fail a combination when the template uses variables that are neither prompted for nor given as arguments")
+}
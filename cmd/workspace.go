@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks/scafall/pkg"
+)
+
+var (
+	workspaceCmd = &cobra.Command{
+		Use:   "workspace workspaceFile",
+		Short: "create a project of several components from a workspace file",
+		Long:  `Given workspaceFile mapping templates to folders of a single project, such as api, web and infra, create every component in its folder.  The variables of the workspace file, and answers given for earlier components, are shared with later components so that each shared variable is asked at most once.  Creation stops at the first component that fails.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputDirVal, err := cmd.Flags().GetString(outputFolderFlag)
+			if err != nil {
+				return err
+			}
+			argumentsVal, err := cmd.Flags().GetStringToString(argumentsFlag)
+			if err != nil {
+				return err
+			}
+			offlineVal, err := cmd.Flags().GetBool(offlineFlag)
+			if err != nil {
+				return err
+			}
+			noInputVal, err := cmd.Flags().GetBool(noInputFlag)
+			if err != nil {
+				return err
+			}
+			yesVal, err := cmd.Flags().GetBool(yesFlag)
+			if err != nil {
+				return err
+			}
+			languageVal, err := cmd.Flags().GetString(languageFlag)
+			if err != nil {
+				return err
+			}
+			modeOpts, err := modeOptions(cmd)
+			if err != nil {
+				return err
+			}
+			providerOpts, err := valueProviderOptions(cmd)
+			if err != nil {
+				return err
+			}
+			hostKeyOpts, err := hostKeyCheckingOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			options := []scafall.Option{
+				scafall.WithOutputFolder(outputDirVal),
+				scafall.WithArguments(argumentsVal),
+				scafall.WithOffline(offlineVal),
+				scafall.WithNoInput(noInputVal),
+				scafall.WithConfirmation(!yesVal),
+				scafall.WithLanguage(languageVal),
+				scafall.WithFetchProgress(fetchProgress()),
+			}
+			options = append(options, jsonOptions(cmd)...)
+			options = append(options, modeOpts...)
+			options = append(options, providerOpts...)
+			options = append(options, hostKeyOpts...)
+			results, err := scafall.ScaffoldWorkspace(args[0], options...)
+			if jsonMode(cmd) {
+				return reportWorkspaceJSON(results, err)
+			}
+			for _, r := range results {
+				if r.Err == nil {
+					fmt.Printf("\tcreated\t%s -> %s\n", r.URL, r.Result.OutputFolder)
+				}
+			}
+			for _, r := range results {
+				if r.Err == nil {
+					reportWarnings(r.Result)
+				}
+			}
+			return err
+		},
+	}
+)
+
+func reportWorkspaceJSON(results []scafall.WorkspaceResult, err error) error {
+	if err != nil {
+		return err
+	}
+	out := jsonWorkspace{Components: make([]jsonWorkspaceComponent, len(results))}
+	for i, r := range results {
+		result, err := newJSONResult(r.Result)
+		if err != nil {
+			return err
+		}
+		out.Components[i] = jsonWorkspaceComponent{URL: r.URL, Path: r.Path, jsonResult: result}
+	}
+	return writeJSON(out)
+}
+
+func init() {
+	workspaceCmd.Flags().StringP(outputFolderFlag, "p", "", "create the workspace in the provided output directory; defaults to the output-folder of the workspace file")
+	workspaceCmd.Flags().StringToStringP(argumentsFlag, "o", map[string]string{}, "provide overrides, shared by every component, as key-value pairs")
+	workspaceCmd.Flags().Bool(offlineFlag, false, "use the cached copy of the templates rather than fetching them")
+	workspaceCmd.Flags().Bool(noInputFlag, false, "never prompt; variables not provided with --arg take their default value and missing required variables are listed")
+	workspaceCmd.Flags().BoolP(yesFlag, "y", false, "create each component without confirming the summary shown after prompting")
+	workspaceCmd.Flags().String(languageFlag, "", "ask prompts in the provided language, such as fr or pt-BR, when the template translates them; defaults to LANG")
+	addHostKeyCheckingFlag(workspaceCmd)
+	addModeFlags(workspaceCmd)
+	addValueProviderFlag(workspaceCmd)
+}
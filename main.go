@@ -1,13 +1,21 @@
 package main
 
 import (
+	"errors"
 	"log"
+	"os"
 
 	"github.com/buildpacks/scafall/cmd"
+	scafall "github.com/buildpacks/scafall/pkg"
 )
 
 func main() {
 	err := cmd.Execute()
+	if errors.Is(err, scafall.ErrPromptAborted) {
+		// the status of a program interrupted with Ctrl-C
+		log.Println(err)
+		os.Exit(130)
+	}
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -0,0 +1,64 @@
+package scafall
+
+import (
+	"sync"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+// BatchResult reports the outcome of a single scaffold in a batch.
+type BatchResult struct {
+	URL          string
+	OutputFolder string
+	Err          error
+}
+
+// ScaffoldBatch reads a spec file containing a list of scaffold jobs and
+// executes each of them.  Jobs are executed sequentially unless the spec sets
+// parallel to true, parallel jobs are never prompted as their prompts would
+// share the terminal, so a value that is neither provided nor has a default
+// fails its job.  A result is returned for every job, failed jobs do not
+// prevent the remaining jobs from running.
+func ScaffoldBatch(specFile string) ([]BatchResult, error) {
+	spec, err := internal.ReadSpec(specFile)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(spec.Jobs))
+	var wg sync.WaitGroup
+	for i, job := range spec.Jobs {
+		if !spec.Parallel {
+			results[i] = runJob(job, false)
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, job internal.Job) {
+			defer wg.Done()
+			results[i] = runJob(job, true)
+		}(i, job)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// Run a single job, without prompting when noPrompt is set
+func runJob(job internal.Job, noPrompt bool) BatchResult {
+	opts := []Option{WithArguments(job.Arguments), WithSubPath(job.SubPath), WithTemplate(job.Template), WithNoPrompt(noPrompt)}
+	if job.OutputFolder != "" {
+		opts = append(opts, WithOutputFolder(job.OutputFolder))
+	}
+
+	result := BatchResult{URL: job.URL}
+	s, err := NewScafall(job.URL, opts...)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	scaffolded, err := s.ScaffoldWithResult()
+	result.OutputFolder = scaffolded.OutputFolder
+	result.Err = err
+	return result
+}
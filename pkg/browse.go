@@ -0,0 +1,304 @@
+package scafall
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+const (
+	// previewLines is the number of lines of a README shown by Browse
+	previewLines = 20
+
+	browseQuit     = "quit"
+	browseScaffold = "scaffold a project from this template"
+	browseBack     = "back to the templates"
+
+	// the alternate screen of the terminal keeps the browser from
+	// scrolling away the output of earlier commands
+	enterAlternateScreen = "\x1b[?1049h"
+	leaveAlternateScreen = "\x1b[?1049l"
+	clearScreen          = "\x1b[H\x1b[2J"
+)
+
+// RegistryEntry describes a template that can be browsed, such as a template
+// listed by a registry.
+type RegistryEntry = internal.RegistryEntry
+
+// TemplatePreview describes a template before a project is created from it.
+type TemplatePreview struct {
+	// Readme is the README of the template, empty when it has none
+	Readme string
+	// Prompts are the prompts of the template
+	Prompts []Prompt
+	// Templates lists the templates of a collection when no template was
+	// chosen with WithTemplate, Readme and Prompts are then empty
+	Templates []string
+}
+
+// ReadRegistry lists the templates at url for Browse.  The url is either a
+// registry index, a .toml file listing [[template]] tables of name, url,
+// description and tags, or a collection of templates.  Without a url the
+// recently used templates are listed.  Options, such as WithProxy, control
+// how the registry is fetched.
+func ReadRegistry(url string, opts ...Option) ([]RegistryEntry, error) {
+	if url == "" {
+		urls, err := RecentTemplates()
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]RegistryEntry, len(urls))
+		for i, u := range urls {
+			entries[i] = RegistryEntry{Name: u, URL: u, Description: "recently used"}
+		}
+		return entries, nil
+	}
+
+	s, err := NewScafall(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if internal.IsRegistry(url) {
+		registry, err := internal.ReadRegistry(url, internal.FetchOptions{
+			Username: s.HTTPUsername,
+			Password: s.HTTPPassword,
+			Proxy:    s.Proxy,
+			CABundle: s.CABundle,
+		})
+		return registry.Templates, err
+	}
+
+	err = s.clone()
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(s.CloneCache)
+	if err := s.checkTemplate(); err != nil {
+		return nil, err
+	}
+	isCollection, templates := internal.IsCollection(s.CloneCache)
+	if !isCollection {
+		return []RegistryEntry{{Name: url, URL: url, Description: summary(s.CloneCache)}}, nil
+	}
+	entries := make([]RegistryEntry, len(templates))
+	for i, template := range templates {
+		entries[i] = RegistryEntry{
+			Name:        template,
+			URL:         url,
+			Description: summary(path.Join(s.CloneCache, template)),
+			Template:    template,
+		}
+	}
+	return entries, nil
+}
+
+// Preview fetches the template and describes it, without prompting or
+// creating a project.
+func (s Scafall) Preview() (TemplatePreview, error) {
+	preview := TemplatePreview{}
+	err := s.clone()
+	if err != nil {
+		return preview, err
+	}
+	defer os.RemoveAll(s.CloneCache)
+	if err := s.checkTemplate(); err != nil {
+		return preview, err
+	}
+
+	inFs := s.CloneCache
+	if isCollection, templates := internal.IsCollection(inFs); isCollection {
+		if s.Template == "" {
+			preview.Templates = templates
+			return preview, nil
+		}
+		chosen, err := s.chooseTemplate()
+		if err != nil {
+			return preview, err
+		}
+		inFs = path.Join(inFs, chosen)
+	}
+	if readme, ok := internal.FindReadme(inFs); ok {
+		preview.Readme, err = internal.ReadFile(readme)
+		if err != nil {
+			return preview, err
+		}
+	}
+	template, err := internal.ReadTemplate(inFs, nil)
+	if err != nil {
+		return preview, err
+	}
+	preview.Prompts = template.Arguments()
+	return preview, nil
+}
+
+// Browse presents entries full-screen so that the end-user can search them,
+// preview the README and prompts of a template and choose a template from
+// which to create a project.  The chosen entry names the template within a
+// collection, if any.  An empty entry is returned when the end-user quits.
+// Options, such as WithProxy, control how templates are fetched.
+func Browse(entries []RegistryEntry, opts ...Option) (RegistryEntry, error) {
+	var s Scafall
+	for _, opt := range opts {
+		opt(&s)
+	}
+	if len(entries) == 0 {
+		return RegistryEntry{}, fmt.Errorf("there are no templates to browse")
+	}
+
+	out := io.Writer(os.Stdout)
+	if s.PromptOutput != nil {
+		out = s.PromptOutput
+	}
+	fmt.Fprint(out, enterAlternateScreen)
+	defer fmt.Fprint(out, leaveAlternateScreen)
+
+	for {
+		fmt.Fprint(out, clearScreen)
+		entry, ok, err := s.chooseEntry(entries)
+		if err != nil || !ok {
+			return RegistryEntry{}, err
+		}
+		chosen, back, err := s.browseEntry(out, entry, opts)
+		if !back {
+			return chosen, err
+		}
+	}
+}
+
+// Ask the end-user to choose one of entries, which are searched by name,
+// description and tags.  Reports false when the end-user quits.
+func (s Scafall) chooseEntry(entries []RegistryEntry) (RegistryEntry, bool, error) {
+	options := make([]string, len(entries)+1)
+	for i, entry := range entries {
+		options[i] = entry.Name
+		if entry.Description != "" {
+			options[i] += "  " + entry.Description
+		}
+	}
+	options[len(entries)] = browseQuit
+
+	question := survey.Select{
+		Message: "browse templates, type to search",
+		Options: options,
+	}
+	filter := survey.WithFilter(func(filter string, value string, index int) bool {
+		return index == len(entries) || entries[index].Matches(filter)
+	})
+	index := 0
+	err := s.ask(func() error {
+		return survey.AskOne(&question, &index, append(s.askOptions(), filter, survey.WithPageSize(15))...)
+	})
+	if err != nil || index == len(entries) {
+		return RegistryEntry{}, false, err
+	}
+	return entries[index], true, nil
+}
+
+// Preview entry and ask the end-user what to do with it, reporting whether
+// the end-user went back to the templates
+func (s Scafall) browseEntry(out io.Writer, entry RegistryEntry, opts []Option) (RegistryEntry, bool, error) {
+	for {
+		fmt.Fprint(out, clearScreen)
+		fmt.Fprintf(out, "%s\n%s\n\n", entry.Name, entry.URL)
+		previewer, err := NewScafall(entry.URL, append(opts, WithTemplate(entry.Template))...)
+		if err != nil {
+			return entry, false, err
+		}
+		preview, err := previewer.Preview()
+		actions := []string{browseScaffold, browseBack, browseQuit}
+		switch {
+		case err != nil:
+			fmt.Fprintf(out, "the template cannot be previewed: %s\n\n", err)
+			actions = actions[1:]
+		case len(preview.Templates) != 0:
+			template, err := s.chooseCollectionTemplate(preview.Templates)
+			if err != nil || template == "" {
+				return entry, template == "" && err == nil, err
+			}
+			entry.Template = template
+			continue
+		default:
+			writePreview(out, preview)
+		}
+
+		action := ""
+		question := survey.Select{Message: "what next", Options: actions}
+		err = s.ask(func() error {
+			return survey.AskOne(&question, &action, s.askOptions()...)
+		})
+		switch {
+		case err != nil:
+			return entry, false, err
+		case action == browseBack:
+			return entry, true, nil
+		case action == browseQuit:
+			return RegistryEntry{}, false, nil
+		}
+		return entry, false, nil
+	}
+}
+
+// Ask the end-user to choose a template of a collection, an empty template is
+// returned when the end-user goes back
+func (s Scafall) chooseCollectionTemplate(templates []string) (string, error) {
+	question := survey.Select{
+		Message: "the url is a collection, choose a template to preview",
+		Options: append(append([]string{}, templates...), browseBack),
+	}
+	template := ""
+	err := s.ask(func() error {
+		return survey.AskOne(&question, &template, s.askOptions()...)
+	})
+	if err != nil || template == browseBack {
+		return "", err
+	}
+	return template, nil
+}
+
+// Write the README, shortened to previewLines, and the prompts of a template
+func writePreview(out io.Writer, preview TemplatePreview) {
+	if readme := strings.TrimSpace(preview.Readme); readme != "" {
+		lines := strings.Split(readme, "\n")
+		if len(lines) > previewLines {
+			lines = append(lines[:previewLines], fmt.Sprintf("... %d more lines", len(lines)-previewLines))
+		}
+		fmt.Fprintf(out, "%s\n\n", strings.Join(lines, "\n"))
+	}
+	if len(preview.Prompts) == 0 {
+		fmt.Fprint(out, "The template asks no questions.\n\n")
+		return
+	}
+	fmt.Fprint(out, "The template asks:\n")
+	for _, p := range preview.Prompts {
+		fmt.Fprintf(out, "  %s: %s", p.Name, p.Prompt)
+		if p.Default != "" {
+			fmt.Fprintf(out, " (default: %s)", p.Default)
+		}
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintln(out)
+}
+
+// The first line of the README of the template in dir that is not a heading
+func summary(dir string) string {
+	readme, ok := internal.FindReadme(dir)
+	if !ok {
+		return ""
+	}
+	content, err := internal.ReadFile(readme)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package scafall
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+// hiddenValue is shown in place of the answers to prompts marked
+// record = false
+const hiddenValue = "********"
+
+// ErrNotConfirmed is returned when the end-user does not confirm the summary
+// shown by WithConfirmation, no files are then written.
+var ErrNotConfirmed = internal.NewError(internal.CodeNotConfirmed)
+
+// After prompting, show the output folder and the value of every variable
+// and ask the end-user to confirm before any file is written.  Scaffolding
+// fails with ErrNotConfirmed when the end-user declines.  Nothing is asked
+// when prompting is disabled by WithNoPrompt.
+func WithConfirmation(confirm bool) Option {
+	return func(s *Scafall) {
+		s.Confirm = confirm
+	}
+}
+
+// List the paths renamed by template variables, as found in Result.Renames,
+// in the summary shown by WithConfirmation.
+func WithShowRenames(showRenames bool) Option {
+	return func(s *Scafall) {
+		s.ShowRenames = showRenames
+	}
+}
+
+// Show a summary of the project to be created from the template in inFs and
+// ask the end-user to confirm it; a dry run writes nothing, so is not
+// confirmed
+func (s Scafall) confirm(inFs string, values map[string]string, renames map[string]string) error {
+	if !s.Confirm || s.NoPrompt || s.DryRun {
+		return nil
+	}
+	template, err := internal.ReadTemplate(inFs, nil)
+	if err != nil {
+		return err
+	}
+	out := io.Writer(os.Stdout)
+	if s.PromptOutput != nil {
+		out = s.PromptOutput
+	}
+	writeSummary(out, s.OutputFolder, values, internal.Unrecorded(template.Arguments()))
+	if s.ShowRenames {
+		writeRenames(out, renames)
+	}
+
+	confirmed := false
+	question := survey.Confirm{Message: "Create the project", Default: true}
+	err = s.ask(func() error {
+		return survey.AskOne(&question, &confirmed, s.askOptions()...)
+	})
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return ErrNotConfirmed
+	}
+	return nil
+}
+
+// Write the output folder and the variables, other than those reserved for
+// scafall, one per line.  The values of unrecorded variables are hidden and
+// values spanning several lines are shortened to their first line.
+func writeSummary(out io.Writer, outputFolder string, values map[string]string, unrecorded []string) {
+	if abs, err := filepath.Abs(outputFolder); err == nil {
+		outputFolder = abs
+	}
+	fmt.Fprintf(out, "\nThe project will be created in %s\n", outputFolder)
+
+	hidden := map[string]bool{}
+	for _, name := range unrecorded {
+		hidden[name] = true
+	}
+	names := []string{}
+	for name := range values {
+		if !strings.HasPrefix(name, internal.ReservedPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		fmt.Fprintln(out)
+		return
+	}
+
+	fmt.Fprint(out, "with the variables:\n")
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, name := range names {
+		value := values[name]
+		lines := strings.Split(value, "\n")
+		switch {
+		case hidden[name]:
+			value = hiddenValue
+		case len(lines) > 1:
+			value = fmt.Sprintf("%s ... (%d lines)", lines[0], len(lines))
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", name, value)
+	}
+	w.Flush()
+	fmt.Fprintln(out)
+}
+
+// Write each path renamed by template variables with the path to which it is
+// rendered, one per line
+func writeRenames(out io.Writer, renames map[string]string) {
+	if len(renames) == 0 {
+		return
+	}
+	paths := make([]string, 0, len(renames))
+	for path := range renames {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fmt.Fprint(out, "with the renamed paths:\n")
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, path := range paths {
+		fmt.Fprintf(w, "  %s\t-> %s\n", path, renames[path])
+	}
+	w.Flush()
+	fmt.Fprintln(out)
+}
@@ -1,5 +1,9 @@
 package scafall
 
+import (
+	"testing/fstest"
+)
+
 // Create a new project from a project template
 func ExampleScafall_Scaffold() {
 	s, _ := NewScafall("http://github.com/AidanDelaney/scafall-python-eg.git",
@@ -19,3 +23,20 @@ func ExampleScafall_Scaffold_arguments() {
 	// User is not prompted for PythonVersion
 	s.Scaffold()
 }
+
+// Create a new project from a project template embedded in the program, for
+// example using
+//
+//	//go:embed all:template
+//	var templateFS embed.FS
+func ExampleNewScafallFromFS() {
+	templateFS := fstest.MapFS{
+		"template/prompts.toml": {Data: []byte("")},
+		"template/main.go":      {Data: []byte("package main")},
+	}
+	s, _ := NewScafallFromFS(templateFS,
+		WithSubPath("template"),
+		WithOutputFolder("embedded"))
+
+	s.Scaffold()
+}
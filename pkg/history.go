@@ -0,0 +1,82 @@
+package scafall
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+// HistoryFile is the file, within the user config folder, that lists recently
+// used templates
+const HistoryFile string = "scafall/history.toml"
+
+func historyFile() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, HistoryFile), nil
+}
+
+// RecentTemplates lists the urls of recently used templates, most recently
+// used first.
+func RecentTemplates() ([]string, error) {
+	file, err := historyFile()
+	if err != nil {
+		return nil, err
+	}
+	history, err := internal.ReadHistory(file)
+	return history.URLs, err
+}
+
+// RememberTemplate records url as the most recently used template.  Local
+// templates are recorded by their absolute path.
+func RememberTemplate(url string) error {
+	if path, ok := internal.LocalPath(url); ok {
+		url = path
+	}
+	file, err := historyFile()
+	if err != nil {
+		return err
+	}
+	history, err := internal.ReadHistory(file)
+	if err != nil {
+		return err
+	}
+	return internal.WriteHistory(history.Add(url), file)
+}
+
+// ChooseRecentTemplate asks the end-user to choose one of the recently used
+// templates.  Options, such as WithPromptOutput, control how the question is
+// asked.
+func ChooseRecentTemplate(opts ...Option) (string, error) {
+	var s Scafall
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	urls, err := RecentTemplates()
+	if err != nil {
+		return "", err
+	}
+	if len(urls) == 0 {
+		return "", fmt.Errorf("no template url provided and no templates have been used recently")
+	}
+
+	question := survey.Select{
+		Message: "choose a recently used template",
+		Options: urls,
+	}
+	url := ""
+	err = s.ask(func() error {
+		return survey.AskOne(&question, &url, append(s.askOptions(), survey.WithValidator(survey.Required))...)
+	})
+	if err != nil {
+		return "", err
+	}
+	return url, nil
+}
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AnswerError lists every answer, provided before prompting, that is not a
+// valid answer to its prompt
+type AnswerError struct {
+	Problems []string
+}
+
+func (e AnswerError) Error() string {
+	return message(CodeInvalidAnswers, strings.Join(e.Problems, "; "))
+}
+
+func (e AnswerError) Code() Code {
+	return CodeInvalidAnswers
+}
+
+// Answer prompts with the provided answers.  Each answer is checked and
+// normalized in the same way as an answer given by the end-user, every
+// invalid answer is reported together in an AnswerError.  Numbers and dates
+// given as strings are written in canonical form, so that the answers give the
+// same project in every locale, other values, such as an int or a time.Time,
+// are converted to canonical form.
+func (t TemplateImpl) Answer(answers map[string]interface{}) (Template, error) {
+	problems := []string{}
+	checked := map[string]string{}
+	known := map[string]bool{}
+
+	// earlier answers are used to render the choices of later prompts
+	current := map[string]string{}
+	for key, value := range t.TArguments {
+		current[key] = value
+	}
+	for key, value := range t.TOverrides {
+		current[key] = value
+	}
+	for _, prompt := range t.TPrompts.Prompts {
+		known[prompt.Name] = true
+		answer, provided := answers[prompt.Name]
+		if !provided {
+			if _, ok := current[prompt.Name]; !ok {
+				if rendered, err := renderPrompt(prompt, current, t.TPrompts.Settings); err == nil {
+					current[prompt.Name] = rendered.Default
+				}
+			}
+			continue
+		}
+		value, err := checkAnswer(prompt, answer, current, t.TPrompts.Settings, t.TMatching)
+		if err == nil {
+			err = t.TValidators.Check(prompt.Name, value)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", prompt.Name, err))
+			continue
+		}
+		checked[prompt.Name] = value
+		current[prompt.Name] = value
+	}
+
+	unknown := []string{}
+	for name := range answers {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		problems = append(problems, fmt.Sprintf("%s: the template has no such prompt", name))
+	}
+
+	if len(problems) != 0 {
+		return nil, AnswerError{Problems: problems}
+	}
+	t.TAnswers = checked
+	return t, nil
+}
+
+// Check and normalize a single answer to prompt, an answer matching one of
+// the choices of prompt is replaced by the choice
+func checkAnswer(prompt Prompt, answer interface{}, answers map[string]string, settings Settings, matching ChoiceMatching) (string, error) {
+	value := ""
+	switch answer := answer.(type) {
+	case string:
+		value = answer
+	case []string, []interface{}:
+		if prompt.Type != ListType {
+			return "", fmt.Errorf("answers of type %T are not supported", answer)
+		}
+		value = joinItems(answer)
+	case bool:
+		value = fmt.Sprint(answer)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		value = fmt.Sprint(answer)
+	case time.Time:
+		value = answer.Format(CanonicalDateLayout)
+	default:
+		return "", fmt.Errorf("answers of type %T are not supported", answer)
+	}
+
+	rendered, err := renderPrompt(prompt, answers, settings)
+	if err != nil {
+		return "", err
+	}
+	normalized, err := Normalize(prompt, value, defaultLocale)
+	if err != nil {
+		return "", err
+	}
+	var choiceErr error
+	if len(rendered.Choices) != 0 {
+		normalized, choiceErr = matchChoices(prompt, rendered.Choices, normalized, matching)
+	}
+	if err := CheckPattern(prompt, normalized); err != nil {
+		return "", err
+	}
+	if err := CheckLength(prompt, normalized); err != nil {
+		return "", err
+	}
+	if err := CheckPath(prompt, normalized); err != nil {
+		return "", err
+	}
+	if prompt.Required && normalized == "" {
+		return "", fmt.Errorf("a value is required")
+	}
+	if choiceErr != nil {
+		return "", choiceErr
+	}
+	return normalized, nil
+}
+
+// Join the items of a list answer one per line
+func joinItems(answer interface{}) string {
+	switch answer := answer.(type) {
+	case []string:
+		return strings.Join(answer, "\n")
+	case []interface{}:
+		items := make([]string, len(answer))
+		for i, item := range answer {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, "\n")
+	}
+	return fmt.Sprint(answer)
+}
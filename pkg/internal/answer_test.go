@@ -0,0 +1,115 @@
+package internal_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testAnswer(t *testing.T, when spec.G, it spec.S) {
+	promptFile := `[[prompt]]
+name = "Language"
+prompt = "Which language"
+choices = ["go", "python"]
+
+[[prompt]]
+name = "Port"
+prompt = "Which port"
+type = "number"
+default = "8080"
+
+[[prompt]]
+name = "Released"
+prompt = "Release date"
+type = "date"
+
+[[prompt]]
+name = "Module"
+prompt = "Module name"
+pattern = "[a-z]+"
+`
+	var template internal.Template
+
+	it.Before(func() {
+		var err error
+		template, err = internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), nil, nil)
+		h.AssertNil(t, err)
+	})
+
+	when("every answer is valid", func() {
+		it("uses the normalized answers without prompting", func() {
+			answered, err := template.Answer(map[string]interface{}{
+				"Language": "python",
+				"Port":     9000,
+				"Released": time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC),
+				"Module":   "shop",
+			})
+			h.AssertNil(t, err)
+			values, err := answered.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values, map[string]string{"Language": "python", "Port": "9000", "Released": "2022-03-04", "Module": "shop"})
+		})
+	})
+
+	when("answers are invalid", func() {
+		it("reports every invalid answer", func() {
+			_, err := template.Answer(map[string]interface{}{
+				"Language": "rust",
+				"Port":     "eighty",
+				"Module":   "Shop",
+				"Colour":   "blue",
+				"Released": []string{"2022"},
+			})
+			var answerErr internal.AnswerError
+			h.AssertTrue(t, errors.As(err, &answerErr))
+			h.AssertEq(t, answerErr.Problems, []string{
+				"Language: rust is not one of go, python",
+				"Port: eighty is not a number",
+				"Released: answers of type []string are not supported",
+				"Module: Shop does not match the pattern [a-z]+",
+				"Colour: the template has no such prompt",
+			})
+		})
+	})
+
+	when("a list prompt is answered", func() {
+		it("gives templates a slice of the items", func() {
+			listTemplate, err := internal.NewTemplate(io.NopCloser(strings.NewReader(`[[prompt]]
+name = "Services"
+prompt = "List the services to generate"
+type = "list"
+`)), nil, nil)
+			h.AssertNil(t, err)
+			answered, err := listTemplate.Answer(map[string]interface{}{"Services": []string{"api", " worker"}})
+			h.AssertNil(t, err)
+			values, err := answered.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["Services"], "api\nworker")
+
+			rendered, err := internal.RenderString("{{range .Services}}[{{.}}]{{end}}", values)
+			h.AssertNil(t, err)
+			h.AssertEq(t, rendered, "[api][worker]")
+		})
+
+		it("splits an argument at commas", func() {
+			listTemplate, err := internal.NewTemplate(io.NopCloser(strings.NewReader(`[[prompt]]
+name = "Services"
+prompt = "List the services to generate"
+type = "list"
+`)), map[string]string{"Services": "api, worker"}, nil)
+			h.AssertNil(t, err)
+			values, err := listTemplate.Defaults()
+			h.AssertNil(t, err)
+			rendered, err := internal.RenderString("{{len .Services}}", values)
+			h.AssertNil(t, err)
+			h.AssertEq(t, rendered, "2")
+		})
+	})
+}
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// ReadAnswers reads answers to prompts from a TOML file of name = answer
+// pairs, such as a file written by WriteAnswers
+func ReadAnswers(answersFile string) (map[string]interface{}, error) {
+	answers := map[string]interface{}{}
+	answersData, err := ReadFile(answersFile)
+	if err != nil {
+		return answers, err
+	}
+
+	if _, err := toml.Decode(answersData, &answers); err != nil {
+		return answers, errors.Wrap(err, fmt.Sprintf("%s file does not match required format", answersFile))
+	}
+	return answers, nil
+}
+
+// WriteAnswers writes an answers file for prompts in which every answer is
+// commented out.  Each answer is its default and is preceded by the prompt,
+// help text, choices and constraints of its prompt, so that the file is a
+// starting point for answering the template without prompting.
+func WriteAnswers(w io.Writer, url string, prompts []Prompt) error {
+	fmt.Fprintf(w, "# Answers to the prompts of %s\n", url)
+	fmt.Fprint(w, "# Uncomment and change the answers to provide, prompts that are not answered\n")
+	fmt.Fprint(w, "# take their default value.\n")
+	for _, prompt := range prompts {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "# %s\n", prompt.Prompt)
+		for _, line := range strings.Split(strings.TrimSpace(prompt.Help), "\n") {
+			if line != "" {
+				fmt.Fprintf(w, "#   %s\n", line)
+			}
+		}
+		if details := answerDetails(prompt); len(details) != 0 {
+			fmt.Fprintf(w, "#   %s\n", strings.Join(details, "; "))
+		}
+		answer, err := commentedAnswer(prompt)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(w, answer)
+	}
+	return nil
+}
+
+// The constraints on the answer to prompt
+func answerDetails(prompt Prompt) []string {
+	details := []string{}
+	if prompt.Required {
+		details = append(details, "required")
+	}
+	if prompt.Type != "" && prompt.Type != StringType {
+		detail := prompt.Type
+		if prompt.Format != "" {
+			detail += " " + prompt.Format
+		}
+		details = append(details, detail)
+	}
+	if detail, ok := pathExistenceDetails[prompt.Exists]; ok && prompt.Type == PathType {
+		details = append(details, detail)
+	}
+	if len(prompt.Choices) != 0 {
+		details = append(details, "one of "+strings.Join(prompt.Choices, ", "))
+	}
+	if prompt.Pattern != "" {
+		details = append(details, "must match "+prompt.Pattern)
+	}
+	if prompt.MinLength != 0 {
+		details = append(details, fmt.Sprintf("at least %d characters", prompt.MinLength))
+	}
+	if prompt.MaxLength != 0 {
+		details = append(details, fmt.Sprintf("at most %d characters", prompt.MaxLength))
+	}
+	if prompt.When != "" {
+		details = append(details, "asked when "+prompt.When)
+	}
+	if isTemplated(prompt.Default) {
+		details = append(details, "defaults to "+prompt.Default)
+	}
+	if !prompt.Recorded() {
+		details = append(details, "not recorded")
+	}
+	return details
+}
+
+// The default answer to prompt, written as a commented TOML key-value pair
+func commentedAnswer(prompt Prompt) (string, error) {
+	var answer interface{} = prompt.Default
+	switch {
+	case isTemplated(prompt.Default):
+		// a default rendered from earlier answers is not a valid answer
+		answer = ""
+	case prompt.Type == ListType:
+		answer = SplitList(prompt.Default)
+	case len(prompt.Choices) != 0 && prompt.Default == "":
+		answer = prompt.Choices[0]
+	}
+
+	var b bytes.Buffer
+	if err := toml.NewEncoder(&b).Encode(map[string]interface{}{prompt.Name: answer}); err != nil {
+		return "", errors.Wrap(err, prompt.Name)
+	}
+	return "# " + b.String(), nil
+}
+
+func isTemplated(value string) bool {
+	return strings.Contains(value, "{{")
+}
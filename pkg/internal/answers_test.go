@@ -0,0 +1,146 @@
+package internal_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testAnswersFile(t *testing.T, when spec.G, it spec.S) {
+	promptFile := `[[prompt]]
+name = "ProjectName"
+prompt = "Project name"
+help = "Used as the module name"
+required = true
+
+[[prompt]]
+name = "Language"
+prompt = "Which language"
+choices = ["go", "python"]
+
+[[prompt]]
+name = "Port"
+prompt = "Which port"
+type = "number"
+default = "8080"
+
+[[prompt]]
+name = "Service"
+prompt = "Service name"
+default = "{{.ProjectName}}-svc"
+
+[[prompt]]
+name = "Owners"
+prompt = "Owners"
+type = "list"
+default = "alice, bob"
+`
+	var (
+		tmpDir   string
+		template internal.Template
+	)
+
+	it.Before(func() {
+		var err error
+		tmpDir, _ = os.MkdirTemp("", "test")
+		template, err = internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), nil, nil)
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("an answers file is written", func() {
+		it("comments out the default answer to every prompt", func() {
+			var b bytes.Buffer
+			h.AssertNil(t, internal.WriteAnswers(&b, "https://example.com/template", template.Arguments()))
+			answers := b.String()
+			h.AssertContains(t, answers, "# Answers to the prompts of https://example.com/template\n")
+			h.AssertContains(t, answers, "# Project name\n#   Used as the module name\n#   required\n# ProjectName = \"\"\n")
+			h.AssertContains(t, answers, "#   one of go, python\n# Language = \"go\"\n")
+			h.AssertContains(t, answers, "#   number\n# Port = \"8080\"\n")
+			h.AssertContains(t, answers, "#   defaults to {{.ProjectName}}-svc\n# Service = \"\"\n")
+			h.AssertContains(t, answers, "# Owners = [\"alice\", \"bob\"]\n")
+
+			// every line is a comment until answers are uncommented
+			read, err := internal.ReadAnswers(writeAnswersFile(t, tmpDir, answers))
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(read), 0)
+		})
+
+		it("reads the uncommented answers as valid answers", func() {
+			var b bytes.Buffer
+			h.AssertNil(t, internal.WriteAnswers(&b, "https://example.com/template", template.Arguments()))
+			answers := strings.ReplaceAll(b.String(), "\n# Port = ", "\nPort = ")
+			answers = strings.ReplaceAll(answers, "\n# Owners = ", "\nOwners = ")
+			answers = strings.ReplaceAll(answers, "# ProjectName = \"\"", "ProjectName = \"api\"")
+
+			read, err := internal.ReadAnswers(writeAnswersFile(t, tmpDir, answers))
+			h.AssertNil(t, err)
+			answered, err := template.Answer(read)
+			h.AssertNil(t, err)
+			values, err := answered.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["ProjectName"], "api")
+			h.AssertEq(t, values["Port"], "8080")
+			h.AssertEq(t, values["Owners"], "alice\nbob")
+		})
+	})
+
+	when("answers are checked against a template", func() {
+		it.Before(func() {
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, internal.PromptFile), []byte(promptFile), 0644))
+		})
+
+		it("accepts complete and valid answers", func() {
+			err := internal.CheckAnswers(tmpDir, nil, map[string]interface{}{"ProjectName": "api", "Port": int64(9000)}, nil, nil, internal.ChoiceMatching{}, nil)
+			h.AssertNil(t, err)
+		})
+
+		it("reports every invalid, unknown and missing answer", func() {
+			err := internal.CheckAnswers(tmpDir, nil, map[string]interface{}{
+				"Language": "rust",
+				"Port":     "eighty",
+				"Colour":   "blue",
+			}, nil, nil, internal.ChoiceMatching{}, nil)
+			var answerErr internal.AnswerError
+			h.AssertTrue(t, errors.As(err, &answerErr))
+			h.AssertEq(t, answerErr.Problems, []string{
+				"Language: rust is not one of go, python",
+				"Port: eighty is not a number",
+				"Colour: the template has no such prompt",
+				"ProjectName: a value is required",
+			})
+		})
+
+		it("accepts required values provided as arguments", func() {
+			err := internal.CheckAnswers(tmpDir, map[string]string{"ProjectName": "api"}, map[string]interface{}{}, nil, nil, internal.ChoiceMatching{}, nil)
+			h.AssertNil(t, err)
+		})
+	})
+
+	when("an answers file is malformed", func() {
+		it("names the file", func() {
+			_, err := internal.ReadAnswers(writeAnswersFile(t, tmpDir, "ProjectName = "))
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "file does not match required format")
+		})
+	})
+}
+
+func writeAnswersFile(t *testing.T, dir string, content string) string {
+	t.Helper()
+	answersFile := filepath.Join(dir, "answers.toml")
+	h.AssertNil(t, os.WriteFile(answersFile, []byte(content), 0600))
+	return answersFile
+}
@@ -0,0 +1,259 @@
+package internal
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// MaxArchiveSize is the maximum number of bytes extracted from an archive
+const MaxArchiveSize int64 = 1 << 30
+
+var archiveSuffixes = []string{".tar.gz", ".tgz", ".zip"}
+
+// IsArchive reports whether url points to a .tar.gz or .zip archive
+func IsArchive(url string) bool {
+	for _, suffix := range archiveSuffixes {
+		if strings.HasSuffix(strings.ToLower(url), suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Download, if necessary, and extract an archive into tmpDir
+func fetchArchive(url string, tmpDir string, opts FetchOptions) error {
+	archiveFile := url
+	if _, err := os.Stat(url); err != nil {
+		downloaded, err := download(url, opts)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(downloaded)
+		archiveFile = downloaded
+	}
+
+	reportProgress(url, opts, "Extracting", -1)
+	var err error
+	if strings.HasSuffix(strings.ToLower(url), ".zip") {
+		err = extractZip(archiveFile, tmpDir, opts)
+	} else {
+		err = extractTarGz(archiveFile, tmpDir, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to extract archive %s: %s", url, err)
+	}
+	return nil
+}
+
+// Find the root of an extracted archive.  Where all files in the archive are
+// in a single top-level folder, as is usual for release artifacts, then that
+// folder is the root.
+func archiveRoot(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(dir, entries[0].Name()), nil
+	}
+	return dir, nil
+}
+
+func download(url string, opts FetchOptions) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	if auth, ok := FindHTTPAuth(url, opts).(*githttp.BasicAuth); ok {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+	client, err := newHTTPClient(opts)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(url, opts, resp)
+	}
+
+	f, err := os.CreateTemp("", "scafall-archive")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	body := &progressReader{Reader: resp.Body, url: url, opts: opts, size: resp.ContentLength, percent: -1}
+	// one byte more than the limit is read to tell an archive of exactly
+	// MaxArchiveSize bytes from a larger one
+	n, err := io.Copy(f, io.LimitReader(body, MaxArchiveSize+1))
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	if n > MaxArchiveSize {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("archive %s is larger than %d bytes", url, MaxArchiveSize)
+	}
+	return f.Name(), nil
+}
+
+// Join an archive entry name to dest, refusing names that escape dest
+func safeJoin(dest string, name string) (string, error) {
+	target := filepath.Join(dest, name)
+	if target != dest && !strings.HasPrefix(target, dest+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %s is outside of the archive", name)
+	}
+	return target, nil
+}
+
+// Write a regular file from an archive, counting the bytes written against
+// the remaining budget
+func writeArchiveFile(target string, mode os.FileMode, r io.Reader, remaining *int64) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	n, err := io.Copy(f, io.LimitReader(r, *remaining+1))
+	if err != nil {
+		return err
+	}
+	*remaining -= n
+	if *remaining < 0 {
+		return fmt.Errorf("archive is larger than %d bytes", MaxArchiveSize)
+	}
+	return nil
+}
+
+func extractTarGz(archiveFile string, dest string, opts FetchOptions) error {
+	f, err := os.Open(archiveFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	remaining := MaxArchiveSize
+	return extractTar(gz, dest, &remaining, opts)
+}
+
+// Extract a tar stream, which may be gzip compressed, such as a template
+// piped to stdin
+func extractStream(r io.Reader, dest string, opts FetchOptions) error {
+	br := bufio.NewReader(r)
+	var tr io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		tr = gz
+	}
+
+	remaining := MaxArchiveSize
+	if err := extractTar(tr, dest, &remaining, opts); err != nil {
+		return fmt.Errorf("failed to extract template stream: %s", err)
+	}
+	return nil
+}
+
+// Extract directories and regular files.  Links and special files are skipped
+// and reported to opts.Warn.
+func extractTar(r io.Reader, dest string, remaining *int64, opts FetchOptions) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// OCI whiteout files mark deletions from earlier layers
+		if strings.HasPrefix(filepath.Base(header.Name), ".wh.") {
+			continue
+		}
+
+		target, err := safeJoin(dest, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeArchiveFile(target, os.FileMode(header.Mode), tr, remaining); err != nil {
+				return err
+			}
+		case tar.TypeSymlink, tar.TypeLink:
+			opts.warn(header.Name, fmt.Sprintf("link to %s is skipped", header.Linkname))
+		case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+			opts.warn(header.Name, "special file is skipped")
+		}
+	}
+}
+
+// Extract directories and regular files.  Links and special files are skipped
+// and reported to opts.Warn.
+func extractZip(archiveFile string, dest string, opts FetchOptions) error {
+	zr, err := zip.OpenReader(archiveFile)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	remaining := MaxArchiveSize
+	for _, entry := range zr.File {
+		target, err := safeJoin(dest, entry.Name)
+		if err != nil {
+			return err
+		}
+		mode := entry.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			r, err := entry.Open()
+			if err != nil {
+				return err
+			}
+			err = writeArchiveFile(target, mode, r, &remaining)
+			r.Close()
+			if err != nil {
+				return err
+			}
+		case mode&os.ModeSymlink != 0:
+			opts.warn(entry.Name, "link is skipped")
+		default:
+			opts.warn(entry.Name, "special file is skipped")
+		}
+	}
+	return nil
+}
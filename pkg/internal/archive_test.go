@@ -0,0 +1,205 @@
+package internal_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func writeTarGz(t *testing.T, archive string, files map[string]string) {
+	f, err := os.Create(archive)
+	h.AssertNil(t, err)
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	defer gz.Close()
+	tw := tar.NewWriter(gz)
+	defer tw.Close()
+
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		h.AssertNil(t, err)
+		_, err = tw.Write([]byte(content))
+		h.AssertNil(t, err)
+	}
+}
+
+func writeZip(t *testing.T, archive string, files map[string]string) {
+	f, err := os.Create(archive)
+	h.AssertNil(t, err)
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	defer zw.Close()
+
+	for name, content := range files {
+		w, err := zw.Create(name)
+		h.AssertNil(t, err)
+		_, err = w.Write([]byte(content))
+		h.AssertNil(t, err)
+	}
+}
+
+func testArchive(t *testing.T, when spec.G, it spec.S) {
+	var (
+		archiveDir string
+		tmpDir     string
+	)
+
+	it.Before(func() {
+		archiveDir, _ = os.MkdirTemp("", "test")
+		tmpDir, _ = os.MkdirTemp("", "test")
+	})
+
+	it.After(func() {
+		os.RemoveAll(archiveDir)
+		os.RemoveAll(tmpDir)
+	})
+
+	when("the url is a tar.gz archive", func() {
+		it("extracts the archive and uses the top-level folder", func() {
+			archive := filepath.Join(archiveDir, "template-v1.0.0.tar.gz")
+			writeTarGz(t, archive, map[string]string{
+				"template-v1.0.0/prompts.toml":    "",
+				"template-v1.0.0/{{.Foo}}/foo.go": "{{.Foo}}",
+			})
+
+			root, err := internal.URLToFs(archive, tmpDir, internal.FetchOptions{})
+			h.AssertNil(t, err)
+			h.AssertEq(t, root, filepath.Join(tmpDir, "template-v1.0.0"))
+			content, err := internal.ReadFile(filepath.Join(root, "{{.Foo}}", "foo.go"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, content, "{{.Foo}}")
+		})
+	})
+
+	when("the url is a zip archive", func() {
+		it("extracts the archive and uses a sub path", func() {
+			archive := filepath.Join(archiveDir, "templates.zip")
+			writeZip(t, archive, map[string]string{
+				"one/prompts.toml": "",
+				"two/prompts.toml": "",
+			})
+
+			root, err := internal.URLToFs(archive, tmpDir, internal.FetchOptions{SubPath: "two"})
+			h.AssertNil(t, err)
+			_, err = os.Stat(filepath.Join(root, internal.PromptFile))
+			h.AssertNil(t, err)
+		})
+
+		it("refuses entries outside of the archive", func() {
+			archive := filepath.Join(archiveDir, "evil.zip")
+			writeZip(t, archive, map[string]string{
+				"../../evil.txt": "evil",
+			})
+
+			_, err := internal.URLToFs(archive, tmpDir, internal.FetchOptions{})
+			h.AssertNotNil(t, err)
+		})
+	})
+
+	when("the template is a tar stream", func() {
+		it("extracts a gzip compressed stream", func() {
+			archive := filepath.Join(archiveDir, "template.tar.gz")
+			writeTarGz(t, archive, map[string]string{
+				"template/prompts.toml": "",
+				"template/main.go":      "package main",
+			})
+			f, err := os.Open(archive)
+			h.AssertNil(t, err)
+			defer f.Close()
+
+			root, err := internal.URLToFs("", tmpDir, internal.FetchOptions{Reader: f})
+			h.AssertNil(t, err)
+			h.AssertEq(t, root, filepath.Join(tmpDir, "template"))
+		})
+
+		it("extracts an uncompressed stream", func() {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: "prompts.toml", Mode: 0644, Typeflag: tar.TypeReg}))
+			h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: "main.go", Mode: 0644, Size: 12, Typeflag: tar.TypeReg}))
+			_, err := tw.Write([]byte("package main"))
+			h.AssertNil(t, err)
+			h.AssertNil(t, tw.Close())
+
+			root, err := internal.URLToFs("", tmpDir, internal.FetchOptions{Reader: &buf})
+			h.AssertNil(t, err)
+			content, err := internal.ReadFile(filepath.Join(root, "main.go"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, content, "package main")
+		})
+	})
+
+	when("the archive is downloaded", func() {
+		var archive string
+
+		it.Before(func() {
+			archive = filepath.Join(archiveDir, "template.tar.gz")
+			writeTarGz(t, archive, map[string]string{"prompts.toml": ""})
+		})
+
+		serveArchive := func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, archive)
+		}
+
+		it("trusts the certificates in the CA bundle", func() {
+			server := httptest.NewTLSServer(http.HandlerFunc(serveArchive))
+			defer server.Close()
+			bundle := filepath.Join(archiveDir, "ca.pem")
+			certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			h.AssertNil(t, os.WriteFile(bundle, certificate, 0600))
+
+			_, err := internal.URLToFs(server.URL+"/template.tar.gz", tmpDir, internal.FetchOptions{})
+			h.AssertNotNil(t, err)
+			_, err = internal.URLToFs(server.URL+"/template.tar.gz", tmpDir, internal.FetchOptions{CABundle: bundle})
+			h.AssertNil(t, err)
+		})
+
+		it("fails for a CA bundle without certificates", func() {
+			bundle := filepath.Join(archiveDir, "ca.pem")
+			h.AssertNil(t, os.WriteFile(bundle, []byte("not a certificate"), 0600))
+
+			_, err := internal.URLToFs("https://example.com/template.tar.gz", tmpDir, internal.FetchOptions{CABundle: bundle})
+			h.AssertNotNil(t, err)
+		})
+
+		it("reports progress", func() {
+			server := httptest.NewServer(http.HandlerFunc(serveArchive))
+			defer server.Close()
+
+			events := []internal.FetchEvent{}
+			_, err := internal.URLToFs(server.URL+"/template.tar.gz", tmpDir, internal.FetchOptions{
+				Progress: func(event internal.FetchEvent) {
+					events = append(events, event)
+				},
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, events[0].Stage, "Downloading")
+			h.AssertEq(t, events[len(events)-1], internal.FetchEvent{URL: server.URL + "/template.tar.gz", Percent: 100, Done: true})
+		})
+
+		it("fetches through the proxy", func() {
+			proxied := ""
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				proxied = r.URL.String()
+				serveArchive(w, r)
+			}))
+			defer proxy.Close()
+
+			_, err := internal.URLToFs("http://templates.invalid/template.tar.gz", tmpDir, internal.FetchOptions{Proxy: proxy.URL})
+			h.AssertNil(t, err)
+			h.AssertEq(t, proxied, "http://templates.invalid/template.tar.gz")
+		})
+	})
+}
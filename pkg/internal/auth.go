@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// AuthError reports that a template could not be fetched because the server
+// requires credentials, or rejected the credentials that were provided
+type AuthError struct {
+	URL string
+	// Variable is the environment variable from which an access token for
+	// the host of URL is read, empty for other hosts
+	Variable string
+	// Authenticated is true when credentials were provided but rejected
+	Authenticated bool
+	Err           error
+}
+
+func (e AuthError) Error() string {
+	switch e.Code() {
+	case CodeAuthRejected:
+		return message(CodeAuthRejected, e.URL, e.Err)
+	case CodeAuthToken:
+		return message(CodeAuthToken, e.URL, e.Variable)
+	}
+	return message(CodeAuthRequired, e.URL)
+}
+
+func (e AuthError) Code() Code {
+	if e.Authenticated {
+		return CodeAuthRejected
+	}
+	if e.Variable != "" {
+		return CodeAuthToken
+	}
+	return CodeAuthRequired
+}
+
+func (e AuthError) Unwrap() error {
+	return e.Err
+}
+
+// TokenVariable names the environment variable from which an access token for
+// the host of url is read, if any.  Hosts are matched exactly, so that a
+// token is not sent to a host that only looks like a well known host.
+func TokenVariable(url string) string {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return ""
+	}
+	for _, t := range tokenVariables {
+		hosts := append([]string{t.host}, strings.Split(os.Getenv(t.hostsVariable), ",")...)
+		for _, host := range hosts {
+			if host = strings.TrimSpace(host); host != "" && strings.EqualFold(u.Hostname(), host) {
+				return t.variable
+			}
+		}
+	}
+	return ""
+}
+
+// Report a failure to clone url that was caused by missing or rejected
+// credentials as an AuthError, other errors are unchanged
+func authError(url string, opts FetchOptions, err error) error {
+	if !errors.Is(err, transport.ErrAuthenticationRequired) && !errors.Is(err, transport.ErrAuthorizationFailed) {
+		return err
+	}
+	return newAuthError(url, opts, err)
+}
+
+// Report an HTTP 401 or 403 response to a request for url as an AuthError
+func statusError(url string, opts FetchOptions, resp *http.Response) error {
+	err := fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	if resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden {
+		return err
+	}
+	return newAuthError(url, opts, err)
+}
+
+func newAuthError(url string, opts FetchOptions, err error) AuthError {
+	return AuthError{
+		URL:           url,
+		Variable:      TokenVariable(url),
+		Authenticated: FindHTTPAuth(url, opts) != nil,
+		Err:           err,
+	}
+}
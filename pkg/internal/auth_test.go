@@ -0,0 +1,100 @@
+package internal_test
+
+import (
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testAuth(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+		server *httptest.Server
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, _, ok := r.BasicAuth(); ok {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+	})
+
+	it.After(func() {
+		server.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a git server requires authentication", func() {
+		it("reports an AuthError", func() {
+			_, err := internal.URLToFs(server.URL+"/template.git", tmpDir, internal.FetchOptions{})
+			var authErr internal.AuthError
+			h.AssertTrue(t, errors.As(err, &authErr))
+			h.AssertEq(t, authErr.Authenticated, false)
+			h.AssertContains(t, err.Error(), "requires authentication")
+		})
+	})
+
+	when("an archive server requires authentication", func() {
+		it("reports an AuthError", func() {
+			_, err := internal.URLToFs(server.URL+"/template.tar.gz", tmpDir, internal.FetchOptions{})
+			var authErr internal.AuthError
+			h.AssertTrue(t, errors.As(err, &authErr))
+			h.AssertEq(t, authErr.Authenticated, false)
+		})
+
+		it("reports rejected credentials", func() {
+			// credentials are only sent over https
+			server := httptest.NewTLSServer(server.Config.Handler)
+			defer server.Close()
+			bundle := filepath.Join(tmpDir, "ca.pem")
+			certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			h.AssertNil(t, os.WriteFile(bundle, certificate, 0600))
+
+			_, err := internal.URLToFs(server.URL+"/template.tar.gz", filepath.Join(tmpDir, "template"), internal.FetchOptions{Username: "duck", Password: "quack", CABundle: bundle})
+			var authErr internal.AuthError
+			h.AssertTrue(t, errors.As(err, &authErr))
+			h.AssertEq(t, authErr.Authenticated, true)
+			h.AssertContains(t, err.Error(), "rejected")
+		})
+	})
+
+	when("the host has a token variable", func() {
+		it("names the variable", func() {
+			h.AssertEq(t, internal.TokenVariable("https://github.com/buildpacks/scafall"), "GITHUB_TOKEN")
+			h.AssertEq(t, internal.TokenVariable("https://gitlab.com/buildpacks/scafall"), "GITLAB_TOKEN")
+			h.AssertEq(t, internal.TokenVariable("https://example.com/buildpacks/scafall"), "")
+		})
+
+		it("does not name the variable for a host that looks like the host", func() {
+			h.AssertEq(t, internal.TokenVariable("https://github.evil.example/buildpacks/scafall"), "")
+			h.AssertEq(t, internal.TokenVariable("https://mygitlab-mirror.io/buildpacks/scafall"), "")
+			h.AssertEq(t, internal.TokenVariable("https://evilgithub.com/buildpacks/scafall"), "")
+		})
+
+		it("names the variable for a host listed in the hosts variable", func() {
+			hosts := os.Getenv("GITHUB_HOSTS")
+			defer os.Setenv("GITHUB_HOSTS", hosts)
+			os.Setenv("GITHUB_HOSTS", "github.example.com, ghe.example.com")
+			h.AssertEq(t, internal.TokenVariable("https://ghe.example.com/buildpacks/scafall"), "GITHUB_TOKEN")
+			h.AssertEq(t, internal.TokenVariable("https://example.com/buildpacks/scafall"), "")
+		})
+
+		it("explains how to provide the token", func() {
+			err := internal.AuthError{URL: "https://github.com/private/template", Variable: "GITHUB_TOKEN"}
+			h.AssertEq(t, err.Error(), "https://github.com/private/template requires authentication: set GITHUB_TOKEN to an access token")
+		})
+	})
+}
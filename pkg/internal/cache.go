@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	cp "github.com/otiai10/copy"
+)
+
+// DefaultCacheDir is the folder, within the user cache folder, in which
+// fetched templates are cached
+const DefaultCacheDir string = "scafall"
+
+// CacheKey identifies a fetched template in the cache.  Only the sub path of a
+// repository may be fetched, so the sub path is part of the key.
+func CacheKey(url string, ref string, subPath string) string {
+	hash := sha256.Sum256([]byte(url + "#" + ref + "//" + subPath))
+	return hex.EncodeToString(hash[:])
+}
+
+// Fetch url into tmpDir, using the cached copy when the fetcher finds that
+// the template has not changed since it was cached, and cache the fetched
+// template otherwise
+func fetchCached(url string, tmpDir string, opts FetchOptions) error {
+	if opts.CacheDir == "" {
+		return fetch(url, tmpDir, opts)
+	}
+	cacheEntry := filepath.Join(opts.CacheDir, CacheKey(url, opts.Ref, opts.SubPath))
+	// a template whose revision cannot be found is always fetched
+	revision := ""
+	if f, ok := fetcherFor(url).(RevisionFetcher); ok {
+		revision, _ = f.Revision(url, opts)
+	}
+	if revision != "" && cachedRevision(cacheEntry) == revision {
+		return cp.Copy(cacheEntry, tmpDir)
+	}
+	if err := fetch(url, tmpDir, opts); err != nil {
+		return err
+	}
+	return storeInCache(tmpDir, cacheEntry, revision)
+}
+
+// The revision of the template in cacheEntry, empty when it is not known
+func cachedRevision(cacheEntry string) string {
+	revision, err := os.ReadFile(cacheEntry + revisionSuffix)
+	if err != nil {
+		return ""
+	}
+	return string(revision)
+}
+
+// The revision of a cache entry is recorded beside it, so that it is not
+// copied with the template
+const revisionSuffix = ".revision"
+
+// Copy a fetched template into the cache, replacing any earlier copy, and
+// record its revision when it is known.  The copy is made beside the cache
+// entry and renamed so that concurrent readers never see a partial entry.
+func storeInCache(fetched string, cacheEntry string, revision string) error {
+	if err := os.MkdirAll(filepath.Dir(cacheEntry), 0700); err != nil {
+		return err
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(cacheEntry), "staging")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	err = cp.Copy(fetched, staging, cp.Options{
+		Skip: func(src string) (bool, error) {
+			return filepath.Base(src) == ".git", nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(cacheEntry + revisionSuffix); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(cacheEntry); err != nil {
+		return err
+	}
+	if err := os.Rename(staging, cacheEntry); err != nil {
+		// another scaffold stored the same template concurrently
+		if _, statErr := os.Stat(cacheEntry); statErr == nil {
+			return nil
+		}
+		return err
+	}
+	if revision == "" {
+		return nil
+	}
+	return os.WriteFile(cacheEntry+revisionSuffix, []byte(revision), 0600)
+}
+
+// Copy a cached template into tmpDir
+func loadFromCache(url string, cacheEntry string, tmpDir string) error {
+	if _, err := os.Stat(cacheEntry); err != nil {
+		return fmt.Errorf("template %s is not cached; fetch it at least once without offline mode", url)
+	}
+	return cp.Copy(cacheEntry, tmpDir)
+}
@@ -0,0 +1,82 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testCache(t *testing.T, when spec.G, it spec.S) {
+	var (
+		archiveDir string
+		cacheDir   string
+		tmpDir     string
+	)
+
+	it.Before(func() {
+		archiveDir, _ = os.MkdirTemp("", "test")
+		cacheDir, _ = os.MkdirTemp("", "test")
+		tmpDir, _ = os.MkdirTemp("", "test")
+	})
+
+	it.After(func() {
+		os.RemoveAll(archiveDir)
+		os.RemoveAll(cacheDir)
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a template has been fetched", func() {
+		it("is used offline once the source is gone", func() {
+			archive := filepath.Join(archiveDir, "template-v1.0.0.tar.gz")
+			writeTarGz(t, archive, map[string]string{
+				"template-v1.0.0/prompts.toml": "",
+			})
+			_, err := internal.URLToFs(archive, tmpDir, internal.FetchOptions{CacheDir: cacheDir})
+			h.AssertNil(t, err)
+			os.Remove(archive)
+
+			offlineDir, _ := os.MkdirTemp("", "test")
+			defer os.RemoveAll(offlineDir)
+			root, err := internal.URLToFs(archive, offlineDir, internal.FetchOptions{CacheDir: cacheDir, Offline: true})
+			h.AssertNil(t, err)
+			h.AssertEq(t, root, filepath.Join(offlineDir, "template-v1.0.0"))
+			_, err = os.Stat(filepath.Join(root, internal.PromptFile))
+			h.AssertNil(t, err)
+		})
+	})
+
+	when("a sub path of a template has been fetched", func() {
+		it("is not used offline for another sub path", func() {
+			archive := filepath.Join(archiveDir, "templates.tar.gz")
+			writeTarGz(t, archive, map[string]string{
+				"go/prompts.toml":     "",
+				"python/prompts.toml": "",
+			})
+			_, err := internal.URLToFs(archive, tmpDir, internal.FetchOptions{CacheDir: cacheDir, SubPath: "go"})
+			h.AssertNil(t, err)
+
+			offlineDir, _ := os.MkdirTemp("", "test")
+			defer os.RemoveAll(offlineDir)
+			_, err = internal.URLToFs(archive, offlineDir, internal.FetchOptions{CacheDir: cacheDir, SubPath: "python", Offline: true})
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "is not cached")
+		})
+
+		it("is keyed by the sub path", func() {
+			h.AssertNotEq(t, internal.CacheKey("https://example.com/templates", "", "go"), internal.CacheKey("https://example.com/templates", "", "python"))
+		})
+	})
+
+	when("a template has not been fetched", func() {
+		it("fails offline", func() {
+			_, err := internal.URLToFs("https://example.com/template.tar.gz", tmpDir, internal.FetchOptions{CacheDir: cacheDir, Offline: true})
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "is not cached")
+		})
+	})
+}
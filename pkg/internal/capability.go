@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/buildpacks/scafall/pkg/internal/util"
+)
+
+// Capabilities of scafall that a template may require
+const (
+	ArchiveSourceCapability string = "archive-source"
+	OCISourceCapability     string = "oci-source"
+	TypedPromptsCapability  string = "typed-prompts"
+	SourceRootsCapability   string = "source-roots"
+	ExclusionsCapability    string = "exclusions"
+)
+
+// Capabilities lists every capability provided by this version of scafall
+var Capabilities = []string{
+	ArchiveSourceCapability,
+	OCISourceCapability,
+	TypedPromptsCapability,
+	SourceRootsCapability,
+	ExclusionsCapability,
+}
+
+// CheckCapabilities reports every capability in requires that is either not
+// provided by this version of scafall or is disabled
+func CheckCapabilities(requires []string, disabled []string) error {
+	missing := []string{}
+	refused := []string{}
+	for _, capability := range requires {
+		switch {
+		case !util.Contains(Capabilities, capability):
+			missing = append(missing, capability)
+		case util.Contains(disabled, capability):
+			refused = append(refused, capability)
+		}
+	}
+
+	problems := []string{}
+	if len(missing) != 0 {
+		problems = append(problems, fmt.Sprintf("%s not provided by this version of scafall, which provides %s", strings.Join(missing, ", "), strings.Join(Capabilities, ", ")))
+	}
+	if len(refused) != 0 {
+		problems = append(problems, fmt.Sprintf("%s disabled by policy", strings.Join(refused, ", ")))
+	}
+	if len(problems) != 0 {
+		return fmt.Errorf("template requires capabilities that are %s", strings.Join(problems, "; and "))
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package internal_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testCapabilities(t *testing.T, when spec.G, it spec.S) {
+	when("a template requires capabilities", func() {
+		it("accepts capabilities that are provided", func() {
+			err := internal.CheckCapabilities([]string{internal.OCISourceCapability, internal.SourceRootsCapability}, nil)
+			h.AssertNil(t, err)
+		})
+
+		it("names capabilities that are not provided", func() {
+			err := internal.CheckCapabilities([]string{"hooks", internal.OCISourceCapability, "multi-select"}, nil)
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "hooks, multi-select not provided")
+		})
+
+		it("names capabilities that are disabled by policy", func() {
+			err := internal.CheckCapabilities([]string{internal.OCISourceCapability}, []string{internal.OCISourceCapability})
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "oci-source disabled by policy")
+		})
+	})
+
+	when("a prompts.toml file lists required capabilities", func() {
+		it("reads the required capabilities", func() {
+			promptFile := io.NopCloser(strings.NewReader(`requires = ["typed-prompts"]`))
+			template, err := internal.NewTemplate(promptFile, nil, nil)
+			h.AssertNil(t, err)
+			h.AssertEq(t, template.Requires(), []string{internal.TypedPromptsCapability})
+		})
+	})
+
+	when("a policy disables a source capability", func() {
+		it("refuses templates from that source", func() {
+			policy := internal.Policy{Disable: []string{internal.OCISourceCapability}}
+			h.AssertNotNil(t, policy.Check("oci://ghcr.io/acme/template:v1"))
+			h.AssertNil(t, policy.Check("https://github.com/acme/template"))
+		})
+	})
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// Checkpoint records a scaffold that failed while its project was written,
+// keeping the fetched template so that the project can be finished without
+// fetching the template or prompting again
+type Checkpoint struct {
+	URL      string `toml:"url"`
+	Template string `toml:"template,omitempty"`
+	// Staged is the folder holding the fetched template, the files that were
+	// written before the failure are no longer in it
+	Staged       string `toml:"staged"`
+	OutputFolder string `toml:"output-folder"`
+	// Variables are the values of the template variables, except the answers
+	// to prompts marked record = false
+	Variables map[string]string `toml:"variables"`
+	// Error is the reason the scaffold failed
+	Error string `toml:"error"`
+}
+
+// WriteCheckpoint writes checkpoint to checkpointFile, which is only readable
+// by its owner
+func WriteCheckpoint(checkpoint Checkpoint, checkpointFile string) error {
+	if err := os.MkdirAll(filepath.Dir(checkpointFile), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(checkpointFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return toml.NewEncoder(f).Encode(checkpoint)
+}
+
+// ReadCheckpoint reads the Checkpoint in checkpointFile
+func ReadCheckpoint(checkpointFile string) (Checkpoint, error) {
+	checkpoint := Checkpoint{}
+	checkpointData, err := ReadFile(checkpointFile)
+	if err != nil {
+		return checkpoint, err
+	}
+
+	if _, err := toml.Decode(checkpointData, &checkpoint); err != nil {
+		return checkpoint, errors.Wrap(err, fmt.Sprintf("%s file does not match required format", checkpointFile))
+	}
+	if checkpoint.URL == "" || checkpoint.Staged == "" || checkpoint.OutputFolder == "" {
+		return checkpoint, fmt.Errorf("%s file is missing required field; url, staged and output-folder required", checkpointFile)
+	}
+	if checkpoint.Variables == nil {
+		checkpoint.Variables = map[string]string{}
+	}
+	return checkpoint, nil
+}
@@ -0,0 +1,56 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testCheckpoint(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a scaffold fails while its project is written", func() {
+		it("records the scaffold so that it can be resumed", func() {
+			checkpointFile := filepath.Join(tmpDir, "scafall", "resume.toml")
+			checkpoint := internal.Checkpoint{
+				URL:          "gh:org/template",
+				Template:     "api",
+				Staged:       filepath.Join(tmpDir, "staged"),
+				OutputFolder: filepath.Join(tmpDir, "shop"),
+				Variables:    map[string]string{"name": "shop"},
+				Error:        "no space left on device",
+			}
+			h.AssertNil(t, internal.WriteCheckpoint(checkpoint, checkpointFile))
+
+			info, err := os.Stat(checkpointFile)
+			h.AssertNil(t, err)
+			h.AssertEq(t, info.Mode().Perm(), os.FileMode(0600))
+			read, err := internal.ReadCheckpoint(checkpointFile)
+			h.AssertNil(t, err)
+			h.AssertEq(t, read, checkpoint)
+		})
+
+		it("requires the staged template and the output folder", func() {
+			checkpointFile := filepath.Join(tmpDir, "resume.toml")
+			h.AssertNil(t, os.WriteFile(checkpointFile, []byte(`url = "gh:org/template"`), 0600))
+
+			_, err := internal.ReadCheckpoint(checkpointFile)
+			h.AssertError(t, err, "url, staged and output-folder required")
+		})
+	})
+}
@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/BurntSushi/toml"
+
+	"github.com/buildpacks/scafall/pkg/internal/util"
+)
+
+// ChoiceMatching controls how arguments, answers and facts are matched
+// against the choices of a prompt.  Values provided by CI systems often
+// differ from a choice only in case or surrounding white space.
+type ChoiceMatching struct {
+	// IgnoreCase matches a value that differs from a choice only in case
+	IgnoreCase bool
+	// TrimSpace matches a value with leading or trailing white space
+	TrimSpace bool
+}
+
+// Match finds the choice matching value.  A value that matches a choice
+// exactly is always matched, the choice is returned so that a matched value
+// is written as the template author wrote it.
+func (m ChoiceMatching) Match(choices []string, value string) (string, bool) {
+	if util.Contains(choices, value) {
+		return value, true
+	}
+	if !m.IgnoreCase && !m.TrimSpace {
+		return value, false
+	}
+	for _, choice := range choices {
+		want, got := choice, value
+		if m.TrimSpace {
+			want, got = strings.TrimSpace(want), strings.TrimSpace(got)
+		}
+		if want == got || (m.IgnoreCase && strings.EqualFold(want, got)) {
+			return choice, true
+		}
+	}
+	return value, false
+}
+
+// Match value against choices, each item of the answer to a list prompt must
+// match a choice
+func matchChoices(prompt Prompt, choices []string, value string, matching ChoiceMatching) (string, error) {
+	items := []string{value}
+	if prompt.Type == ListType {
+		items = SplitList(value)
+	}
+	for i, item := range items {
+		choice, ok := matching.Match(choices, item)
+		if !ok {
+			return value, fmt.Errorf("%s is not one of %s", item, strings.Join(choices, ", "))
+		}
+		items[i] = choice
+	}
+	return strings.Join(items, "\n"), nil
+}
+
+// Enabled reports whether values are matched other than exactly
+func (m ChoiceMatching) Enabled() bool {
+	return m.IgnoreCase || m.TrimSpace
+}
+
+// Decode the choices of prompts from a prompt file.  A choice is written as a
+// string or as a table, such as { label = "PostgreSQL", value = "postgres" },
+// whose label is shown to the end-user and whose value is given to the
+// template.  The tables are checked by validatePromptFile.
+func decodeChoices(promptData string, prompts []Prompt) error {
+	raw := struct {
+		Prompts []struct {
+			Choices []interface{} `toml:"choices"`
+		} `toml:"prompt"`
+	}{}
+	if _, err := toml.Decode(promptData, &raw); err != nil {
+		return err
+	}
+	for i := range prompts {
+		if i >= len(raw.Prompts) {
+			break
+		}
+		labelled := false
+		choices := raw.Prompts[i].Choices
+		prompts[i].Choices = make([]string, 0, len(choices))
+		labels := make([]string, 0, len(choices))
+		for _, choice := range choices {
+			value, label := readChoice(choice)
+			prompts[i].Choices = append(prompts[i].Choices, value)
+			labels = append(labels, label)
+			labelled = labelled || label != ""
+		}
+		if len(choices) == 0 {
+			prompts[i].Choices = nil
+		}
+		if labelled {
+			prompts[i].Labels = labels
+		}
+	}
+	return nil
+}
+
+// Read the value and label of a choice written as a string or as a table
+func readChoice(choice interface{}) (string, string) {
+	switch choice := choice.(type) {
+	case string:
+		return choice, ""
+	case map[string]interface{}:
+		value, _ := choice["value"].(string)
+		label, _ := choice["label"].(string)
+		return value, label
+	}
+	return "", ""
+}
+
+// Options are the choices of the prompt as shown to the end-user, the label of
+// each choice or, for a choice without a label, the choice itself
+func (p Prompt) Options() []string {
+	return p.labelsOf(p.Choices)
+}
+
+// The label shown for each of values, a value that is not a labelled choice
+// is shown as it is
+func (p Prompt) labelsOf(values []string) []string {
+	labels := make([]string, len(values))
+	for i, value := range values {
+		labels[i] = value
+		for j, choice := range p.Choices {
+			if choice == value && j < len(p.Labels) && p.Labels[j] != "" {
+				labels[i] = p.Labels[j]
+				break
+			}
+		}
+	}
+	return labels
+}
+
+// The choice selected by the end-user as option
+func (p Prompt) choiceOf(option core.OptionAnswer) string {
+	if option.Index >= 0 && option.Index < len(p.Choices) {
+		return p.Choices[option.Index]
+	}
+	return option.Value
+}
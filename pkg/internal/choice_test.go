@@ -0,0 +1,165 @@
+package internal_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testChoiceMatching(t *testing.T, when spec.G, it spec.S) {
+	promptFile := `[[prompt]]
+name = "Language"
+prompt = "Which language"
+choices = ["Go", "Python"]
+`
+	choices := []string{"Go", "Python"}
+	servicesPrompt := `[[prompt]]
+name = "Services"
+prompt = "Which services"
+type = "list"
+choices = ["api", "web", "worker"]
+`
+
+	when("values are matched exactly", func() {
+		it("matches only the choices as written", func() {
+			matching := internal.ChoiceMatching{}
+			choice, ok := matching.Match(choices, "Go")
+			h.AssertTrue(t, ok)
+			h.AssertEq(t, choice, "Go")
+			_, ok = matching.Match(choices, "go")
+			h.AssertTrue(t, !ok)
+			_, ok = matching.Match(choices, " Go")
+			h.AssertTrue(t, !ok)
+		})
+
+		it("rejects an answer that differs in case", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), nil, nil)
+			h.AssertNil(t, err)
+			_, err = template.Answer(map[string]interface{}{"Language": "go"})
+			var answerErr internal.AnswerError
+			h.AssertTrue(t, errors.As(err, &answerErr))
+			h.AssertEq(t, answerErr.Problems, []string{"Language: go is not one of Go, Python"})
+		})
+	})
+
+	when("a list prompt has choices", func() {
+		it("rejects an item that is not a choice", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(servicesPrompt)), nil, nil)
+			h.AssertNil(t, err)
+			_, err = template.Answer(map[string]interface{}{"Services": []string{"api", "mobile"}})
+			var answerErr internal.AnswerError
+			h.AssertTrue(t, errors.As(err, &answerErr))
+			h.AssertEq(t, answerErr.Problems, []string{"Services: mobile is not one of api, web, worker"})
+		})
+
+		it("defaults to no items", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(servicesPrompt)), nil, nil)
+			h.AssertNil(t, err)
+			values, err := template.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["Services"], "")
+		})
+	})
+
+	when("case is ignored and white space is trimmed", func() {
+		matching := internal.ChoiceMatching{IgnoreCase: true, TrimSpace: true}
+
+		it("matches the choice as written", func() {
+			choice, ok := matching.Match(choices, " python\n")
+			h.AssertTrue(t, ok)
+			h.AssertEq(t, choice, "Python")
+			_, ok = matching.Match(choices, "rust")
+			h.AssertTrue(t, !ok)
+		})
+
+		it("replaces an answer with its choice", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), nil, nil)
+			h.AssertNil(t, err)
+			answered, err := template.MatchChoices(matching).Answer(map[string]interface{}{"Language": "PYTHON "})
+			h.AssertNil(t, err)
+			values, err := answered.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["Language"], "Python")
+		})
+
+		it("replaces an argument with its choice", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), map[string]string{"Language": "go"}, nil)
+			h.AssertNil(t, err)
+			values, err := template.MatchChoices(matching).Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["Language"], "Go")
+		})
+
+		it("replaces each item of a list with its choice", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(servicesPrompt)), nil, nil)
+			h.AssertNil(t, err)
+			answered, err := template.MatchChoices(matching).Answer(map[string]interface{}{"Services": "API, Web"})
+			h.AssertNil(t, err)
+			values, err := answered.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["Services"], "api\nweb")
+		})
+
+		it("suggests a fact that differs in case", func() {
+			licensePrompt := `[[prompt]]
+name = "License"
+prompt = "Which license"
+choices = ["Apache-2.0", "MIT"]
+`
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(licensePrompt)), nil, nil)
+			h.AssertNil(t, err)
+			values, err := template.MatchChoices(matching).Suggest(map[string]string{internal.LicenseFact: "mit"}).Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["License"], "MIT")
+		})
+	})
+	when("choices have labels", func() {
+		databasePrompt := `[[prompt]]
+name = "Database"
+prompt = "Which database"
+default = "postgres"
+choices = [
+  { label = "PostgreSQL (recommended)", value = "postgres" },
+  { label = "MySQL", value = "mysql" },
+  "sqlite",
+]
+`
+
+		it("decodes the value and label of each choice", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(databasePrompt)), nil, nil)
+			h.AssertNil(t, err)
+			prompt := template.Arguments()[0]
+			h.AssertEq(t, prompt.Choices, []string{"postgres", "mysql", "sqlite"})
+			h.AssertEq(t, prompt.Options(), []string{"PostgreSQL (recommended)", "MySQL", "sqlite"})
+		})
+
+		it("answers with the value of a choice", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(databasePrompt)), nil, nil)
+			h.AssertNil(t, err)
+			answered, err := template.Answer(map[string]interface{}{"Database": "mysql"})
+			h.AssertNil(t, err)
+			values, err := answered.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["Database"], "mysql")
+
+			_, err = template.Answer(map[string]interface{}{"Database": "MySQL"})
+			var answerErr internal.AnswerError
+			h.AssertTrue(t, errors.As(err, &answerErr))
+			h.AssertEq(t, answerErr.Problems, []string{"Database: MySQL is not one of postgres, mysql, sqlite"})
+		})
+
+		it("reports a default given as a label", func() {
+			promptFile := strings.Replace(databasePrompt, `default = "postgres"`, `default = "MySQL"`, 1)
+			_, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), nil, nil)
+			var fileErr internal.PromptFileError
+			h.AssertTrue(t, errors.As(err, &fileErr))
+			h.AssertEq(t, fileErr.Problems[0].Message, "default MySQL of prompt Database is the label of a choice; use its value mysql")
+		})
+	})
+}
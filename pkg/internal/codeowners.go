@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// CodeOwnersFiles are the places in which GitHub looks for the CODEOWNERS
+// file of a repository, in the order in which they are searched
+var CodeOwnersFiles = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwners describes an entry, giving the owners of a project scaffolded
+// into an existing repository, that is added to the CODEOWNERS file of the
+// repository
+type CodeOwners struct {
+	// Variable names the template variable whose value lists the owners of
+	// the project, such as @org/team, separated by spaces or commas or given
+	// as a list
+	Variable string `toml:"variable,omitempty"`
+	// File is the CODEOWNERS file relative to the root of the repository,
+	// the first of CodeOwnersFiles that exists when empty
+	File string `toml:"file,omitempty"`
+	// When is a condition on the answers to prompts, the entry is only
+	// written when the condition is true
+	When string `toml:"when,omitempty"`
+}
+
+// WriteCodeOwners adds an entry for outputDir to the CODEOWNERS file of the
+// git repository containing it, replacing any entry for the same folder so
+// that scaffolding the same project again does not add another.  Nothing is
+// written when outputDir is not within a repository or when the variable
+// lists no owners.  A project at the root of the repository is refused, so
+// that the default owners of the repository are never replaced.
+func WriteCodeOwners(outputDir string, codeOwners CodeOwners, vars map[string]string) error {
+	if codeOwners.When != "" {
+		applies, err := EvalCondition(codeOwners.When, vars)
+		if err != nil {
+			return errors.Wrap(err, "invalid condition for codeowners")
+		}
+		if !applies {
+			return nil
+		}
+	}
+	if codeOwners.Variable == "" {
+		return fmt.Errorf("codeowners settings must name the variable that lists the owners")
+	}
+	value, ok := vars[codeOwners.Variable]
+	if !ok {
+		return fmt.Errorf("codeowners variable %s is not a variable of the template", codeOwners.Variable)
+	}
+	owners := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	if len(owners) == 0 {
+		return nil
+	}
+	if codeOwners.File != "" && isOutside(path.Clean(filepath.ToSlash(codeOwners.File))) {
+		return fmt.Errorf("codeowners file %s is outside of the repository", codeOwners.File)
+	}
+
+	root, ok := worktreeRoot(outputDir)
+	if !ok {
+		return nil
+	}
+	project, err := filepath.Abs(outputDir)
+	if err != nil {
+		return err
+	}
+	relPath, err := filepath.Rel(root, project)
+	if err != nil {
+		return err
+	}
+	// the entry for the root would take the place of the default owners of
+	// the repository
+	if relPath == "." {
+		return fmt.Errorf("codeowners cannot be added for the root of the repository %s", root)
+	}
+	pattern := "/" + filepath.ToSlash(relPath) + "/"
+
+	target := filepath.Join(root, filepath.FromSlash(codeOwnersFile(root, codeOwners.File)))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(target)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	entry := pattern + " " + strings.Join(owners, " ")
+	return os.WriteFile(target, []byte(replaceCodeOwner(string(existing), pattern, entry)), 0644)
+}
+
+// The CODEOWNERS file of the repository at root, the named file or the first
+// of CodeOwnersFiles that exists
+func codeOwnersFile(root string, file string) string {
+	if file != "" {
+		return path.Clean(filepath.ToSlash(file))
+	}
+	for _, candidate := range CodeOwnersFiles {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(candidate))); err == nil {
+			return candidate
+		}
+	}
+	return CodeOwnersFiles[0]
+}
+
+// Replace the entry of content for pattern, or append the entry when content
+// has none.  Appended entries come last, so that they take precedence over
+// the entries of the folders that contain the project.
+func replaceCodeOwner(content string, pattern string, entry string) string {
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 0 && fields[0] == pattern {
+			lines[i] = entry + "\n"
+			return strings.Join(lines, "")
+		}
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + entry + "\n"
+}
@@ -0,0 +1,115 @@
+package internal_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testCodeOwners(t *testing.T, when spec.G, it spec.S) {
+	var (
+		repoDir   string
+		outputDir string
+	)
+	vars := map[string]string{"Owners": "@acme/payments, @alice", "ci": "no"}
+	codeOwners := internal.CodeOwners{Variable: "Owners"}
+
+	it.Before(func() {
+		repoDir, _ = os.MkdirTemp("", "test")
+		os.MkdirAll(filepath.Join(repoDir, ".git"), 0755)
+		outputDir = filepath.Join(repoDir, "services", "shop")
+		os.MkdirAll(outputDir, 0755)
+	})
+
+	it.After(func() {
+		os.RemoveAll(repoDir)
+	})
+
+	read := func(file string) string {
+		content, err := os.ReadFile(filepath.Join(repoDir, filepath.FromSlash(file)))
+		h.AssertNil(t, err)
+		return string(content)
+	}
+
+	when("a project is scaffolded into a repository", func() {
+		it("adds an entry for the project to the existing CODEOWNERS once", func() {
+			os.WriteFile(filepath.Join(repoDir, "CODEOWNERS"), []byte("* @acme/platform"), 0600)
+			for i := 0; i < 2; i++ {
+				h.AssertNil(t, internal.WriteCodeOwners(outputDir, codeOwners, vars))
+			}
+			h.AssertEq(t, read("CODEOWNERS"), "* @acme/platform\n/services/shop/ @acme/payments @alice\n")
+		})
+
+		it("replaces the owners of an existing entry", func() {
+			os.WriteFile(filepath.Join(repoDir, "CODEOWNERS"), []byte("/services/shop/ @bob\n/web/ @carol\n"), 0600)
+			h.AssertNil(t, internal.WriteCodeOwners(outputDir, codeOwners, vars))
+			h.AssertEq(t, read("CODEOWNERS"), "/services/shop/ @acme/payments @alice\n/web/ @carol\n")
+		})
+
+		it("creates .github/CODEOWNERS when the repository has none", func() {
+			h.AssertNil(t, internal.WriteCodeOwners(outputDir, codeOwners, vars))
+			h.AssertEq(t, read(".github/CODEOWNERS"), "/services/shop/ @acme/payments @alice\n")
+		})
+
+		it("writes the named file", func() {
+			named := internal.CodeOwners{Variable: "Owners", File: "docs/CODEOWNERS"}
+			h.AssertNil(t, internal.WriteCodeOwners(outputDir, named, vars))
+			h.AssertEq(t, read("docs/CODEOWNERS"), "/services/shop/ @acme/payments @alice\n")
+		})
+
+		it("refuses the root of the repository and keeps the default owners", func() {
+			os.WriteFile(filepath.Join(repoDir, "CODEOWNERS"), []byte("* @acme/platform\n"), 0600)
+			err := internal.WriteCodeOwners(repoDir, codeOwners, vars)
+			h.AssertError(t, err, "codeowners cannot be added for the root of the repository")
+			h.AssertEq(t, read("CODEOWNERS"), "* @acme/platform\n")
+		})
+	})
+
+	when("no entry is wanted", func() {
+		it("writes nothing when the condition is false or no owners are given", func() {
+			conditional := internal.CodeOwners{Variable: "Owners", When: "ci == 'yes'"}
+			h.AssertNil(t, internal.WriteCodeOwners(outputDir, conditional, vars))
+			h.AssertNil(t, internal.WriteCodeOwners(outputDir, codeOwners, map[string]string{"Owners": ""}))
+			_, err := os.Stat(filepath.Join(repoDir, ".github", "CODEOWNERS"))
+			h.AssertTrue(t, os.IsNotExist(err))
+		})
+
+		it("writes nothing outside of a repository", func() {
+			os.RemoveAll(filepath.Join(repoDir, ".git"))
+			h.AssertNil(t, internal.WriteCodeOwners(outputDir, codeOwners, vars))
+			_, err := os.Stat(filepath.Join(repoDir, ".github", "CODEOWNERS"))
+			h.AssertTrue(t, os.IsNotExist(err))
+		})
+	})
+
+	when("the settings are invalid", func() {
+		it("refuses an unknown variable and a file outside of the repository", func() {
+			err := internal.WriteCodeOwners(outputDir, internal.CodeOwners{Variable: "Team"}, vars)
+			h.AssertError(t, err, "codeowners variable Team is not a variable of the template")
+			err = internal.WriteCodeOwners(outputDir, internal.CodeOwners{Variable: "Owners", File: "../CODEOWNERS"}, vars)
+			h.AssertError(t, err, "outside of the repository")
+		})
+
+		it("reports settings without a variable", func() {
+			promptFile := io.NopCloser(strings.NewReader(`[[prompt]]
+name = "Owners"
+prompt = "Who owns the project"
+
+[settings.codeowners]
+file = "CODEOWNERS"
+`))
+			_, err := internal.NewTemplate(promptFile, nil, nil)
+			var fileErr internal.PromptFileError
+			h.AssertTrue(t, errors.As(err, &fileErr))
+			h.AssertEq(t, fileErr.Problems[0].Key, "settings.codeowners.variable")
+		})
+	})
+}
@@ -1,8 +1,10 @@
 package internal
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // If there are no top level prompts and some subdirectories contain prompts,
@@ -30,3 +32,80 @@ func IsCollection(dir string) (bool, []string) {
 	}
 	return len(options) > 0, options
 }
+
+const maxListedEntries int = 20
+
+// NotATemplateError is returned when a folder is neither a project template
+// nor a collection, but project templates are found further down the tree
+type NotATemplateError struct {
+	Contents  []string
+	Templates []string
+}
+
+func (e NotATemplateError) Error() string {
+	var b strings.Builder
+	b.WriteString(message(CodeNotATemplate, PromptFile, listEntries(e.Contents)))
+	fmt.Fprintf(&b, "\nproject templates were found in: %s", listEntries(e.Templates))
+	b.WriteString("\nuse --sub-path to choose one of these project templates")
+	return b.String()
+}
+
+func (e NotATemplateError) Code() Code {
+	return CodeNotATemplate
+}
+
+func listEntries(entries []string) string {
+	if len(entries) > maxListedEntries {
+		return fmt.Sprintf("%s and %d more", strings.Join(entries[:maxListedEntries], ", "), len(entries)-maxListedEntries)
+	}
+	return strings.Join(entries, ", ")
+}
+
+// CheckTemplate ensures that dir is a project template or a collection.  A
+// folder without a prompts file is a project template with no prompts, unless
+// project templates are nested deeper in the folder.  Then the url most likely
+// needs a sub path.
+func CheckTemplate(dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, PromptFile)); err == nil {
+		return nil
+	}
+	if isCollection, _ := IsCollection(dir); isCollection {
+		return nil
+	}
+
+	templates := []string{}
+	err := filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && IsIgnoredDirectory(info.Name()) {
+			return filepath.SkipDir
+		}
+		if info.IsDir() || info.Name() != PromptFile {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		templates = append(templates, filepath.ToSlash(relPath))
+		return nil
+	})
+	if err != nil || len(templates) == 0 {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	contents := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		contents = append(contents, name)
+	}
+	return NotATemplateError{Contents: contents, Templates: templates}
+}
@@ -52,3 +52,39 @@ func testCollection(t *testing.T, when spec.G, it spec.S) {
 		})
 	}
 }
+
+func testCheckTemplate(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "scafall")
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a folder has no prompts file", func() {
+		it("is a template with no prompts", func() {
+			os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte{}, 0600)
+
+			h.AssertNil(t, internal.CheckTemplate(tmpDir))
+		})
+
+		it("explains that nested templates need a sub path", func() {
+			os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte{}, 0600)
+			os.MkdirAll(filepath.Join(tmpDir, "templates", "go"), 0700)
+			os.WriteFile(filepath.Join(tmpDir, "templates", "go", "prompts.toml"), []byte{}, 0600)
+
+			err := internal.CheckTemplate(tmpDir)
+			h.AssertNotNil(t, err)
+			h.AssertEq(t, err, internal.NotATemplateError{
+				Contents:  []string{"README.md", "templates/"},
+				Templates: []string{"templates/go"},
+			})
+			h.AssertContains(t, err.Error(), "--sub-path")
+		})
+	})
+}
@@ -0,0 +1,145 @@
+package internal_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+const concurrentScaffolds = 8
+
+// Run fn concurrently, once for each index, and return the errors by index
+func concurrently(fn func(i int) error) []error {
+	errs := make([]error, concurrentScaffolds)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrentScaffolds; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+func testConcurrency(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("projects are rendered concurrently from one template", func() {
+		it("renders each project with its own arguments", func() {
+			inputDir := filepath.Join(tmpDir, "template")
+			h.AssertNil(t, os.MkdirAll(filepath.Join(inputDir, "{{.Name}}"), 0755))
+			h.AssertNil(t, os.WriteFile(filepath.Join(inputDir, "{{.Name}}", "README.md"), []byte("{{.Name}}"), 0644))
+			h.AssertNil(t, os.WriteFile(filepath.Join(inputDir, ".gitignore"), []byte("bin/"), 0644))
+
+			errs := concurrently(func(i int) error {
+				outputDir := filepath.Join(tmpDir, fmt.Sprintf("output%d", i))
+				vars := map[string]string{"Name": fmt.Sprintf("project%d", i)}
+				return internal.Apply(inputDir, vars, outputDir, internal.Settings{})
+			})
+			for i, err := range errs {
+				h.AssertNil(t, err)
+				name := fmt.Sprintf("project%d", i)
+				content, err := os.ReadFile(filepath.Join(tmpDir, fmt.Sprintf("output%d", i), name, "README.md"))
+				h.AssertNil(t, err)
+				h.AssertEq(t, string(content), name)
+			}
+		})
+	})
+
+	when("templates are fetched concurrently into a shared cache", func() {
+		it("fetches every template", func() {
+			archive := filepath.Join(tmpDir, "template-v1.0.0.tar.gz")
+			writeTarGz(t, archive, map[string]string{
+				"template-v1.0.0/prompts.toml": "",
+			})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.ServeFile(w, r, archive)
+			}))
+			defer server.Close()
+			cacheDir := filepath.Join(tmpDir, "cache")
+
+			errs := concurrently(func(i int) error {
+				fetchDir := filepath.Join(tmpDir, fmt.Sprintf("fetch%d", i))
+				if err := os.MkdirAll(fetchDir, 0755); err != nil {
+					return err
+				}
+				root, err := internal.URLToFs(server.URL+"/template-v1.0.0.tar.gz", fetchDir, internal.FetchOptions{CacheDir: cacheDir})
+				if err != nil {
+					return err
+				}
+				_, err = os.Stat(filepath.Join(root, internal.PromptFile))
+				return err
+			})
+			for _, err := range errs {
+				h.AssertNil(t, err)
+			}
+		})
+	})
+
+	when("templates are cloned concurrently with different proxies", func() {
+		it("clones each template with its own proxy", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			defer server.Close()
+
+			// clones through the unreachable proxy never reach the server
+			errs := concurrently(func(i int) error {
+				cloneDir := filepath.Join(tmpDir, fmt.Sprintf("clone%d", i))
+				if err := os.MkdirAll(cloneDir, 0755); err != nil {
+					return err
+				}
+				opts := internal.FetchOptions{}
+				if i%2 == 1 {
+					opts.Proxy = "http://127.0.0.1:1"
+				}
+				_, err := internal.URLToFs(server.URL+"/template.git", cloneDir, opts)
+				return err
+			})
+			for i, err := range errs {
+				var authErr internal.AuthError
+				h.AssertNotNil(t, err)
+				h.AssertEq(t, errors.As(err, &authErr), i%2 == 0)
+			}
+		})
+
+		it("puts back the transports of go-git once the clones finish", func() {
+			previous := client.Protocols["http"]
+			errs := concurrently(func(i int) error {
+				cloneDir := filepath.Join(tmpDir, fmt.Sprintf("clone%d", i))
+				if err := os.MkdirAll(cloneDir, 0755); err != nil {
+					return err
+				}
+				_, err := internal.URLToFs("http://templates.invalid/template.git", cloneDir, internal.FetchOptions{Proxy: "http://127.0.0.1:1"})
+				return err
+			})
+			for _, err := range errs {
+				h.AssertNotNil(t, err)
+			}
+			h.AssertEq(t, client.Protocols["http"] == previous, true)
+		})
+	})
+}
@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Tokens of a condition, such as use_database == 'postgres' and not minimal
+var conditionToken = regexp.MustCompile(`\s*(==|!=|&&|\|\||!|\(|\)|'[^']*'|"[^"]*"|[A-Za-z_][A-Za-z0-9_]*|-?[0-9][0-9.]*|\S)`)
+
+// Values that are false when a variable is used as a condition on its own
+var falseValues = []string{"", "false", "no", "n", "0", "off"}
+
+// EvalCondition evaluates the when clause of a prompt using the answers to
+// earlier prompts.  A condition compares variables and quoted strings with ==
+// and !=, and combines comparisons with and, or, not and parentheses.  A
+// variable on its own is true unless it is empty, false, no, n, 0 or off.
+// The ContextVariables, such as __OS, can also be used.
+func EvalCondition(condition string, vars map[string]string) (bool, error) {
+	p, err := newConditionParser(condition, WithContextVariables(vars))
+	if err != nil {
+		return false, err
+	}
+	value, err := p.or()
+	if err != nil {
+		return false, err
+	}
+	if p.pos < len(p.tokens) {
+		return false, fmt.Errorf("unexpected %s in condition %s", p.tokens[p.pos], condition)
+	}
+	return truthy(value), nil
+}
+
+// ConditionVariables lists the variables used by a condition
+func ConditionVariables(condition string) ([]string, error) {
+	if _, err := EvalCondition(condition, map[string]string{}); err != nil {
+		return nil, err
+	}
+	p, _ := newConditionParser(condition, nil)
+	names := []string{}
+	for _, token := range p.tokens {
+		if isIdentifier(token) && !isKeyword(token) {
+			names = append(names, token)
+		}
+	}
+	return names, nil
+}
+
+type conditionParser struct {
+	condition string
+	tokens    []string
+	pos       int
+	vars      map[string]string
+}
+
+func newConditionParser(condition string, vars map[string]string) (*conditionParser, error) {
+	p := &conditionParser{condition: condition, vars: vars}
+	for _, match := range conditionToken.FindAllStringSubmatch(condition, -1) {
+		p.tokens = append(p.tokens, match[1])
+	}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("condition is empty")
+	}
+	return p, nil
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *conditionParser) accept(tokens ...string) bool {
+	for _, token := range tokens {
+		if p.peek() == token {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *conditionParser) or() (string, error) {
+	left, err := p.and()
+	if err != nil {
+		return "", err
+	}
+	for p.accept("or", "||") {
+		right, err := p.and()
+		if err != nil {
+			return "", err
+		}
+		left = boolValue(truthy(left) || truthy(right))
+	}
+	return left, nil
+}
+
+func (p *conditionParser) and() (string, error) {
+	left, err := p.not()
+	if err != nil {
+		return "", err
+	}
+	for p.accept("and", "&&") {
+		right, err := p.not()
+		if err != nil {
+			return "", err
+		}
+		left = boolValue(truthy(left) && truthy(right))
+	}
+	return left, nil
+}
+
+func (p *conditionParser) not() (string, error) {
+	if p.accept("not", "!") {
+		value, err := p.not()
+		if err != nil {
+			return "", err
+		}
+		return boolValue(!truthy(value)), nil
+	}
+	return p.comparison()
+}
+
+func (p *conditionParser) comparison() (string, error) {
+	left, err := p.operand()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case p.accept("=="):
+		right, err := p.operand()
+		return boolValue(left == right), err
+	case p.accept("!="):
+		right, err := p.operand()
+		return boolValue(left != right), err
+	}
+	return left, nil
+}
+
+func (p *conditionParser) operand() (string, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return "", fmt.Errorf("condition %s ends unexpectedly", p.condition)
+	case token == "(":
+		p.pos++
+		value, err := p.or()
+		if err != nil {
+			return "", err
+		}
+		if !p.accept(")") {
+			return "", fmt.Errorf("missing ) in condition %s", p.condition)
+		}
+		return value, nil
+	case token == "true" || token == "false":
+		p.pos++
+		return token, nil
+	case isKeyword(token):
+		return "", fmt.Errorf("unexpected %s in condition %s", token, p.condition)
+	case isIdentifier(token):
+		p.pos++
+		return p.vars[token], nil
+	case len(token) >= 2 && (strings.HasPrefix(token, "'") || strings.HasPrefix(token, `"`)):
+		p.pos++
+		return token[1 : len(token)-1], nil
+	case token[0] == '-' || (token[0] >= '0' && token[0] <= '9'):
+		p.pos++
+		return token, nil
+	}
+	return "", fmt.Errorf("unexpected %s in condition %s", token, p.condition)
+}
+
+func isIdentifier(token string) bool {
+	return identifierPattern.MatchString(token)
+}
+
+func isKeyword(token string) bool {
+	switch token {
+	case "and", "or", "not", "true", "false":
+		return true
+	}
+	return false
+}
+
+func truthy(value string) bool {
+	for _, f := range falseValues {
+		if strings.EqualFold(value, f) {
+			return false
+		}
+	}
+	return true
+}
+
+func boolValue(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
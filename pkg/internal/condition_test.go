@@ -0,0 +1,78 @@
+package internal_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testCondition(t *testing.T, when spec.G, it spec.S) {
+	type TestCase struct {
+		condition string
+		expected  bool
+	}
+	vars := map[string]string{"use_database": "postgres", "minimal": "no", "replicas": "3"}
+	testCases := []TestCase{
+		{"use_database == 'postgres'", true},
+		{`use_database != "postgres"`, false},
+		{"minimal", false},
+		{"not minimal", true},
+		{"!minimal && replicas == 3", true},
+		{"use_database == 'mysql' or (not minimal and replicas != '1')", true},
+		{"unknown", false},
+	}
+	for _, testCase := range testCases {
+		current := testCase
+		when("a condition is evaluated", func() {
+			it("is "+current.condition, func() {
+				value, err := internal.EvalCondition(current.condition, vars)
+				h.AssertNil(t, err)
+				h.AssertEq(t, value, current.expected)
+			})
+		})
+	}
+
+	for _, condition := range []string{"", "use_database ==", "(minimal", "'postgres", "minimal minimal"} {
+		current := condition
+		when("an invalid condition is evaluated", func() {
+			it("fails for "+current, func() {
+				_, err := internal.EvalCondition(current, vars)
+				h.AssertNotNil(t, err)
+			})
+		})
+	}
+
+	when("a prompt has a condition", func() {
+		promptFile := `[[prompt]]
+name = "use_database"
+prompt = "Which database"
+choices = ["none", "postgres"]
+
+[[prompt]]
+name = "database_name"
+prompt = "Database name"
+required = true
+when = "use_database == 'postgres'"
+`
+		it("is not asked when the condition is false", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), map[string]string{"use_database": "none"}, nil)
+			h.AssertNil(t, err)
+			values, err := template.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["database_name"], "")
+		})
+
+		it("is asked when the condition is true", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), map[string]string{"use_database": "postgres"}, nil)
+			h.AssertNil(t, err)
+			_, err = template.Defaults()
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "database_name is required")
+		})
+	})
+}
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"os"
+	"os/user"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5/config"
+)
+
+const (
+	// YearVariable and DateVariable are the current year and the current
+	// date in CanonicalDateLayout
+	YearVariable string = ReservedPrefix + "Year"
+	DateVariable string = ReservedPrefix + "Date"
+	// UserVariable is the login name of the end-user
+	UserVariable string = ReservedPrefix + "User"
+	// GitNameVariable and GitEmailVariable are the user.name and user.email
+	// of the git configuration of the end-user
+	GitNameVariable  string = ReservedPrefix + "GitName"
+	GitEmailVariable string = ReservedPrefix + "GitEmail"
+	// OSVariable and ArchVariable are the operating system and architecture
+	// on which scafall runs, such as linux and amd64
+	OSVariable   string = ReservedPrefix + "OS"
+	ArchVariable string = ReservedPrefix + "Arch"
+)
+
+var (
+	identityOnce sync.Once
+	identity     map[string]string
+)
+
+// ContextVariables describe the environment in which a project is created.
+// They are available to every template without prompting; a variable that
+// cannot be determined, such as the git identity of an end-user without a
+// git configuration, is empty.
+func ContextVariables() map[string]string {
+	identityOnce.Do(func() {
+		identity = readIdentity()
+	})
+	now := time.Now()
+	context := map[string]string{
+		YearVariable: strconv.Itoa(now.Year()),
+		DateVariable: now.Format(CanonicalDateLayout),
+		OSVariable:   runtime.GOOS,
+		ArchVariable: runtime.GOARCH,
+	}
+	for name, value := range identity {
+		context[name] = value
+	}
+	return context
+}
+
+// IsContextVariable reports whether name is one of the ContextVariables
+func IsContextVariable(name string) bool {
+	switch name {
+	case YearVariable, DateVariable, UserVariable, GitNameVariable, GitEmailVariable, OSVariable, ArchVariable:
+		return true
+	}
+	return false
+}
+
+// WithContextVariables returns a copy of vars to which the ContextVariables
+// are added, vars take precedence so that a context variable can be provided
+// as an argument
+func WithContextVariables(vars map[string]string) map[string]string {
+	withContext := ContextVariables()
+	for name, value := range vars {
+		withContext[name] = value
+	}
+	return withContext
+}
+
+// Read the user name and git identity of the end-user, which do not change
+// while scafall runs
+func readIdentity() map[string]string {
+	identity := map[string]string{
+		UserVariable:     currentUser(),
+		GitNameVariable:  "",
+		GitEmailVariable: "",
+	}
+	// the global configuration takes precedence over the system configuration
+	for _, scope := range []config.Scope{config.SystemScope, config.GlobalScope} {
+		cfg, err := config.LoadConfig(scope)
+		if err != nil {
+			continue
+		}
+		if cfg.User.Name != "" {
+			identity[GitNameVariable] = cfg.User.Name
+		}
+		if cfg.User.Email != "" {
+			identity[GitEmailVariable] = cfg.User.Email
+		}
+	}
+	return identity
+}
+
+// The login name of the end-user, without the domain of a Windows account
+func currentUser() string {
+	name := ""
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if name == "" {
+		name = os.Getenv("USER")
+	}
+	if name == "" {
+		name = os.Getenv("USERNAME")
+	}
+	if i := strings.LastIndex(name, `\`); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
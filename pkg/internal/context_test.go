@@ -0,0 +1,71 @@
+package internal_test
+
+import (
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testContextVariables(t *testing.T, when spec.G, it spec.S) {
+	when("a template uses context variables", func() {
+		it("renders them without prompting", func() {
+			rendered, err := internal.RenderString("{{.__Year}} {{.__OS}}/{{.__Arch}}", map[string]string{})
+			h.AssertNil(t, err)
+			h.AssertEq(t, rendered, strconv.Itoa(time.Now().Year())+" "+runtime.GOOS+"/"+runtime.GOARCH)
+		})
+
+		it("renders them as placeholders", func() {
+			rendered := internal.RenderPlaceholders("{{ __OS }}", map[string]string{})
+			h.AssertEq(t, rendered, runtime.GOOS)
+		})
+
+		it("prefers provided values", func() {
+			rendered, err := internal.RenderString("{{.__User}}", map[string]string{internal.UserVariable: "ci"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, rendered, "ci")
+		})
+	})
+
+	when("prompts use context variables", func() {
+		it("renders defaults and evaluates conditions with them", func() {
+			prompts := `[[prompt]]
+name = "Copyright"
+prompt = "Copyright line"
+default = "Copyright {{.__Year}}"
+
+[[prompt]]
+name = "Shell"
+prompt = "Which shell"
+default = "powershell"
+when = "__OS == 'windows'"
+`
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(prompts)), nil, nil)
+			h.AssertNil(t, err)
+			values, err := template.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["Copyright"], "Copyright "+strconv.Itoa(time.Now().Year()))
+			applies, err := internal.EvalCondition("__OS == 'windows'", values)
+			h.AssertNil(t, err)
+			h.AssertEq(t, applies, runtime.GOOS == "windows")
+		})
+	})
+
+	when("the context variables are listed", func() {
+		it("describes the environment", func() {
+			vars := internal.ContextVariables()
+			h.AssertEq(t, vars[internal.DateVariable], time.Now().Format(internal.CanonicalDateLayout))
+			h.AssertEq(t, vars[internal.OSVariable], runtime.GOOS)
+			for name := range vars {
+				h.AssertTrue(t, internal.IsContextVariable(name))
+			}
+		})
+	})
+}
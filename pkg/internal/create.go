@@ -3,75 +3,155 @@ package internal
 import (
 	"fmt"
 	"os"
-	"path"
 	"path/filepath"
 
-	git "github.com/go-git/go-git/v5"
-	cp "github.com/otiai10/copy"
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/pkg/errors"
 )
 
-// Present a local directory or a git repo as a Filesystem
-func URLToFs(url string, subPath string, tmpDir string) (string, error) {
-	// if the URL is a local folder, then do not git clone it
-	if _, err := os.Stat(url); err == nil {
-		cp.Copy(url, tmpDir)
-	} else {
-		_, err := git.PlainClone(tmpDir, false, &git.CloneOptions{
-			URL:   url,
-			Depth: 1,
-		})
-		if err != nil {
-			return "", err
-		}
+// Create a new source project in targetDir and return the values of all
+// template variables
+func Create(inputDir string, arguments map[string]string, targetDir string) (map[string]string, error) {
+	template, err := ReadTemplate(inputDir, arguments)
+	if err != nil {
+		return nil, err
 	}
 
-	requestedSubPath := path.Join(tmpDir, subPath)
-	if _, err := os.Stat(requestedSubPath); err != nil {
-		return "", fmt.Errorf("reequested subPath of template does not exist: %s", subPath)
+	values, err := template.Ask()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prompt for values")
 	}
-	return requestedSubPath, nil
+	err = Apply(inputDir, values, targetDir, template.Settings())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scaffold new project")
+	}
+
+	return values, nil
 }
 
-// Create a new source project in targetDir
-func Create(inputDir string, arguments map[string]string, targetDir string) error {
+// Read the prompts and overrides of the project template in inputDir
+func ReadTemplate(inputDir string, arguments map[string]string) (Template, error) {
 	promptFile := filepath.Join(inputDir, PromptFile)
-	var template Template
 
 	overridesFile := filepath.Join(inputDir, OverrideFile)
 	overrides := map[string]string{}
 	if _, err := os.Stat(overridesFile); err == nil {
 		overrides, err = ReadOverrides(overridesFile)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	if _, ok := os.Stat(promptFile); ok == nil {
 		p, err := os.Open(promptFile)
 		if err != nil {
-			return err
-		}
-		template, err = NewTemplate(p, arguments, overrides)
-		if err != nil {
-			return err
-		}
-	} else {
-		var err error
-		template, err = NewTemplate(nil, arguments, overrides)
-		if err != nil {
-			return err
+			return nil, err
 		}
+		defer p.Close()
+		return NewTemplate(p, arguments, overrides)
 	}
+	return NewTemplate(nil, arguments, overrides)
+}
 
-	values, err := template.Ask()
+// Prompt the end-user for the value of each template variable that is not
+// provided as an argument or answer.  References to providers are resolved
+// and answers are checked, including by validators, before any prompt is
+// asked.  Facts about an
+// existing project are suggested as defaults.  Prompts are asked in language
+// when the template translates them.  Options, such as survey.WithStdio, are
+// passed to every prompt.
+func AskValues(inputDir string, arguments map[string]string, answers map[string]interface{}, providers ValueProviders, validators Validators, matching ChoiceMatching, functions Functions, facts map[string]string, language string, opts ...survey.AskOpt) (map[string]string, error) {
+	template, err := readAnswered(inputDir, arguments, answers, providers, validators, matching, functions)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := template.Suggest(facts).Localize(language).Ask(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prompt for values")
+	}
+	return values, nil
+}
+
+// Answer each template variable that is not provided as an argument or
+// answer with its default value, without prompting the end-user.  Facts about
+// an existing project take precedence over the defaults of the template.
+func DefaultValues(inputDir string, arguments map[string]string, answers map[string]interface{}, providers ValueProviders, validators Validators, matching ChoiceMatching, functions Functions, facts map[string]string) (map[string]string, error) {
+	template, err := readAnswered(inputDir, arguments, answers, providers, validators, matching, functions)
 	if err != nil {
-		return errors.Wrap(err, "failed to prompt for values")
+		return nil, err
 	}
-	err = Apply(inputDir, values, targetDir)
+	return template.Suggest(facts).Defaults()
+}
+
+// Read the template in inputDir, resolve references to providers and check
+// the answers provided before prompting
+func readAnswered(inputDir string, arguments map[string]string, answers map[string]interface{}, providers ValueProviders, validators Validators, matching ChoiceMatching, functions Functions) (Template, error) {
+	template, err := ReadTemplate(inputDir, arguments)
+	if err != nil {
+		return nil, err
+	}
+	template, err = template.Resolve(providers)
+	if err != nil {
+		return nil, err
+	}
+	template = template.Validate(validators).MatchChoices(matching).AddFunctions(functions)
+	answers, err = providers.ResolveAnswers(answers)
 	if err != nil {
-		return errors.Wrap(err, "failed to scaffold new project")
+		return nil, err
 	}
+	if len(answers) == 0 {
+		return template, nil
+	}
+	return template.Answer(answers)
+}
 
+// CheckAnswers checks answers against the template in inputDir without
+// prompting or rendering the template.  Every answer that is not a valid
+// answer to its prompt, or names no prompt, and every required prompt that is
+// neither answered nor has a default, is listed in an AnswerError.
+func CheckAnswers(inputDir string, arguments map[string]string, answers map[string]interface{}, providers ValueProviders, validators Validators, matching ChoiceMatching, functions Functions) error {
+	template, err := ReadTemplate(inputDir, arguments)
+	if err != nil {
+		return err
+	}
+	template, err = template.Resolve(providers)
+	if err != nil {
+		return err
+	}
+	template = template.Validate(validators).MatchChoices(matching).AddFunctions(functions)
+	answers, err = providers.ResolveAnswers(answers)
+	if err != nil {
+		return err
+	}
+
+	problems := []string{}
+	answered, err := template.Answer(answers)
+	var answerErr AnswerError
+	switch {
+	case errors.As(err, &answerErr):
+		// completeness is still checked so that every problem is reported
+		problems = append(problems, answerErr.Problems...)
+		answered = template
+	case err != nil:
+		return err
+	}
+
+	_, err = answered.Defaults()
+	var missingErr MissingValuesError
+	switch {
+	case errors.As(err, &missingErr):
+		for _, name := range missingErr.Names {
+			if _, ok := answers[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: a value is required", name))
+			}
+		}
+	case err != nil && len(problems) == 0:
+		return err
+	}
+
+	if len(problems) != 0 {
+		return AnswerError{Problems: problems}
+	}
 	return nil
 }
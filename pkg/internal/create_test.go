@@ -33,8 +33,9 @@ func testCreate(t *testing.T, when spec.G, it spec.S) {
 		})
 
 		it("creates valid output", func() {
-			err := internal.Create(inputDir, map[string]string{"Test": "quack"}, targetDir)
+			values, err := internal.Create(inputDir, map[string]string{"Test": "quack"}, targetDir)
 			h.AssertNil(t, err)
+			h.AssertEq(t, values, map[string]string{"Test": "quack"})
 
 			buf, err := os.ReadFile(filepath.Join(targetDir, "test.md"))
 			h.AssertNil(t, err)
@@ -48,7 +49,7 @@ func testCreate(t *testing.T, when spec.G, it spec.S) {
 			})
 
 			it("reads prompt.toml and creates valid output", func() {
-				err := internal.Create(inputDir, map[string]string{"Test": "quack"}, targetDir)
+				_, err := internal.Create(inputDir, map[string]string{"Test": "quack"}, targetDir)
 				h.AssertNil(t, err)
 
 				buf, err := os.ReadFile(filepath.Join(targetDir, "test.md"))
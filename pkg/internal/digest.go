@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const DigestAlgorithm string = "sha256"
+
+// Digest computes a digest over the relative path and content of every file
+// in dir.  The digest is formatted as "sha256:<hex>".
+func Digest(dir string) (string, error) {
+	hash := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if IsIgnoredDirectory(info.Name()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Type().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hash.Write([]byte(filepath.ToSlash(relPath)))
+		hash.Write([]byte{0})
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(hash, f)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return DigestAlgorithm + ":" + hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,63 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testDigest(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+		os.MkdirAll(filepath.Join(tmpDir, "{{.Foo}}"), 0700)
+		os.WriteFile(filepath.Join(tmpDir, "{{.Foo}}", "foo.txt"), []byte("{{.Foo}}"), 0600)
+		os.WriteFile(filepath.Join(tmpDir, internal.PromptFile), []byte{}, 0600)
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("Computing a digest of a template", func() {
+		it("produces a stable digest", func() {
+			first, err := internal.Digest(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertTrue(t, strings.HasPrefix(first, "sha256:"))
+
+			second, err := internal.Digest(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, first, second)
+		})
+
+		it("ignores the .git folder", func() {
+			before, err := internal.Digest(tmpDir)
+			h.AssertNil(t, err)
+			os.MkdirAll(filepath.Join(tmpDir, ".git"), 0700)
+			os.WriteFile(filepath.Join(tmpDir, ".git", "HEAD"), []byte("ref: refs/heads/main"), 0600)
+
+			after, err := internal.Digest(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, before, after)
+		})
+
+		it("changes when file content changes", func() {
+			before, err := internal.Digest(tmpDir)
+			h.AssertNil(t, err)
+			os.WriteFile(filepath.Join(tmpDir, "{{.Foo}}", "foo.txt"), []byte("{{.Bar}}"), 0600)
+
+			after, err := internal.Digest(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertNotEq(t, before, after)
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// GoTemplateEngine renders templates with gotemplate, which includes
+	// template logic and the sprig functions
+	GoTemplateEngine string = "gotemplate"
+	// PlaceholderEngine only replaces variables written as {{ name }} or
+	// {{ .name }}, everything else is copied as is
+	PlaceholderEngine string = "placeholder"
+)
+
+var Engines = []string{GoTemplateEngine, PlaceholderEngine}
+
+var (
+	placeholderPattern = regexp.MustCompile(`{{\s*\.?([A-Za-z_][A-Za-z0-9_]*)\s*}}`)
+	// name forms are written as {{ name_forms.env }} or {{ .name_forms.env }}
+	nameFormPattern = regexp.MustCompile(`{{\s*\.?` + NameFormsVariable + `\.([a-z]+)\s*}}`)
+)
+
+// CheckEngine reports an unknown engine, an empty engine is GoTemplateEngine
+func CheckEngine(engine string) error {
+	switch engine {
+	case "", GoTemplateEngine, PlaceholderEngine:
+		return nil
+	}
+	return fmt.Errorf("engine %s is unknown; expected one of %s", engine, strings.Join(Engines, ", "))
+}
+
+// Render renders content using vars with the named engine.  Unknown variables
+// are left in place by every engine.
+func Render(engine string, content string, vars map[string]string) (string, error) {
+	return render(Settings{Engine: engine}, content, vars)
+}
+
+// Render content using vars with the engine of the settings, the functions of
+// the settings can be called by GoTemplateEngine
+func render(settings Settings, content string, vars map[string]string) (string, error) {
+	if settings.Engine == PlaceholderEngine {
+		return RenderPlaceholders(content, vars), nil
+	}
+	return RenderStringWith(content, vars, settings.Functions)
+}
+
+// RenderPlaceholders replaces each {{ name }} or {{ .name }} in content with
+// the value of the variable, of its case variant such as name_snake, or of a
+// context variable such as __Year, and each {{ name_forms.form }} with the
+// form of the project name.  There is no template logic, so templates using
+// only placeholders are safe to render even when they are not trusted.
+func RenderPlaceholders(content string, vars map[string]string) string {
+	if base, ok := vars[NameFormsBase]; ok {
+		forms := NameForms(base)
+		content = nameFormPattern.ReplaceAllStringFunc(content, func(placeholder string) string {
+			if value, ok := forms[nameFormPattern.FindStringSubmatch(placeholder)[1]]; ok {
+				return value
+			}
+			return placeholder
+		})
+	}
+	variants := CaseVariants(vars)
+	context := ContextVariables()
+	return placeholderPattern.ReplaceAllStringFunc(content, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		if value, ok := variants[name]; ok {
+			return value
+		}
+		if value, ok := context[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
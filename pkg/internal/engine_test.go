@@ -0,0 +1,57 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testEngine(t *testing.T, when spec.G, it spec.S) {
+	when("placeholders are rendered", func() {
+		it("replaces known variables only", func() {
+			vars := map[string]string{"Name": "demo", "version": "1.0"}
+			rendered := internal.RenderPlaceholders("{{ Name }} {{.Name}} {{version}} {{ Unknown }}", vars)
+			h.AssertEq(t, rendered, "demo demo 1.0 {{ Unknown }}")
+		})
+
+		it("copies template logic as is", func() {
+			content := `{{ if .Name }}{{ env "HOME" }}{{ end }}{{ range until 10 }}{{ end }}`
+			rendered := internal.RenderPlaceholders(content, map[string]string{"Name": "demo"})
+			h.AssertEq(t, rendered, content)
+		})
+	})
+
+	when("an engine is named", func() {
+		it("accepts known engines", func() {
+			h.AssertNil(t, internal.CheckEngine(""))
+			h.AssertNil(t, internal.CheckEngine(internal.GoTemplateEngine))
+			h.AssertNil(t, internal.CheckEngine(internal.PlaceholderEngine))
+		})
+
+		it("refuses unknown engines", func() {
+			h.AssertNotNil(t, internal.CheckEngine("jinja"))
+		})
+	})
+
+	when("a template uses the placeholder engine", func() {
+		it("renders file paths and content without template logic", func() {
+			tmpDir, _ := os.MkdirTemp("", "test")
+			defer os.RemoveAll(tmpDir)
+			outputDir, _ := os.MkdirTemp("", "test")
+			defer os.RemoveAll(outputDir)
+			os.WriteFile(filepath.Join(tmpDir, "{{ Name }}.txt"), []byte(`{{ Name }} {{ upper .Name }}`), 0600)
+
+			settings := internal.Settings{Engine: internal.PlaceholderEngine}
+			err := internal.Apply(tmpDir, map[string]string{"Name": "demo"}, outputDir, settings)
+			h.AssertNil(t, err)
+			content, err := os.ReadFile(filepath.Join(outputDir, "demo.txt"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(content), "demo {{ upper .Name }}")
+		})
+	})
+}
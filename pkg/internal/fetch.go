@@ -0,0 +1,317 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	neturl "net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// FetchOptions control how a project template is fetched
+type FetchOptions struct {
+	// SubPath is a folder within the template repository to use as the template
+	SubPath string
+	// Ref is a git branch, tag or commit to check out
+	Ref string
+	// Username and Password authenticate HTTPS clones
+	Username string
+	Password string
+	// CacheDir stores fetched templates, no templates are cached when empty
+	CacheDir string
+	// Offline uses cached templates rather than fetching them
+	Offline bool
+	// FS provides the template in place of the url, it is never cached
+	FS fs.FS
+	// Reader provides the template as a tar stream in place of the url, it is
+	// never cached
+	Reader io.Reader
+	// Submodules clones the git submodules of the template repository
+	Submodules bool
+	// Proxy is the url of an HTTP proxy, when empty the proxy is read from
+	// the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables
+	Proxy string
+	// CABundle is a file of PEM encoded certificates to trust in addition to
+	// the system certificates, such as for a self-signed git server
+	CABundle string
+	// HostKeyChecking is one of HostKeyChecking, the keys of SSH servers are
+	// checked strictly against known_hosts when it is empty
+	HostKeyChecking string
+	// Progress is called as a template is fetched, when it is not nil
+	Progress func(FetchEvent)
+	// Warn is called for each part of the template that is skipped as it is
+	// fetched, such as a link in an archive, when it is not nil
+	Warn func(Warning)
+}
+
+// Access tokens that are read from the environment for HTTPS clones of
+// repositories on well known hosts.  The token is only sent to the host
+// itself or to a host listed in the comma separated hosts variable, such as
+// GitHub Enterprise or a self-hosted GitLab.
+var tokenVariables = []struct {
+	host          string
+	hostsVariable string
+	variable      string
+}{
+	{"github.com", "GITHUB_HOSTS", "GITHUB_TOKEN"},
+	{"gitlab.com", "GITLAB_HOSTS", "GITLAB_TOKEN"},
+}
+
+const defaultTokenUsername string = "oauth2"
+
+// Split a "url#ref" into the url and the git ref
+func SplitRef(url string) (string, string) {
+	if _, ok := LocalPath(url); ok {
+		return url, ""
+	}
+	if i := strings.LastIndex(url, "#"); i >= 0 {
+		return url[:i], url[i+1:]
+	}
+	return url, ""
+}
+
+// Split a "url//sub/path" into the url and the path of a template within the
+// repository, as used for templates in a monorepo
+func SplitSubPath(url string) (string, string) {
+	if _, ok := LocalPath(url); ok {
+		return url, ""
+	}
+	start := 0
+	if i := strings.Index(url, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	if i := strings.Index(url[start:], "//"); i >= 0 {
+		return url[:start+i], strings.Trim(url[start+i+len("//"):], "/")
+	}
+	return url, ""
+}
+
+// Present a local directory, an archive, an OCI artifact or a git repo as a
+// Filesystem
+func URLToFs(url string, tmpDir string, opts FetchOptions) (string, error) {
+	var err error
+	if opts.FS != nil {
+		err = CopyFS(opts.FS, tmpDir)
+	} else if opts.Reader != nil {
+		err = extractStream(opts.Reader, tmpDir, opts)
+	} else if local, ok := LocalPath(url); ok && !IsArchive(url) && !isFileRepository(url, local) {
+		// if the URL is a local folder then do not git clone it.  A bare
+		// repository, or a requested ref, is checked out instead of copied.
+		if opts.Ref != "" || IsBareRepository(local) {
+			err = checkoutLocal(local, tmpDir, opts)
+		} else {
+			err = copyLocal(local, tmpDir, opts)
+		}
+	} else if ok && !IsArchive(url) {
+		// a file:// url naming a repository is cloned, so that only committed
+		// files are used
+		err = clone(url, tmpDir, opts)
+	} else if strings.HasPrefix(url, "file://") && !IsArchive(url) {
+		return "", fmt.Errorf("%s does not exist; a file:// url must name a local folder or repository", url)
+	} else if opts.Offline {
+		if opts.CacheDir == "" {
+			return "", fmt.Errorf("cannot fetch %s in offline mode without a cache", url)
+		}
+		err = loadFromCache(url, filepath.Join(opts.CacheDir, CacheKey(url, opts.Ref, opts.SubPath)), tmpDir)
+	} else {
+		err = fetchCached(url, tmpDir, opts)
+		if err == nil && opts.Progress != nil {
+			opts.Progress(FetchEvent{URL: url, Percent: 100, Done: true})
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	root := tmpDir
+	if opts.Reader != nil || (opts.FS == nil && IsArchive(url)) {
+		root, err = archiveRoot(tmpDir)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	requestedSubPath := path.Join(root, opts.SubPath)
+	if _, err := os.Stat(requestedSubPath); err != nil {
+		return "", fmt.Errorf("requested sub path %s does not exist in the template", opts.SubPath)
+	}
+	return requestedSubPath, nil
+}
+
+// FindHTTPAuth finds credentials for an HTTPS clone.  Credentials are either
+// provided explicitly or are an access token for the host read from the
+// environment.  No credentials are found for other schemes.
+func FindHTTPAuth(url string, opts FetchOptions) transport.AuthMethod {
+	// credentials are never sent in the clear
+	u, err := neturl.Parse(url)
+	if err != nil || u.Scheme != "https" {
+		return nil
+	}
+
+	username := opts.Username
+	if username == "" {
+		username = defaultTokenUsername
+	}
+	if opts.Password != "" {
+		return &githttp.BasicAuth{Username: username, Password: opts.Password}
+	}
+	if variable := TokenVariable(url); variable != "" {
+		if token := os.Getenv(variable); token != "" {
+			return &githttp.BasicAuth{Username: username, Password: token}
+		}
+	}
+	return nil
+}
+
+// Find the credentials for a clone of url.  An SSH clone is authenticated by
+// the SSH agent and the key of the server is checked against known_hosts,
+// other clones use FindHTTPAuth.
+func findAuth(url string, opts FetchOptions) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil || endpoint.Protocol != "ssh" {
+		return FindHTTPAuth(url, opts), nil
+	}
+	return newSSHAuth(endpoint.User, opts.HostKeyChecking)
+}
+
+// Find the commit of the ref of opts, or of HEAD, in the repository at url
+// without cloning it.  A commit given as the ref is its own revision.
+func remoteRevision(url string, opts FetchOptions) (string, error) {
+	if plumbing.IsHash(opts.Ref) {
+		return opts.Ref, nil
+	}
+	auth, err := findAuth(url, opts)
+	if err != nil {
+		return "", err
+	}
+	release, err := acquireGitTransport(opts)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{url}})
+	refs, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", err
+	}
+	byName := map[plumbing.ReferenceName]*plumbing.Reference{}
+	for _, ref := range refs {
+		byName[ref.Name()] = ref
+	}
+	names := []plumbing.ReferenceName{plumbing.HEAD}
+	if opts.Ref != "" {
+		names = []plumbing.ReferenceName{plumbing.NewBranchReferenceName(opts.Ref), plumbing.NewTagReferenceName(opts.Ref)}
+	}
+	for _, name := range names {
+		ref, ok := byName[name]
+		if ok && ref.Type() == plumbing.SymbolicReference {
+			ref, ok = byName[ref.Target()]
+		}
+		if ok {
+			return ref.Hash().String(), nil
+		}
+	}
+	return "", fmt.Errorf("cannot find the revision of %s", url)
+}
+
+func clone(url string, tmpDir string, opts FetchOptions) error {
+	auth, err := findAuth(url, opts)
+	if err != nil {
+		return err
+	}
+	return hostKeyError(auth, cloneWith(url, tmpDir, auth, opts))
+}
+
+func cloneWith(url string, tmpDir string, auth transport.AuthMethod, opts FetchOptions) error {
+	// avoid downloading a whole monorepo to use a single template
+	if opts.SubPath != "" {
+		if err := sparseClone(url, tmpDir, opts); err == nil {
+			return nil
+		}
+		if err := os.RemoveAll(tmpDir); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(tmpDir, 0700); err != nil {
+			return err
+		}
+	}
+
+	release, err := acquireGitTransport(opts)
+	if err != nil {
+		return err
+	}
+	defer release()
+	ref := opts.Ref
+	progress := newProgressWriter(url, opts)
+	submodules := git.NoRecurseSubmodules
+	if opts.Submodules {
+		submodules = git.DefaultSubmoduleRecursionDepth
+	}
+	if ref == "" {
+		_, err := git.PlainClone(tmpDir, false, &git.CloneOptions{
+			URL:               url,
+			Auth:              auth,
+			Depth:             1,
+			RecurseSubmodules: submodules,
+			Progress:          progress,
+		})
+		return err
+	}
+
+	// A branch or tag can be fetched without fetching the full history
+	for _, refName := range []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)} {
+		_, err := git.PlainClone(tmpDir, false, &git.CloneOptions{
+			URL:               url,
+			Auth:              auth,
+			ReferenceName:     refName,
+			SingleBranch:      true,
+			Depth:             1,
+			RecurseSubmodules: submodules,
+			Progress:          progress,
+		})
+		if err == nil {
+			return nil
+		}
+	}
+
+	// otherwise the ref is a commit, so clone the full history to find it
+	repo, err := git.PlainClone(tmpDir, false, &git.CloneOptions{
+		URL:      url,
+		Auth:     auth,
+		Progress: progress,
+	})
+	if err != nil {
+		return err
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return fmt.Errorf("requested git ref does not exist: %s", ref)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	err = worktree.Checkout(&git.CheckoutOptions{Hash: *hash})
+	if err != nil || !opts.Submodules {
+		return err
+	}
+	modules, err := worktree.Submodules()
+	if err != nil {
+		return err
+	}
+	return modules.Update(&git.SubmoduleUpdateOptions{
+		Init:              true,
+		RecurseSubmodules: submodules,
+		Auth:              auth,
+	})
+}
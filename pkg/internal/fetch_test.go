@@ -0,0 +1,108 @@
+package internal_test
+
+import (
+	"os"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testSplitRef(t *testing.T, when spec.G, it spec.S) {
+	type TestCase struct {
+		url         string
+		expectedURL string
+		expectedRef string
+	}
+	testCases := []TestCase{
+		{"https://github.com/org/repo", "https://github.com/org/repo", ""},
+		{"https://github.com/org/repo#v1.0.0", "https://github.com/org/repo", "v1.0.0"},
+		{"git@github.com:org/repo.git#feature/foo", "git@github.com:org/repo.git", "feature/foo"},
+	}
+	for _, testCase := range testCases {
+		current := testCase
+		when("a url is split", func() {
+			it("separates the git ref from the url", func() {
+				url, ref := internal.SplitRef(current.url)
+				h.AssertEq(t, url, current.expectedURL)
+				h.AssertEq(t, ref, current.expectedRef)
+			})
+		})
+	}
+}
+
+func testSplitSubPath(t *testing.T, when spec.G, it spec.S) {
+	type TestCase struct {
+		url             string
+		expectedURL     string
+		expectedSubPath string
+	}
+	testCases := []TestCase{
+		{"https://github.com/org/templates", "https://github.com/org/templates", ""},
+		{"https://github.com/org/templates//go/cli", "https://github.com/org/templates", "go/cli"},
+		{"gh:org/templates//go/cli/", "gh:org/templates", "go/cli"},
+		{"git@github.com:org/templates.git//go", "git@github.com:org/templates.git", "go"},
+	}
+	for _, testCase := range testCases {
+		current := testCase
+		when("a url is split", func() {
+			it("separates the sub path from the url", func() {
+				url, subPath := internal.SplitSubPath(current.url)
+				h.AssertEq(t, url, current.expectedURL)
+				h.AssertEq(t, subPath, current.expectedSubPath)
+			})
+		})
+	}
+}
+
+func testFindHTTPAuth(t *testing.T, when spec.G, it spec.S) {
+	var (
+		githubToken string
+	)
+
+	it.Before(func() {
+		githubToken = os.Getenv("GITHUB_TOKEN")
+		os.Setenv("GITHUB_TOKEN", "ghp_secret")
+	})
+
+	it.After(func() {
+		os.Setenv("GITHUB_TOKEN", githubToken)
+	})
+
+	when("credentials are provided", func() {
+		it("uses the provided credentials", func() {
+			auth := internal.FindHTTPAuth("https://github.com/org/repo", internal.FetchOptions{Username: "duck", Password: "quack"})
+			h.AssertEq(t, auth, &githttp.BasicAuth{Username: "duck", Password: "quack"})
+		})
+
+		it("does not send the credentials over http", func() {
+			auth := internal.FindHTTPAuth("http://example.com/org/repo", internal.FetchOptions{Username: "duck", Password: "quack"})
+			h.AssertNil(t, auth)
+		})
+	})
+
+	when("a token is in the environment", func() {
+		it("uses the token for https clones from the host", func() {
+			auth := internal.FindHTTPAuth("https://github.com/org/repo", internal.FetchOptions{})
+			h.AssertEq(t, auth, &githttp.BasicAuth{Username: "oauth2", Password: "ghp_secret"})
+		})
+
+		it("does not send the token to other hosts", func() {
+			auth := internal.FindHTTPAuth("https://example.com/org/repo", internal.FetchOptions{})
+			h.AssertNil(t, auth)
+		})
+
+		it("does not send the token to a host that looks like the host", func() {
+			auth := internal.FindHTTPAuth("https://github.evil.example/org/repo", internal.FetchOptions{})
+			h.AssertNil(t, auth)
+		})
+
+		it("does not send the token over http", func() {
+			auth := internal.FindHTTPAuth("http://github.com/org/repo", internal.FetchOptions{})
+			h.AssertNil(t, auth)
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package internal
+
+import "fmt"
+
+// Fetcher fetches remote templates of one kind, such as git repositories or
+// OCI artifacts
+type Fetcher interface {
+	// Handles reports whether the Fetcher fetches url
+	Handles(url string) bool
+	// Fetch fetches the template at url into tmpDir
+	Fetch(url string, tmpDir string, opts FetchOptions) error
+}
+
+// Fetchers are tried in order and the first that handles a url fetches it.
+// Git handles every url, so it is tried last.
+var fetchers = []Fetcher{ociFetcher{}, mercurialFetcher{}, archiveFetcher{}, gitFetcher{}}
+
+// A RevisionFetcher finds the revision of a template without fetching it, so
+// that a cached copy of the same revision is used rather than fetched again
+type RevisionFetcher interface {
+	Fetcher
+	// Revision identifies the content of the template at url, such as the
+	// commit of a git ref
+	Revision(url string, opts FetchOptions) (string, error)
+}
+
+// Fetch a remote template into tmpDir
+func fetch(url string, tmpDir string, opts FetchOptions) error {
+	if f := fetcherFor(url); f != nil {
+		return f.Fetch(url, tmpDir, opts)
+	}
+	return fmt.Errorf("cannot fetch %s", url)
+}
+
+// The first Fetcher that handles url
+func fetcherFor(url string) Fetcher {
+	for _, f := range fetchers {
+		if f.Handles(url) {
+			return f
+		}
+	}
+	return nil
+}
+
+type ociFetcher struct{}
+
+func (ociFetcher) Handles(url string) bool {
+	return IsOCI(url)
+}
+
+func (ociFetcher) Fetch(url string, tmpDir string, opts FetchOptions) error {
+	return fetchOCI(url, tmpDir, opts)
+}
+
+type archiveFetcher struct{}
+
+func (archiveFetcher) Handles(url string) bool {
+	return IsArchive(url)
+}
+
+func (archiveFetcher) Fetch(url string, tmpDir string, opts FetchOptions) error {
+	return fetchArchive(url, tmpDir, opts)
+}
+
+type gitFetcher struct{}
+
+func (gitFetcher) Handles(url string) bool {
+	return true
+}
+
+func (gitFetcher) Fetch(url string, tmpDir string, opts FetchOptions) error {
+	return authError(url, opts, clone(url, tmpDir, opts))
+}
+
+func (gitFetcher) Revision(url string, opts FetchOptions) (string, error) {
+	return remoteRevision(url, opts)
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// CopyFS copies the directories and regular files of fsys into dest.  Files
+// are always writable by the owner, as files in an embed.FS are read-only.
+func CopyFS(fsys fs.FS, dest string) error {
+	return fs.WalkDir(fsys, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, filepath.FromSlash(path))
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return copyFSFile(fsys, path, target, info.Mode().Perm()|0600)
+	})
+}
+
+func copyFSFile(fsys fs.FS, path string, target string, mode fs.FileMode) error {
+	in, err := fsys.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,47 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testCopyFS(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a template is provided as a filesystem", func() {
+		it("copies the template and uses a sub path", func() {
+			fsys := fstest.MapFS{
+				"templates/go/prompts.toml":    {Data: []byte(""), Mode: 0444},
+				"templates/go/{{.Foo}}/foo.go": {Data: []byte("{{.Foo}}"), Mode: 0444},
+			}
+
+			root, err := internal.URLToFs("", tmpDir, internal.FetchOptions{FS: fsys, SubPath: "templates/go"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, root, filepath.Join(tmpDir, "templates", "go"))
+			content, err := internal.ReadFile(filepath.Join(root, "{{.Foo}}", "foo.go"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, content, "{{.Foo}}")
+
+			info, err := os.Stat(filepath.Join(root, "{{.Foo}}", "foo.go"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, info.Mode().Perm(), os.FileMode(0644))
+		})
+	})
+}
@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/buildpacks/scafall/pkg/internal/util"
+)
+
+// FunctionPolicy restricts the functions that templates may call, such as to
+// refuse env or getHostByName.  When allow is given, only the allowed
+// functions may be called.
+type FunctionPolicy struct {
+	Allow []string `toml:"allow"`
+	Deny  []string `toml:"deny"`
+}
+
+// FunctionError reports the functions called by a template file that are
+// refused by the FunctionPolicy
+type FunctionError struct {
+	FilePath  string
+	Functions []string
+}
+
+func (e FunctionError) Error() string {
+	return message(CodeRefusedFunction, e.FilePath, strings.Join(e.Functions, ", "))
+}
+
+func (e FunctionError) Code() Code {
+	return CodeRefusedFunction
+}
+
+// FunctionErrors reports every template file that calls refused functions
+type FunctionErrors []FunctionError
+
+func (e FunctionErrors) Error() string {
+	errs := make([]string, len(e))
+	for i, err := range e {
+		errs[i] = err.Error()
+	}
+	return strings.Join(errs, "\n")
+}
+
+func (e FunctionErrors) Code() Code {
+	return CodeRefusedFunction
+}
+
+var (
+	// keywords and builtin functions of text/template are always allowed
+	templateBuiltins = []string{
+		"if", "else", "end", "range", "with", "define", "template", "block", "break", "continue", "nil", "true", "false",
+		"and", "or", "not", "len", "index", "slice", "print", "printf", "println", "html", "js", "urlquery", "call",
+		"eq", "ne", "lt", "le", "gt", "ge",
+	}
+
+	actionPattern  = regexp.MustCompile(`(?s){{(.*?)}}`)
+	literalPattern = regexp.MustCompile("(?s)\"(\\\\.|[^\"\\\\])*\"|`[^`]*`|'(\\\\.|[^'\\\\])*'|/\\*.*?\\*/")
+	callPattern    = regexp.MustCompile(`(^|[^.$\w])([A-Za-z_]\w*)`)
+)
+
+// IsEmpty reports whether the policy allows every function
+func (p FunctionPolicy) IsEmpty() bool {
+	return len(p.Allow) == 0 && len(p.Deny) == 0
+}
+
+// Find the functions called in content that are refused by the policy
+func (p FunctionPolicy) refused(content string) []string {
+	refused := []string{}
+	for _, action := range actionPattern.FindAllStringSubmatch(content, -1) {
+		code := literalPattern.ReplaceAllString(action[1], " ")
+		for _, match := range callPattern.FindAllStringSubmatch(code, -1) {
+			function := match[2]
+			if util.Contains(templateBuiltins, function) || util.Contains(refused, function) {
+				continue
+			}
+			if util.Contains(p.Deny, function) || (len(p.Allow) != 0 && !util.Contains(p.Allow, function)) {
+				refused = append(refused, function)
+			}
+		}
+	}
+	sort.Strings(refused)
+	return refused
+}
+
+// CheckFunctions checks that every file in dir, and every file name, calls
+// only the functions allowed by policy
+func CheckFunctions(dir string, policy FunctionPolicy) error {
+	if policy.IsEmpty() {
+		return nil
+	}
+
+	errs := FunctionErrors{}
+	err := filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if IsIgnoredDirectory(info.Name()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Type().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if refused := policy.refused(relPath + "\n" + string(content)); len(refused) != 0 {
+			errs = append(errs, FunctionError{FilePath: relPath, Functions: refused})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
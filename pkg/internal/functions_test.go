@@ -0,0 +1,67 @@
+package internal_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testFunctions(t *testing.T, when spec.G, it spec.S) {
+	var (
+		inputDir string
+	)
+
+	it.Before(func() {
+		inputDir, _ = os.MkdirTemp("", "test")
+		files := map[string]string{
+			"README.md":              "{{ .Name | upper }}",
+			"config.yaml":            `home: {{ env "HOME" }} # {{ "getHostByName" }}`,
+			"{{ expandenv .Dir }}/a": "{{ getHostByName .Host }}",
+		}
+		for name, content := range files {
+			path := filepath.Join(inputDir, name)
+			h.AssertNil(t, os.MkdirAll(filepath.Dir(path), 0755))
+			h.AssertNil(t, os.WriteFile(path, []byte(content), 0600))
+		}
+	})
+
+	it.After(func() {
+		os.RemoveAll(inputDir)
+	})
+
+	when("no function policy is given", func() {
+		it("allows every function", func() {
+			h.AssertNil(t, internal.CheckFunctions(inputDir, internal.FunctionPolicy{}))
+		})
+	})
+
+	when("functions are denied", func() {
+		it("reports the refused functions of every file", func() {
+			policy := internal.FunctionPolicy{Deny: []string{"env", "expandenv", "getHostByName"}}
+			err := internal.CheckFunctions(inputDir, policy)
+			var errs internal.FunctionErrors
+			h.AssertTrue(t, errors.As(err, &errs))
+			h.AssertEq(t, errs, internal.FunctionErrors{
+				{FilePath: "config.yaml", Functions: []string{"env"}},
+				{FilePath: filepath.Join("{{ expandenv .Dir }}", "a"), Functions: []string{"expandenv", "getHostByName"}},
+			})
+		})
+	})
+
+	when("functions are allowed", func() {
+		it("refuses every other function", func() {
+			policy := internal.FunctionPolicy{Allow: []string{"upper", "env"}}
+			err := internal.CheckFunctions(inputDir, policy)
+			var errs internal.FunctionErrors
+			h.AssertTrue(t, errors.As(err, &errs))
+			h.AssertEq(t, len(errs), 1)
+			h.AssertEq(t, errs[0].Functions, []string{"expandenv", "getHostByName"})
+		})
+	})
+}
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	t "github.com/coveooss/gotemplate/v3/template"
+)
+
+const (
+	// HelpersDir is the folder of a template whose *.tmpl files are helper
+	// templates, which every file of the template may use but which are
+	// never written to the project
+	HelpersDir string = ".scafall/helpers"
+	// HelperSuffix is the suffix of the helper templates in HelpersDir
+	HelperSuffix string = ".tmpl"
+)
+
+// Helpers holds the content of each helper template of a project template,
+// keyed by its slash separated path within HelpersDir
+type Helpers map[string]string
+
+// ReadHelpers reads the helper templates in the HelpersDir of the template in
+// inputDir, a template without helpers has none
+func ReadHelpers(inputDir string) (Helpers, error) {
+	helpers := Helpers{}
+	dir := filepath.Join(inputDir, filepath.FromSlash(HelpersDir))
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return helpers, nil
+	}
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), HelperSuffix) {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := ReadFile(path)
+		if err != nil {
+			return err
+		}
+		helpers[filepath.ToSlash(relPath)] = content
+		return nil
+	})
+	return helpers, err
+}
+
+// Whether a file of a template is in HelpersDir, so is not rendered
+func isHelper(filePath string) bool {
+	return isWithin(filepath.ToSlash(filePath), HelpersDir)
+}
+
+// Parse the helpers as templates associated with template.  The templates
+// defined by a helper are available by their names, and the whole of a helper
+// by its path within HelpersDir, such as {{template "license.tmpl" .}}.
+func (h Helpers) addTo(template *t.Template, vars map[string]interface{}) error {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		filePath := HelpersDir + "/" + name
+		helper, err := template.New(filePath).Parse(replaceUnknownVars(vars, h[name]))
+		if err != nil {
+			return FileError{FilePath: filePath, Err: err}
+		}
+		// templates named after the file they are parsed from are not shared
+		// with the files of the template, so the helper is also added by the
+		// name it is used by
+		if _, err := template.AddParseTree(name, helper.Tree); err != nil {
+			return FileError{FilePath: filePath, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package internal_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testHelpers(t *testing.T, when spec.G, it spec.S) {
+	var (
+		inputDir  string
+		outputDir string
+	)
+
+	write := func(file string, content string) {
+		path := filepath.Join(inputDir, filepath.FromSlash(file))
+		h.AssertNil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		h.AssertNil(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	it.Before(func() {
+		inputDir, _ = os.MkdirTemp("", "test")
+		outputDir, _ = os.MkdirTemp("", "test")
+		write(".scafall/helpers/license.tmpl", `Copyright {{.Owner}}{{define "package"}}package {{.Name}}{{end}}`)
+		write("main.go", "// {{template \"license.tmpl\" .}}\n{{template \"package\" .}}\n")
+	})
+
+	it.After(func() {
+		os.RemoveAll(inputDir)
+		os.RemoveAll(outputDir)
+	})
+
+	when("a template has helpers", func() {
+		it("renders files that use them", func() {
+			err := internal.Apply(inputDir, map[string]string{"Owner": "ACME", "Name": "shop"}, outputDir, internal.Settings{})
+			h.AssertNil(t, err)
+			content, err := internal.ReadFile(filepath.Join(outputDir, "main.go"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, content, "// Copyright ACME\npackage shop\n")
+		})
+
+		it("does not write the helpers, even when .scafall is included", func() {
+			err := internal.Apply(inputDir, map[string]string{"Owner": "ACME", "Name": "shop"}, outputDir, internal.Settings{Include: []string{".scafall"}})
+			h.AssertNil(t, err)
+			_, err = os.Stat(filepath.Join(outputDir, ".scafall", "helpers", "license.tmpl"))
+			h.AssertTrue(t, os.IsNotExist(err))
+		})
+
+		it("reads only *.tmpl files", func() {
+			write(".scafall/helpers/notes.md", "not a helper")
+			helpers, err := internal.ReadHelpers(inputDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(helpers), 1)
+			_, ok := helpers["license.tmpl"]
+			h.AssertTrue(t, ok)
+		})
+	})
+
+	when("a helper cannot be parsed", func() {
+		it("is reported", func() {
+			write(".scafall/helpers/broken.tmpl", `{{define "broken"}}`)
+			err := internal.Apply(inputDir, map[string]string{"Owner": "ACME", "Name": "shop"}, outputDir, internal.Settings{})
+			var fileErr internal.FileError
+			h.AssertTrue(t, errors.As(err, &fileErr))
+			h.AssertEq(t, fileErr.FilePath, ".scafall/helpers/broken.tmpl")
+		})
+	})
+}
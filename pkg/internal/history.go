@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// MaxHistory is the number of recently used templates that are remembered
+const MaxHistory int = 20
+
+// History lists recently used template urls, most recently used first
+type History struct {
+	URLs []string `toml:"urls"`
+}
+
+// ReadHistory reads the History in historyFile, a missing file is an empty
+// History
+func ReadHistory(historyFile string) (History, error) {
+	history := History{}
+	if _, err := os.Stat(historyFile); err != nil {
+		return history, nil
+	}
+	historyData, err := ReadFile(historyFile)
+	if err != nil {
+		return history, err
+	}
+
+	if _, err := toml.Decode(historyData, &history); err != nil {
+		return history, errors.Wrap(err, fmt.Sprintf("%s file does not match required format", historyFile))
+	}
+	return history, nil
+}
+
+func WriteHistory(history History, historyFile string) error {
+	if err := os.MkdirAll(filepath.Dir(historyFile), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(historyFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return toml.NewEncoder(f).Encode(history)
+}
+
+// Add moves url to the front of the History, forgetting the least recently
+// used url when the History is full
+func (h History) Add(url string) History {
+	urls := []string{url}
+	for _, u := range h.URLs {
+		if u != url && len(urls) < MaxHistory {
+			urls = append(urls, u)
+		}
+	}
+	return History{URLs: urls}
+}
@@ -0,0 +1,52 @@
+package internal_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testHistory(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a template is used", func() {
+		it("is remembered as the most recently used template", func() {
+			historyFile := filepath.Join(tmpDir, "scafall", "history.toml")
+			history, err := internal.ReadHistory(historyFile)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(history.URLs), 0)
+
+			history = history.Add("gh:org/one").Add("gh:org/two").Add("gh:org/one")
+			h.AssertNil(t, internal.WriteHistory(history, historyFile))
+
+			history, err = internal.ReadHistory(historyFile)
+			h.AssertNil(t, err)
+			h.AssertEq(t, history.URLs, []string{"gh:org/one", "gh:org/two"})
+		})
+
+		it("forgets the least recently used template", func() {
+			history := internal.History{}
+			for i := 0; i <= internal.MaxHistory; i++ {
+				history = history.Add(fmt.Sprintf("gh:org/%d", i))
+			}
+			h.AssertEq(t, len(history.URLs), internal.MaxHistory)
+			h.AssertEq(t, history.URLs[0], fmt.Sprintf("gh:org/%d", internal.MaxHistory))
+		})
+	})
+}
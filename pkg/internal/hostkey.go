@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/buildpacks/scafall/pkg/internal/util"
+)
+
+const (
+	// HostKeyStrict only accepts SSH servers whose key is in known_hosts
+	HostKeyStrict = "strict"
+	// HostKeyAcceptNew adds the key of an SSH server that is not in
+	// known_hosts, a server whose key has changed is still rejected
+	HostKeyAcceptNew = "accept-new"
+	// HostKeyOff accepts any key, so that the server is not verified
+	HostKeyOff = "off"
+)
+
+// HostKeyChecking lists the ways in which the keys of SSH servers are checked
+var HostKeyChecking = []string{HostKeyStrict, HostKeyAcceptNew, HostKeyOff}
+
+// HostKeyError reports that the key of an SSH server is not in known_hosts,
+// or does not match the key in known_hosts
+type HostKeyError struct {
+	Host string
+	// KnownHosts is the known_hosts file the key was checked against
+	KnownHosts string
+	// Changed is true when known_hosts has another key for the host, which
+	// may mean that the connection is intercepted
+	Changed bool
+	Err     error
+}
+
+func (e HostKeyError) Error() string {
+	if e.Changed {
+		return message(CodeHostKeyChanged, e.Host, e.KnownHosts, e.Host)
+	}
+	return message(CodeHostKeyUnknown, e.Host, e.KnownHosts, e.Host, e.KnownHosts, HostKeyAcceptNew)
+}
+
+func (e HostKeyError) Code() Code {
+	if e.Changed {
+		return CodeHostKeyChanged
+	}
+	return CodeHostKeyUnknown
+}
+
+func (e HostKeyError) Unwrap() error {
+	return e.Err
+}
+
+// The known_hosts file, the first file of SSH_KNOWN_HOSTS or the known_hosts
+// of the end-user
+func knownHostsFile() string {
+	if files := filepath.SplitList(os.Getenv("SSH_KNOWN_HOSTS")); len(files) != 0 && files[0] != "" {
+		return files[0]
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".ssh", "known_hosts")
+	}
+	return filepath.Join(home, ".ssh", "known_hosts")
+}
+
+// hostKeyChecker checks the keys of SSH servers against a known_hosts file,
+// keeping the failure so that it is reported rather than the handshake error
+// that wraps it
+type hostKeyChecker struct {
+	mode       string
+	knownHosts string
+	err        error
+}
+
+func newHostKeyChecker(mode string) (*hostKeyChecker, error) {
+	if mode == "" {
+		mode = HostKeyStrict
+	}
+	if !util.Contains(HostKeyChecking, mode) {
+		return nil, fmt.Errorf("unknown host key checking %s; expected one of %s", mode, strings.Join(HostKeyChecking, ", "))
+	}
+	return &hostKeyChecker{mode: mode, knownHosts: knownHostsFile()}, nil
+}
+
+// HostKeyCallback checks the keys of SSH servers against known_hosts in the
+// way named by mode, one of HostKeyChecking
+func HostKeyCallback(mode string) (ssh.HostKeyCallback, error) {
+	checker, err := newHostKeyChecker(mode)
+	if err != nil {
+		return nil, err
+	}
+	return checker.check, nil
+}
+
+func (c *hostKeyChecker) check(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	if c.mode == HostKeyOff {
+		return nil
+	}
+	check, err := knownhosts.New(c.knownHosts)
+	if errors.Is(err, os.ErrNotExist) {
+		// no host is known
+		check = func(string, net.Addr, ssh.PublicKey) error {
+			return &knownhosts.KeyError{}
+		}
+	} else if err != nil {
+		return err
+	}
+
+	err = check(hostname, remote, key)
+	var keyErr *knownhosts.KeyError
+	if !errors.As(err, &keyErr) {
+		return err
+	}
+	if len(keyErr.Want) == 0 && c.mode == HostKeyAcceptNew {
+		return c.add(hostname, key)
+	}
+	host := hostname
+	if h, port, splitErr := net.SplitHostPort(hostname); splitErr == nil && port == "22" {
+		host = h
+	}
+	c.err = HostKeyError{Host: host, KnownHosts: c.knownHosts, Changed: len(keyErr.Want) != 0, Err: err}
+	return c.err
+}
+
+// Add the key of hostname to known_hosts
+func (c *hostKeyChecker) add(hostname string, key ssh.PublicKey) error {
+	if err := os.MkdirAll(filepath.Dir(c.knownHosts), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(c.knownHosts, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key))
+	return err
+}
+
+// sshAgentAuth authenticates an SSH clone with the keys of the SSH agent and
+// checks the key of the server with its checker
+type sshAgentAuth struct {
+	*gitssh.PublicKeysCallback
+	checker *hostKeyChecker
+}
+
+// Authenticate an SSH clone as user, checking the key of the server in the
+// way named by mode, one of HostKeyChecking
+func newSSHAuth(user string, mode string) (transport.AuthMethod, error) {
+	checker, err := newHostKeyChecker(mode)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := gitssh.NewSSHAgentAuth(user)
+	if err != nil {
+		return nil, err
+	}
+	auth.HostKeyCallback = checker.check
+	return sshAgentAuth{PublicKeysCallback: auth, checker: checker}, nil
+}
+
+// Report a failure to clone with auth that was caused by the key of the
+// server as a HostKeyError, other errors are unchanged
+func hostKeyError(auth transport.AuthMethod, err error) error {
+	if sshAuth, ok := auth.(sshAgentAuth); ok && err != nil && sshAuth.checker.err != nil {
+		return sshAuth.checker.err
+	}
+	return err
+}
+
+// The option of the ssh command line that checks host keys in the way named
+// by mode
+func sshHostKeyOption(mode string) string {
+	switch mode {
+	case HostKeyAcceptNew:
+		return "StrictHostKeyChecking=accept-new"
+	case HostKeyOff:
+		return "StrictHostKeyChecking=no"
+	}
+	return "StrictHostKeyChecking=yes"
+}
@@ -0,0 +1,93 @@
+package internal_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testHostKeyChecking(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir     string
+		knownHosts string
+		remote     = &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+	)
+
+	newKey := func() ssh.PublicKey {
+		public, _, err := ed25519.GenerateKey(rand.Reader)
+		h.AssertNil(t, err)
+		key, err := ssh.NewPublicKey(public)
+		h.AssertNil(t, err)
+		return key
+	}
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+		knownHosts = filepath.Join(tmpDir, "known_hosts")
+		t.Setenv("SSH_KNOWN_HOSTS", knownHosts)
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("checking is strict", func() {
+		it("rejects a host that is not known", func() {
+			check, err := internal.HostKeyCallback(internal.HostKeyStrict)
+			h.AssertNil(t, err)
+			err = check("git.example.com:22", remote, newKey())
+			var hostKeyErr internal.HostKeyError
+			h.AssertTrue(t, errors.As(err, &hostKeyErr))
+			h.AssertEq(t, hostKeyErr.Host, "git.example.com")
+			h.AssertEq(t, hostKeyErr.Changed, false)
+			h.AssertContains(t, err.Error(), "the host key of git.example.com is not in "+knownHosts)
+		})
+	})
+
+	when("new hosts are accepted", func() {
+		it("adds the key of a new host and rejects a changed key", func() {
+			check, err := internal.HostKeyCallback(internal.HostKeyAcceptNew)
+			h.AssertNil(t, err)
+			key := newKey()
+			h.AssertNil(t, check("git.example.com:22", remote, key))
+			h.AssertNil(t, check("git.example.com:22", remote, key))
+
+			strict, err := internal.HostKeyCallback(internal.HostKeyStrict)
+			h.AssertNil(t, err)
+			h.AssertNil(t, strict("git.example.com:22", remote, key))
+
+			err = check("git.example.com:22", remote, newKey())
+			var hostKeyErr internal.HostKeyError
+			h.AssertTrue(t, errors.As(err, &hostKeyErr))
+			h.AssertEq(t, hostKeyErr.Changed, true)
+			h.AssertContains(t, err.Error(), "does not match the key in "+knownHosts)
+		})
+	})
+
+	when("checking is off", func() {
+		it("accepts any key", func() {
+			check, err := internal.HostKeyCallback(internal.HostKeyOff)
+			h.AssertNil(t, err)
+			h.AssertNil(t, check("git.example.com:22", remote, newKey()))
+			_, err = os.Stat(knownHosts)
+			h.AssertTrue(t, os.IsNotExist(err))
+		})
+	})
+
+	when("the mode is unknown", func() {
+		it("reports the modes", func() {
+			_, err := internal.HostKeyCallback("ask")
+			h.AssertError(t, err, "unknown host key checking ask; expected one of strict, accept-new, off")
+		})
+	})
+}
@@ -9,11 +9,77 @@ import (
 
 func TestIternal(t *testing.T) {
 	spec.Run(t, "Collection", testCollection, spec.Report(report.Terminal{}))
+	spec.Run(t, "CheckTemplate", testCheckTemplate, spec.Report(report.Terminal{}))
 	spec.Run(t, "Create", testCreate, spec.Report(report.Terminal{}))
+	spec.Run(t, "SplitRef", testSplitRef, spec.Report(report.Terminal{}))
+	spec.Run(t, "SplitSubPath", testSplitSubPath, spec.Report(report.Terminal{}))
+	spec.Run(t, "ExpandURL", testExpandURL, spec.Report(report.Terminal{}))
+	spec.Run(t, "LocalPath", testLocalPath, spec.Report(report.Terminal{}))
+	spec.Run(t, "LocalRepository", testLocalRepository, spec.Report(report.Terminal{}))
+	spec.Run(t, "NormalizeURL", testNormalizeURL, spec.Report(report.Terminal{}))
+	spec.Run(t, "FindHTTPAuth", testFindHTTPAuth, spec.Report(report.Terminal{}))
+	spec.Run(t, "Archive", testArchive, spec.Report(report.Terminal{}))
+	spec.Run(t, "OCI", testOCI, spec.Report(report.Terminal{}))
+	spec.Run(t, "Cache", testCache, spec.Report(report.Terminal{}))
+	spec.Run(t, "SparseClone", testSparseClone, spec.Report(report.Terminal{}))
+	spec.Run(t, "CopyFS", testCopyFS, spec.Report(report.Terminal{}))
 	spec.Run(t, "ReadPrompt", testReadPrompt, spec.Report(report.Terminal{}))
 	spec.Run(t, "Apply", testApply, spec.Report(report.Terminal{}))
 	spec.Run(t, "AskPrompts", testAskPrompts, spec.Report(report.Terminal{}))
 	spec.Run(t, "NoArgument", testApplyNoArgument, spec.Report(report.Terminal{}))
+	spec.Run(t, "ApplyPermissions", testApplyPermissions, spec.Report(report.Terminal{}))
+	spec.Run(t, "ApplyEmpty", testApplyEmpty, spec.Report(report.Terminal{}))
+	spec.Run(t, "ApplyChanged", testApplyChanged, spec.Report(report.Terminal{}))
+	spec.Run(t, "ApplyHardLinks", testApplyHardLinks, spec.Report(report.Terminal{}))
+	spec.Run(t, "ApplyRoots", testApplyRoots, spec.Report(report.Terminal{}))
+	spec.Run(t, "ApplyExclusions", testApplyExclusions, spec.Report(report.Terminal{}))
+	spec.Run(t, "ApplyTimeout", testApplyTimeout, spec.Report(report.Terminal{}))
+	spec.Run(t, "Provenance", testProvenance, spec.Report(report.Terminal{}))
+	spec.Run(t, "Engine", testEngine, spec.Report(report.Terminal{}))
+	spec.Run(t, "NameForms", testNameForms, spec.Report(report.Terminal{}))
+	spec.Run(t, "Mercurial", testMercurial, spec.Report(report.Terminal{}))
 	spec.Run(t, "Replace", testReplace, spec.Report(report.Terminal{}))
 	spec.Run(t, "Transform", testTransform, spec.Report(report.Terminal{}))
+	spec.Run(t, "ReadSpec", testReadSpec, spec.Report(report.Terminal{}))
+	spec.Run(t, "History", testHistory, spec.Report(report.Terminal{}))
+	spec.Run(t, "Policy", testPolicy, spec.Report(report.Terminal{}))
+	spec.Run(t, "Capabilities", testCapabilities, spec.Report(report.Terminal{}))
+	spec.Run(t, "Functions", testFunctions, spec.Report(report.Terminal{}))
+	spec.Run(t, "Matrix", testMatrix, spec.Report(report.Terminal{}))
+	spec.Run(t, "Digest", testDigest, spec.Report(report.Terminal{}))
+	spec.Run(t, "Normalize", testNormalize, spec.Report(report.Terminal{}))
+	spec.Run(t, "CheckPattern", testCheckPattern, spec.Report(report.Terminal{}))
+	spec.Run(t, "Answer", testAnswer, spec.Report(report.Terminal{}))
+	spec.Run(t, "Condition", testCondition, spec.Report(report.Terminal{}))
+	spec.Run(t, "Introspect", testIntrospect, spec.Report(report.Terminal{}))
+	spec.Run(t, "DefaultOutputFolder", testDefaultOutputFolder, spec.Report(report.Terminal{}))
+	spec.Run(t, "ModePolicy", testModePolicy, spec.Report(report.Terminal{}))
+	spec.Run(t, "Auth", testAuth, spec.Report(report.Terminal{}))
+	spec.Run(t, "Concurrency", testConcurrency, spec.Report(report.Terminal{}))
+	spec.Run(t, "ValueProviders", testValueProviders, spec.Report(report.Terminal{}))
+	spec.Run(t, "Registry", testRegistry, spec.Report(report.Terminal{}))
+	spec.Run(t, "AnswersFile", testAnswersFile, spec.Report(report.Terminal{}))
+	spec.Run(t, "ContextVariables", testContextVariables, spec.Report(report.Terminal{}))
+	spec.Run(t, "Validators", testValidators, spec.Report(report.Terminal{}))
+	spec.Run(t, "ChoiceMatching", testChoiceMatching, spec.Report(report.Terminal{}))
+	spec.Run(t, "Paths", testPaths, spec.Report(report.Terminal{}))
+	spec.Run(t, "ReadWorkspace", testReadWorkspace, spec.Report(report.Terminal{}))
+	spec.Run(t, "CheckLength", testCheckLength, spec.Report(report.Terminal{}))
+	spec.Run(t, "HostKeyChecking", testHostKeyChecking, spec.Report(report.Terminal{}))
+	spec.Run(t, "Translations", testTranslations, spec.Report(report.Terminal{}))
+	spec.Run(t, "Warnings", testWarnings, spec.Report(report.Terminal{}))
+	spec.Run(t, "Space", testSpace, spec.Report(report.Terminal{}))
+	spec.Run(t, "StrictVariables", testStrictVariables, spec.Report(report.Terminal{}))
+	spec.Run(t, "Checkpoint", testCheckpoint, spec.Report(report.Terminal{}))
+	spec.Run(t, "Messages", testMessages, spec.Report(report.Terminal{}))
+	spec.Run(t, "ErrorMessage", testErrorMessage, spec.Report(report.Terminal{}))
+	spec.Run(t, "Tree", testTree, spec.Report(report.Terminal{}))
+	spec.Run(t, "Escaping", testEscaping, spec.Report(report.Terminal{}))
+	spec.Run(t, "Helpers", testHelpers, spec.Report(report.Terminal{}))
+	spec.Run(t, "Seed", testSeed, spec.Report(report.Terminal{}))
+	spec.Run(t, "ApplyRaw", testApplyRaw, spec.Report(report.Terminal{}))
+	spec.Run(t, "Width", testWidth, spec.Report(report.Terminal{}))
+	spec.Run(t, "ApplyTemplateSuffix", testApplyTemplateSuffix, spec.Report(report.Terminal{}))
+	spec.Run(t, "CodeOwners", testCodeOwners, spec.Report(report.Terminal{}))
+	spec.Run(t, "TemplateFunctions", testTemplateFunctions, spec.Report(report.Terminal{}))
 }
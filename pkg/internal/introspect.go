@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Facts derived from an existing project, offered as defaults for prompts
+const (
+	ModulePathFact  string = "ModulePath"
+	ProjectNameFact string = "ProjectName"
+	LicenseFact     string = "License"
+)
+
+// Prompt names, compared without regard to case, that are offered each fact
+var factPrompts = map[string][]string{
+	ModulePathFact:  {"modulepath", "module", "gomodule"},
+	ProjectNameFact: {"projectname", "name", "packagename"},
+	LicenseFact:     {"license"},
+}
+
+// Phrases that identify a license in a license file, the first match wins
+var licensePhrases = []struct {
+	license string
+	phrases []string
+}{
+	{"Apache-2.0", []string{"Apache License", "Version 2.0"}},
+	{"MIT", []string{"Permission is hereby granted, free of charge"}},
+	{"LGPL-3.0", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"GPL-3.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"GPL-2.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 2"}},
+	{"MPL-2.0", []string{"Mozilla Public License", "2.0"}},
+	{"BSD-3-Clause", []string{"Redistribution and use", "Neither the name"}},
+	{"BSD-2-Clause", []string{"Redistribution and use"}},
+	{"ISC", []string{"Permission to use, copy, modify, and/or distribute"}},
+	{"Unlicense", []string{"This is free and unencumbered software"}},
+}
+
+var licenseFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"}
+
+// Introspect derives facts from an existing project in dir: the module path
+// from go.mod, the project name from package.json and the license from the
+// license file.  Facts that cannot be derived are omitted.
+func Introspect(dir string) map[string]string {
+	facts := map[string]string{}
+	if module := goModulePath(filepath.Join(dir, "go.mod")); module != "" {
+		facts[ModulePathFact] = module
+	}
+	if name := packageName(filepath.Join(dir, "package.json")); name != "" {
+		facts[ProjectNameFact] = name
+	}
+	for _, licenseFile := range licenseFiles {
+		if license := detectLicense(filepath.Join(dir, licenseFile)); license != "" {
+			facts[LicenseFact] = license
+			break
+		}
+	}
+	return facts
+}
+
+// FactForPrompt finds the fact offered to the prompt called name
+func FactForPrompt(facts map[string]string, name string) (string, bool) {
+	for fact, names := range factPrompts {
+		value, ok := facts[fact]
+		if !ok {
+			continue
+		}
+		for _, n := range names {
+			if strings.EqualFold(n, name) {
+				return value, true
+			}
+		}
+	}
+	return "", false
+}
+
+func goModulePath(goMod string) string {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.SplitN(scanner.Text(), "//", 2)[0]
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], "\"`")
+		}
+	}
+	return ""
+}
+
+func packageName(packageJSON string) string {
+	data, err := os.ReadFile(packageJSON)
+	if err != nil {
+		return ""
+	}
+	pkg := struct {
+		Name string `json:"name"`
+	}{}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return ""
+	}
+	return pkg.Name
+}
+
+func detectLicense(licenseFile string) string {
+	data, err := os.ReadFile(licenseFile)
+	if err != nil {
+		return ""
+	}
+	// license files are often wrapped at different widths
+	text := strings.Join(strings.Fields(string(data)), " ")
+	for _, candidate := range licensePhrases {
+		matched := true
+		for _, phrase := range candidate.phrases {
+			if !strings.Contains(text, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return candidate.license
+		}
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package internal_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testIntrospect(t *testing.T, when spec.G, it spec.S) {
+	var (
+		projectDir string
+	)
+
+	it.Before(func() {
+		projectDir, _ = os.MkdirTemp("", "test")
+	})
+
+	it.After(func() {
+		os.RemoveAll(projectDir)
+	})
+
+	when("the output folder contains a project", func() {
+		it.Before(func() {
+			os.WriteFile(filepath.Join(projectDir, "go.mod"), []byte("module github.com/org/repo // the repo\n\ngo 1.18\n"), 0600)
+			os.WriteFile(filepath.Join(projectDir, "package.json"), []byte(`{"name": "repo-ui", "version": "1.0.0"}`), 0600)
+			os.WriteFile(filepath.Join(projectDir, "LICENSE"), []byte("\n                                 Apache License\n                           Version 2.0, January 2004\n"), 0600)
+		})
+
+		it("derives facts from the project", func() {
+			h.AssertEq(t, internal.Introspect(projectDir), map[string]string{
+				internal.ModulePathFact:  "github.com/org/repo",
+				internal.ProjectNameFact: "repo-ui",
+				internal.LicenseFact:     "Apache-2.0",
+			})
+		})
+
+		it("suggests facts as the defaults of matching prompts", func() {
+			prompts := `
+[[prompt]]
+name = "module"
+prompt = "Go module path"
+default = "example.com/module"
+
+[[prompt]]
+name = "License"
+prompt = "Choose a license"
+choices = ["MIT", "Apache-2.0"]
+`
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(prompts)), nil, nil)
+			h.AssertNil(t, err)
+			values, err := template.Suggest(internal.Introspect(projectDir)).Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values, map[string]string{"module": "github.com/org/repo", "License": "Apache-2.0"})
+		})
+	})
+
+	when("the output folder is empty", func() {
+		it("derives no facts", func() {
+			h.AssertEq(t, internal.Introspect(projectDir), map[string]string{})
+		})
+	})
+}
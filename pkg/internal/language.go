@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"os"
+	"strings"
+)
+
+// Translation localizes the text of a prompt shown to the end-user, the
+// answers to a localized prompt are the same as those to the prompt
+type Translation struct {
+	Prompt         string `toml:"prompt,omitempty"`
+	Help           string `toml:"help,omitempty"`
+	PatternMessage string `toml:"pattern-message,omitempty"`
+	ErrorMessage   string `toml:"error-message,omitempty"`
+	// Choices maps a choice to the label shown in its place
+	Choices map[string]string `toml:"choices,omitempty"`
+}
+
+// CurrentLanguage finds the language of the end-user from the environment,
+// such as fr for LANG=fr_FR.UTF-8
+func CurrentLanguage() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if name := os.Getenv(env); name != "" {
+			return normalizeLanguage(name)
+		}
+	}
+	return ""
+}
+
+// Write a language, or a locale such as fr_FR.UTF-8, as a lower case tag
+// such as fr-fr.  The C and POSIX locales have no language.
+func normalizeLanguage(name string) string {
+	if i := strings.IndexAny(name, ".@"); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+	if name == "c" || name == "posix" {
+		return ""
+	}
+	return name
+}
+
+// Find the translation of the prompt into language, or failing that into the
+// language without its region, so that fr is used for fr-CA
+func (p Prompt) translation(language string) (Translation, bool) {
+	language = normalizeLanguage(language)
+	if language == "" {
+		return Translation{}, false
+	}
+	translations := make(map[string]Translation, len(p.Translations))
+	for name, translation := range p.Translations {
+		translations[normalizeLanguage(name)] = translation
+	}
+	if translation, ok := translations[language]; ok {
+		return translation, true
+	}
+	if i := strings.Index(language, "-"); i > 0 {
+		translation, ok := translations[language[:i]]
+		return translation, ok
+	}
+	return Translation{}, false
+}
+
+// Localize the text of the prompt into language, text that is not translated
+// is shown as it is written
+func (p Prompt) Localize(language string) Prompt {
+	translation, ok := p.translation(language)
+	if !ok {
+		return p
+	}
+	if translation.Prompt != "" {
+		p.Prompt = translation.Prompt
+	}
+	if translation.Help != "" {
+		p.Help = translation.Help
+	}
+	if translation.PatternMessage != "" {
+		p.PatternMessage = translation.PatternMessage
+	}
+	if translation.ErrorMessage != "" {
+		p.ErrorMessage = translation.ErrorMessage
+	}
+	if len(translation.Choices) != 0 {
+		labels := make([]string, len(p.Choices))
+		for i, choice := range p.Choices {
+			if i < len(p.Labels) {
+				labels[i] = p.Labels[i]
+			}
+			if label, ok := translation.Choices[choice]; ok {
+				labels[i] = label
+			}
+		}
+		p.Labels = labels
+	}
+	return p
+}
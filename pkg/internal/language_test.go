@@ -0,0 +1,100 @@
+package internal_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testTranslations(t *testing.T, when spec.G, it spec.S) {
+	promptFile := `[[prompt]]
+name = "Database"
+prompt = "Which database"
+help = "Used by the service"
+choices = ["postgres", "mysql"]
+
+[prompt.labels.fr]
+prompt = "Quelle base de données"
+choices = { postgres = "PostgreSQL (recommandé)" }
+
+[prompt.labels.pt-BR]
+prompt = "Qual banco de dados"
+`
+	var prompt internal.Prompt
+
+	it.Before(func() {
+		template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), nil, nil)
+		h.AssertNil(t, err)
+		prompt = template.Arguments()[0]
+	})
+
+	when("a prompt is translated into the language", func() {
+		it("localizes the prompt and the labels of its choices", func() {
+			localized := prompt.Localize("fr")
+			h.AssertEq(t, localized.Prompt, "Quelle base de données")
+			h.AssertEq(t, localized.Help, "Used by the service")
+			h.AssertEq(t, localized.Options(), []string{"PostgreSQL (recommandé)", "mysql"})
+			h.AssertEq(t, localized.Choices, []string{"postgres", "mysql"})
+		})
+
+		it("matches a locale read from the environment", func() {
+			h.AssertEq(t, prompt.Localize("fr_CA.UTF-8").Prompt, "Quelle base de données")
+			h.AssertEq(t, prompt.Localize("pt_BR").Prompt, "Qual banco de dados")
+		})
+	})
+
+	when("a prompt is not translated into the language", func() {
+		it("is asked as it is written", func() {
+			h.AssertEq(t, prompt.Localize("de").Prompt, "Which database")
+			h.AssertEq(t, prompt.Localize("pt").Prompt, "Which database")
+			h.AssertEq(t, prompt.Localize("").Prompt, "Which database")
+		})
+	})
+
+	when("the language is read from the environment", func() {
+		it("prefers LC_ALL to LANG", func() {
+			t.Setenv("LC_ALL", "")
+			t.Setenv("LC_MESSAGES", "")
+			t.Setenv("LANG", "fr_FR.UTF-8")
+			h.AssertEq(t, internal.CurrentLanguage(), "fr-fr")
+			t.Setenv("LC_ALL", "C")
+			h.AssertEq(t, internal.CurrentLanguage(), "")
+		})
+	})
+
+	when("a translated prompt is asked", func() {
+		it("shows the translation and answers with the choice", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), nil, nil)
+			h.AssertNil(t, err)
+			test := func(stdio terminal.Stdio) (map[string]string, error) {
+				return template.Localize("fr").Ask(survey.WithStdio(stdio.In, stdio.Out, stdio.Err))
+			}
+			RunTest(t, func(c expectConsole) {
+				c.ExpectString("Quelle base de données")
+				c.ExpectString("PostgreSQL (recommandé)")
+				c.SendLine("")
+				c.ExpectEOF()
+			}, test, map[string]string{"Database": "postgres"})
+		})
+	})
+
+	when("a translation labels a value that is not a choice", func() {
+		it("reports the translation", func() {
+			invalid := strings.Replace(promptFile, "{ postgres =", "{ sqlite =", 1)
+			_, err := internal.NewTemplate(io.NopCloser(strings.NewReader(invalid)), nil, nil)
+			var fileErr internal.PromptFileError
+			h.AssertTrue(t, errors.As(err, &fileErr))
+			h.AssertEq(t, fileErr.Problems[0].Line, 9)
+			h.AssertEq(t, fileErr.Problems[0].Key, "prompt.labels.fr.choices.sqlite")
+			h.AssertEq(t, fileErr.Problems[0].Message, "the fr translation of prompt Database labels sqlite, which is not one of the choices postgres, mysql")
+		})
+	})
+}
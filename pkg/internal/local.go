@@ -0,0 +1,252 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	cp "github.com/otiai10/copy"
+)
+
+// MaxLocalSize is the maximum number of bytes copied from a local template
+// folder
+const MaxLocalSize int64 = 1 << 30
+
+// LocalPath resolves a reference to a local template, which may be a relative
+// path, a path starting with ~ or a file:// url, to an absolute path.  False is
+// returned, along with url, when url does not refer to an existing local file
+// or folder.
+func LocalPath(url string) (string, bool) {
+	path, ok := localPath(url)
+	if !ok {
+		return url, false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return url, false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return url, false
+	}
+	return abs, true
+}
+
+// Find the filesystem path of url without checking that the path exists
+func localPath(url string) (string, bool) {
+	if strings.HasPrefix(url, "file://") {
+		u, err := neturl.Parse(url)
+		if err != nil || (u.Host != "" && u.Host != "localhost") {
+			return "", false
+		}
+		path := u.Path
+		// file:///C:/templates names a path on a Windows drive
+		if len(path) > 2 && path[0] == '/' && path[2] == ':' {
+			path = path[1:]
+		}
+		return filepath.FromSlash(path), true
+	}
+	if url == "~" || strings.HasPrefix(url, "~/") || strings.HasPrefix(url, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		return filepath.Join(home, url[1:]), true
+	}
+	return url, true
+}
+
+// Reports whether url is a file:// url naming the root of a git repository,
+// which is cloned in the same way as a remote repository.  Other file:// urls
+// name folders that are copied.
+func isFileRepository(url string, dir string) bool {
+	if !strings.HasPrefix(url, "file://") {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true
+	}
+	return IsBareRepository(dir)
+}
+
+// Copy the template at the sub path of a local folder to the same sub path of
+// tmpDir, including any uncommitted changes.  Where the sub path is not found
+// in a folder within a git worktree then the sub path is relative to the root
+// of the worktree, so that a monorepo can host and use its own templates.
+// Files ignored by a .gitignore, such as build artifacts, and special files,
+// such as sockets, are not copied.
+func copyLocal(dir string, tmpDir string, opts FetchOptions) error {
+	root, inWorktree := worktreeRoot(dir)
+	source := filepath.Join(dir, opts.SubPath)
+	if _, err := os.Stat(source); err != nil {
+		if !inWorktree || opts.SubPath == "" {
+			return nil
+		}
+		source = filepath.Join(root, opts.SubPath)
+		if _, err := os.Stat(source); err != nil {
+			return nil
+		}
+	}
+	if !inWorktree {
+		root = source
+	}
+
+	rules, err := newIgnoreRules(root, source)
+	if err != nil {
+		return err
+	}
+	c := localCopy{url: dir, source: source, opts: opts, rules: rules}
+	reportProgress(dir, opts, "Copying files", -1)
+	if err := cp.Copy(source, filepath.Join(tmpDir, opts.SubPath), cp.Options{Skip: c.skip}); err != nil {
+		return err
+	}
+	if opts.Progress != nil {
+		opts.Progress(FetchEvent{URL: dir, Percent: 100, Done: true})
+	}
+	return nil
+}
+
+// localCopy counts the files and bytes copied from a local template folder
+type localCopy struct {
+	url    string
+	source string
+	opts   FetchOptions
+	rules  *ignoreRules
+	files  int
+	size   int64
+}
+
+// Skip ignored and special files, failing once more than MaxLocalSize bytes
+// are copied
+func (c *localCopy) skip(path string) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+	// sockets, devices and named pipes cannot be copied
+	if info.Mode()&(os.ModeSocket|os.ModeDevice|os.ModeNamedPipe|os.ModeIrregular) != 0 {
+		if rel, err := filepath.Rel(c.source, path); err == nil {
+			c.opts.warn(rel, "special file is skipped")
+		}
+		return true, nil
+	}
+	if ignored, err := c.rules.ignored(path, info); ignored || err != nil {
+		return ignored, err
+	}
+	if !info.Mode().IsRegular() {
+		return false, nil
+	}
+
+	c.size += info.Size()
+	if c.size > MaxLocalSize {
+		return false, fmt.Errorf("local template %s is larger than %d bytes; ignore build artifacts in .gitignore or use a sub path", c.url, MaxLocalSize)
+	}
+	c.files++
+	if c.files%100 == 0 {
+		reportProgress(c.url, c.opts, fmt.Sprintf("Copying files (%d)", c.files), -1)
+	}
+	return false, nil
+}
+
+// ignoreRules are the .gitignore patterns of a folder as it is copied.  A
+// folder is always visited before its contents, so the patterns of a folder
+// are read once the folder is known not to be ignored.
+type ignoreRules struct {
+	root     string
+	patterns []gitignore.Pattern
+	read     map[string]bool
+}
+
+// Read the patterns of root and of each folder between root and dir
+func newIgnoreRules(root string, dir string) (*ignoreRules, error) {
+	rules := &ignoreRules{root: root, read: map[string]bool{}}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return nil, err
+	}
+	domain := []string{}
+	if err := rules.readPatterns(domain); err != nil {
+		return nil, err
+	}
+	if rel == "." {
+		return rules, nil
+	}
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		domain = append(domain, part)
+		if err := rules.readPatterns(domain); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// Read the .gitignore of the folder at domain, relative to the root
+func (r *ignoreRules) readPatterns(domain []string) error {
+	folder := filepath.Join(append([]string{r.root}, domain...)...)
+	if r.read[folder] {
+		return nil
+	}
+	r.read[folder] = true
+
+	f, err := os.Open(filepath.Join(folder, ".gitignore"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		r.patterns = append(r.patterns, gitignore.ParsePattern(line, append([]string{}, domain...)))
+	}
+	return scanner.Err()
+}
+
+// Report whether a file or folder is ignored, later patterns take precedence
+// as in git.  The patterns of a folder that is not ignored are read.
+func (r *ignoreRules) ignored(path string, info os.FileInfo) (bool, error) {
+	rel, err := filepath.Rel(r.root, path)
+	if err != nil || rel == "." {
+		return false, err
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := len(r.patterns) - 1; i >= 0; i-- {
+		if match := r.patterns[i].Match(parts, info.IsDir()); match != gitignore.NoMatch {
+			if match == gitignore.Exclude {
+				return true, nil
+			}
+			break
+		}
+	}
+	if info.IsDir() {
+		return false, r.readPatterns(parts)
+	}
+	return false, nil
+}
+
+// Find the root of the git worktree containing dir.  The .git of a linked
+// worktree or a submodule is a file rather than a folder.
+func worktreeRoot(dir string) (string, bool) {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current, true
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", false
+		}
+		current = parent
+	}
+}
@@ -0,0 +1,208 @@
+package internal_test
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testLocalPath(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+	)
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "scafall-local")
+		h.AssertNil(t, err)
+		tmpDir, err = filepath.EvalSymlinks(tmpDir)
+		h.AssertNil(t, err)
+		h.AssertNil(t, os.MkdirAll(filepath.Join(tmpDir, "my template"), 0755))
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a file url is given", func() {
+		it("resolves the url to a path", func() {
+			path, ok := internal.LocalPath("file://" + filepath.ToSlash(tmpDir) + "/my%20template")
+			h.AssertTrue(t, ok)
+			h.AssertEq(t, path, filepath.Join(tmpDir, "my template"))
+		})
+
+		it("refuses a url naming another host", func() {
+			_, ok := internal.LocalPath("file://example.com" + filepath.ToSlash(tmpDir))
+			h.AssertEq(t, ok, false)
+		})
+	})
+
+	when("a path starts with ~", func() {
+		it("resolves the path within the home folder", func() {
+			t.Setenv("HOME", tmpDir)
+			path, ok := internal.LocalPath("~/my template")
+			h.AssertTrue(t, ok)
+			h.AssertEq(t, path, filepath.Join(tmpDir, "my template"))
+		})
+	})
+
+	when("a relative path is given", func() {
+		it("resolves the path against the working folder", func() {
+			wd, err := os.Getwd()
+			h.AssertNil(t, err)
+			defer os.Chdir(wd)
+			h.AssertNil(t, os.Chdir(tmpDir))
+
+			path, ok := internal.LocalPath("./my template")
+			h.AssertTrue(t, ok)
+			h.AssertEq(t, path, filepath.Join(tmpDir, "my template"))
+			h.AssertEq(t, internal.ExpandURL("my template"), filepath.Join(tmpDir, "my template"))
+		})
+	})
+
+	when("the path does not exist", func() {
+		it("returns the url unchanged", func() {
+			path, ok := internal.LocalPath("file:///does/not/exist")
+			h.AssertEq(t, ok, false)
+			h.AssertEq(t, path, "file:///does/not/exist")
+		})
+	})
+
+	when("a sub path of a folder within a git worktree is used", func() {
+		it("resolves the sub path against the root of the worktree", func() {
+			h.AssertNil(t, os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755))
+			h.AssertNil(t, os.MkdirAll(filepath.Join(tmpDir, "apps", "web"), 0755))
+			h.AssertNil(t, os.MkdirAll(filepath.Join(tmpDir, "templates", "service"), 0755))
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "templates", "service", "main.go"), []byte("uncommitted"), 0600))
+			outputDir, err := os.MkdirTemp("", "scafall")
+			h.AssertNil(t, err)
+			defer os.RemoveAll(outputDir)
+
+			inFs, err := internal.URLToFs(filepath.Join(tmpDir, "apps", "web"), outputDir, internal.FetchOptions{SubPath: "templates/service"})
+			h.AssertNil(t, err)
+			content, err := os.ReadFile(filepath.Join(inFs, "main.go"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(content), "uncommitted")
+		})
+	})
+
+	when("a file url names a folder that is not a repository", func() {
+		it("copies the folder", func() {
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0600))
+			outputDir, err := os.MkdirTemp("", "scafall")
+			h.AssertNil(t, err)
+			defer os.RemoveAll(outputDir)
+
+			inFs, err := internal.URLToFs("file://"+tmpDir, outputDir, internal.FetchOptions{})
+			h.AssertNil(t, err)
+			content, err := os.ReadFile(filepath.Join(inFs, "main.go"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(content), "package main")
+		})
+	})
+
+	when("a file url names a git repository", func() {
+		it("clones only the committed files", func() {
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0600))
+			for _, args := range [][]string{
+				{"init", "--quiet"},
+				{"add", "main.go"},
+				{"-c", "user.name=scafall", "-c", "user.email=scafall@example.com", "commit", "--quiet", "--message", "template"},
+			} {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = tmpDir
+				h.AssertNil(t, cmd.Run())
+			}
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "uncommitted.go"), []byte("package main"), 0600))
+			outputDir, err := os.MkdirTemp("", "scafall")
+			h.AssertNil(t, err)
+			defer os.RemoveAll(outputDir)
+
+			url := internal.ExpandURL("file://" + filepath.ToSlash(tmpDir))
+			h.AssertEq(t, url, "file://"+filepath.ToSlash(tmpDir))
+			inFs, err := internal.URLToFs(url, outputDir, internal.FetchOptions{})
+			h.AssertNil(t, err)
+			_, err = os.Stat(filepath.Join(inFs, "main.go"))
+			h.AssertNil(t, err)
+			_, err = os.Stat(filepath.Join(inFs, "uncommitted.go"))
+			h.AssertTrue(t, os.IsNotExist(err))
+		})
+	})
+
+	when("a file url names a folder that does not exist", func() {
+		it("reports the missing folder", func() {
+			outputDir, err := os.MkdirTemp("", "scafall")
+			h.AssertNil(t, err)
+			defer os.RemoveAll(outputDir)
+
+			_, err = internal.URLToFs("file://"+filepath.Join(tmpDir, "missing"), outputDir, internal.FetchOptions{})
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "does not exist")
+		})
+	})
+
+	when("a local folder has a .gitignore", func() {
+		it("does not copy ignored files", func() {
+			write := func(file string) {
+				h.AssertNil(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, file)), 0755))
+				h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, file), []byte(file), 0600))
+			}
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("node_modules/\n*.pyc\n!keep.pyc\n"), 0600))
+			write("main.py")
+			write("main.pyc")
+			write("keep.pyc")
+			write("node_modules/left-pad/index.js")
+			write("docs/index.md")
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "docs", ".gitignore"), []byte("build\n"), 0600))
+			write("docs/build/index.html")
+			outputDir, err := os.MkdirTemp("", "scafall")
+			h.AssertNil(t, err)
+			defer os.RemoveAll(outputDir)
+
+			inFs, err := internal.URLToFs(tmpDir, outputDir, internal.FetchOptions{})
+			h.AssertNil(t, err)
+			exists := func(file string) bool {
+				_, err := os.Stat(filepath.Join(inFs, file))
+				return err == nil
+			}
+			h.AssertTrue(t, exists("main.py"))
+			h.AssertTrue(t, exists("keep.pyc"))
+			h.AssertTrue(t, exists("docs/index.md"))
+			h.AssertEq(t, exists("main.pyc"), false)
+			h.AssertEq(t, exists("node_modules"), false)
+			h.AssertEq(t, exists("docs/build"), false)
+		})
+	})
+
+	when("a local folder contains a socket", func() {
+		it("copies the other files and reports progress", func() {
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0600))
+			listener, err := net.Listen("unix", filepath.Join(tmpDir, "agent.sock"))
+			h.AssertNil(t, err)
+			defer listener.Close()
+			outputDir, err := os.MkdirTemp("", "scafall")
+			h.AssertNil(t, err)
+			defer os.RemoveAll(outputDir)
+
+			events := []internal.FetchEvent{}
+			progress := func(event internal.FetchEvent) {
+				events = append(events, event)
+			}
+			inFs, err := internal.URLToFs(tmpDir, outputDir, internal.FetchOptions{Progress: progress})
+			h.AssertNil(t, err)
+			_, err = os.Stat(filepath.Join(inFs, "main.go"))
+			h.AssertNil(t, err)
+			_, err = os.Stat(filepath.Join(inFs, "agent.sock"))
+			h.AssertNotNil(t, err)
+			h.AssertEq(t, events[0].Stage, "Copying files")
+			h.AssertTrue(t, events[len(events)-1].Done)
+		})
+	})
+}
@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// IsBareRepository reports whether dir is a bare git repository, which has
+// no working files to copy
+func IsBareRepository(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return false
+	}
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Write the files of a ref of the local git repository containing dir, or of
+// HEAD when no ref is requested, to tmpDir.  The repository may be bare, a
+// worktree or a linked worktree whose .git is a file.  Uncommitted changes are
+// not included.  As with copyLocal, a sub path that is not found within dir is
+// relative to the root of the repository.
+func checkoutLocal(dir string, tmpDir string, opts FetchOptions) error {
+	root, prefix := dir, ""
+	if !IsBareRepository(dir) {
+		worktree, ok := worktreeRoot(dir)
+		if !ok {
+			return fmt.Errorf("cannot check out %s of %s as it is not in a git repository", opts.Ref, dir)
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(worktree, abs)
+		if err != nil {
+			return err
+		}
+		root, prefix = worktree, filepath.ToSlash(rel)
+	}
+
+	repo, err := git.PlainOpenWithOptions(root, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return err
+	}
+	ref := opts.Ref
+	if ref == "" {
+		ref = string(plumbing.HEAD)
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return fmt.Errorf("requested git ref does not exist: %s", ref)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	reportProgress(dir, opts, "Checking out files", -1)
+	source := path.Join(prefix, opts.SubPath)
+	if source != "" && source != "." {
+		subTree, err := tree.Tree(source)
+		if err != nil && opts.SubPath != "" {
+			subTree, err = tree.Tree(opts.SubPath)
+		}
+		if err != nil && opts.SubPath == "" {
+			return fmt.Errorf("%s does not exist at git ref %s", dir, ref)
+		}
+		if err != nil {
+			return nil
+		}
+		tree = subTree
+	}
+
+	remaining := MaxLocalSize
+	target := filepath.Join(tmpDir, opts.SubPath)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		return writeGitFile(filepath.Join(target, filepath.FromSlash(f.Name)), f, &remaining)
+	})
+	if err != nil {
+		return err
+	}
+	if opts.Progress != nil {
+		opts.Progress(FetchEvent{URL: dir, Percent: 100, Done: true})
+	}
+	return nil
+}
+
+// Write a file of a git tree, counting the bytes written against the
+// remaining budget
+func writeGitFile(target string, f *object.File, remaining *int64) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if f.Mode == filemode.Symlink {
+		link, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, target)
+	}
+	mode, err := f.Mode.ToOSFileMode()
+	if err != nil {
+		return err
+	}
+	r, err := f.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	n, err := io.Copy(out, io.LimitReader(r, *remaining+1))
+	if err != nil {
+		return err
+	}
+	*remaining -= n
+	if *remaining < 0 {
+		return fmt.Errorf("local template is larger than %d bytes", MaxLocalSize)
+	}
+	return nil
+}
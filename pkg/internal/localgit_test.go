@@ -0,0 +1,117 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testLocalRepository(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir  string
+		repoDir string
+		outDir  string
+	)
+
+	// commit the content of template/prompts.toml to the repository
+	commit := func(repo *git.Repository, content string) {
+		h.AssertNil(t, os.WriteFile(filepath.Join(repoDir, "template", "prompts.toml"), []byte(content), 0644))
+		worktree, err := repo.Worktree()
+		h.AssertNil(t, err)
+		_, err = worktree.Add("template/prompts.toml")
+		h.AssertNil(t, err)
+		_, err = worktree.Commit(content, &git.CommitOptions{Author: &object.Signature{Name: "duck", Email: "duck@example.com", When: time.Now()}})
+		h.AssertNil(t, err)
+	}
+
+	read := func(file string) string {
+		content, err := os.ReadFile(file)
+		h.AssertNil(t, err)
+		return string(content)
+	}
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "scafall-repo")
+		h.AssertNil(t, err)
+		repoDir = filepath.Join(tmpDir, "repo")
+		outDir = filepath.Join(tmpDir, "out")
+		h.AssertNil(t, os.MkdirAll(filepath.Join(repoDir, "template"), 0755))
+
+		repo, err := git.PlainInit(repoDir, false)
+		h.AssertNil(t, err)
+		commit(repo, "first")
+		head, err := repo.Head()
+		h.AssertNil(t, err)
+		_, err = repo.CreateTag("v1", head.Hash(), nil)
+		h.AssertNil(t, err)
+		commit(repo, "second")
+		// an uncommitted change
+		h.AssertNil(t, os.WriteFile(filepath.Join(repoDir, "template", "prompts.toml"), []byte("draft"), 0644))
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a ref of a worktree is requested", func() {
+		it("checks out the ref", func() {
+			root, err := internal.URLToFs(repoDir, outDir, internal.FetchOptions{Ref: "v1"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, read(filepath.Join(root, "template", "prompts.toml")), "first")
+		})
+
+		it("checks out a folder within the worktree", func() {
+			root, err := internal.URLToFs(filepath.Join(repoDir, "template"), outDir, internal.FetchOptions{Ref: "v1"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, read(filepath.Join(root, "prompts.toml")), "first")
+		})
+
+		it("reports a ref that does not exist", func() {
+			_, err := internal.URLToFs(repoDir, outDir, internal.FetchOptions{Ref: "v2"})
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "requested git ref does not exist: v2")
+		})
+	})
+
+	when("no ref of a worktree is requested", func() {
+		it("copies uncommitted changes", func() {
+			root, err := internal.URLToFs(repoDir, outDir, internal.FetchOptions{SubPath: "template"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, read(filepath.Join(root, "prompts.toml")), "draft")
+		})
+	})
+
+	when("the repository is bare", func() {
+		it("checks out HEAD", func() {
+			bareDir := filepath.Join(tmpDir, "repo.git")
+			h.AssertNil(t, os.Rename(filepath.Join(repoDir, ".git"), bareDir))
+			h.AssertTrue(t, internal.IsBareRepository(bareDir))
+
+			root, err := internal.URLToFs(bareDir, outDir, internal.FetchOptions{SubPath: "template"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, read(filepath.Join(root, "prompts.toml")), "second")
+		})
+	})
+
+	when("the .git of the worktree is a file", func() {
+		it("checks out the ref from the linked git folder", func() {
+			gitDir := filepath.Join(tmpDir, "repo.git")
+			h.AssertNil(t, os.Rename(filepath.Join(repoDir, ".git"), gitDir))
+			h.AssertNil(t, os.WriteFile(filepath.Join(repoDir, ".git"), []byte("gitdir: "+gitDir+"\n"), 0644))
+			h.AssertEq(t, internal.IsBareRepository(repoDir), false)
+
+			root, err := internal.URLToFs(repoDir, outDir, internal.FetchOptions{Ref: "v1", SubPath: "template"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, read(filepath.Join(root, "prompts.toml")), "first")
+		})
+	})
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// Manifest records a checksum of every file written to the output folder,
+// keyed by the path of the file relative to the output folder
+type Manifest struct {
+	Files map[string]string `toml:"files"`
+}
+
+func WriteManifest(manifest Manifest, manifestFile string) error {
+	f, err := os.OpenFile(manifestFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return toml.NewEncoder(f).Encode(manifest)
+}
+
+func ReadManifest(manifestFile string) (Manifest, error) {
+	manifest := Manifest{}
+	manifestData, err := ReadFile(manifestFile)
+	if err != nil {
+		return manifest, err
+	}
+
+	if _, err := toml.Decode(manifestData, &manifest); err != nil {
+		return manifest, errors.Wrap(err, fmt.Sprintf("%s file does not match required format", manifestFile))
+	}
+	if manifest.Files == nil {
+		manifest.Files = map[string]string{}
+	}
+	return manifest, nil
+}
+
+// Checksum of a rendered file.  Binary files are not rendered, so the checksum
+// is of the input file.
+func checksum(inputDir string, file SourceFile, rendered SourceFile) (string, error) {
+	hash := sha256.New()
+	if rendered.FileContent != "" {
+		hash.Write([]byte(rendered.FileContent))
+	} else {
+		f, err := os.Open(filepath.Join(inputDir, file.FilePath))
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		if _, err := io.Copy(hash, f); err != nil {
+			return "", err
+		}
+	}
+	return DigestAlgorithm + ":" + hex.EncodeToString(hash.Sum(nil)), nil
+}
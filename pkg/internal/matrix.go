@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// Matrix describes combinations of answers with which to render a template
+// and the commands that validate each rendered project
+type Matrix struct {
+	// Values of each variable, every combination of the values is rendered
+	Values map[string][]string `toml:"matrix"`
+	// Include lists explicit combinations that are also rendered
+	Include []map[string]string `toml:"include"`
+	// Validate lists shell commands run in each rendered project
+	Validate []string `toml:"validate"`
+}
+
+func ReadMatrix(matrixFile string) (Matrix, error) {
+	matrix := Matrix{}
+	matrixData, err := ReadFile(matrixFile)
+	if err != nil {
+		return matrix, err
+	}
+
+	if _, err := toml.Decode(matrixData, &matrix); err != nil {
+		return matrix, errors.Wrap(err, fmt.Sprintf("%s file does not match required format", matrixFile))
+	}
+	for name, values := range matrix.Values {
+		if len(values) == 0 {
+			return matrix, fmt.Errorf("%s file contains variable %s with no values", matrixFile, name)
+		}
+	}
+	return matrix, nil
+}
+
+// Combinations returns the cartesian product of the matrix values followed by
+// the included combinations.  A matrix with no values and no included
+// combinations has a single, empty, combination.
+func (m Matrix) Combinations() []map[string]string {
+	names := make([]string, 0, len(m.Values))
+	for name := range m.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	combinations := []map[string]string{}
+	if len(names) != 0 || len(m.Include) == 0 {
+		combinations = append(combinations, map[string]string{})
+	}
+	for _, name := range names {
+		product := []map[string]string{}
+		for _, combination := range combinations {
+			for _, value := range m.Values[name] {
+				next := map[string]string{name: value}
+				for k, v := range combination {
+					next[k] = v
+				}
+				product = append(product, next)
+			}
+		}
+		combinations = product
+	}
+	return append(combinations, m.Include...)
+}
@@ -0,0 +1,58 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testMatrix(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a matrix file is read", func() {
+		it("combines every value and the included combinations", func() {
+			matrixFile := filepath.Join(tmpDir, "matrix.toml")
+			os.WriteFile(matrixFile, []byte(`
+validate = ["make test"]
+
+[matrix]
+Lang = ["go", "python"]
+Test = ["true", "false"]
+
+[[include]]
+Lang = "bash"
+`), 0600)
+
+			matrix, err := internal.ReadMatrix(matrixFile)
+			h.AssertNil(t, err)
+			h.AssertEq(t, matrix.Validate, []string{"make test"})
+			h.AssertEq(t, matrix.Combinations(), []map[string]string{
+				{"Lang": "go", "Test": "true"},
+				{"Lang": "go", "Test": "false"},
+				{"Lang": "python", "Test": "true"},
+				{"Lang": "python", "Test": "false"},
+				{"Lang": "bash"},
+			})
+		})
+
+		it("renders only the included combinations", func() {
+			matrix := internal.Matrix{Include: []map[string]string{{"Lang": "bash"}}}
+			h.AssertEq(t, matrix.Combinations(), []map[string]string{{"Lang": "bash"}})
+		})
+	})
+}
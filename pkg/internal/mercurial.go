@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	neturl "net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// MercurialPrefix marks the url of a Mercurial repository, such as
+// hg+https://hg.example.com/templates
+const MercurialPrefix string = "hg+"
+
+// IsMercurial reports whether url points to a Mercurial repository
+func IsMercurial(url string) bool {
+	return strings.HasPrefix(url, MercurialPrefix)
+}
+
+type mercurialFetcher struct{}
+
+func (mercurialFetcher) Handles(url string) bool {
+	return IsMercurial(url)
+}
+
+// Clone a Mercurial repository using the hg command line, as there is no Go
+// implementation of Mercurial.  The repository is configured before pulling,
+// rather than cloned, so that credentials are neither visible in the process
+// list nor left in the fetched template.
+func (mercurialFetcher) Fetch(url string, tmpDir string, opts FetchOptions) error {
+	hgCmd, err := exec.LookPath("hg")
+	if err != nil {
+		return fmt.Errorf("fetching %s requires Mercurial: %s", url, err)
+	}
+	repoURL := strings.TrimPrefix(url, MercurialPrefix)
+	run := func(args ...string) error {
+		cmd := exec.Command(hgCmd, append([]string{"--noninteractive", "--quiet"}, args...)...)
+		cmd.Env = append(os.Environ(), "HGPLAIN=1")
+		output := bytes.Buffer{}
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("hg %s failed: %s", args[0], strings.TrimSpace(output.String()))
+		}
+		return nil
+	}
+
+	if err := run("init", tmpDir); err != nil {
+		return err
+	}
+	hgrc := filepath.Join(tmpDir, ".hg", "hgrc")
+	if err := os.WriteFile(hgrc, []byte(mercurialConfig(repoURL, opts)), 0600); err != nil {
+		return err
+	}
+	defer os.Remove(hgrc)
+
+	reportProgress(url, opts, "Pulling", -1)
+	pullArgs := []string{"pull", "-R", tmpDir}
+	// without a ref use the default branch, otherwise only the ref and its
+	// ancestors are pulled so the ref is the tip
+	rev := "default"
+	if opts.Ref != "" {
+		pullArgs = append(pullArgs, "--rev", opts.Ref)
+		rev = "tip"
+	}
+	if err := run(pullArgs...); err != nil {
+		return err
+	}
+	reportProgress(url, opts, "Updating", -1)
+	return run("update", "-R", tmpDir, "--rev", rev)
+}
+
+// Write the configuration of a repository pulled from repoURL
+func mercurialConfig(repoURL string, opts FetchOptions) string {
+	config := strings.Builder{}
+	fmt.Fprintf(&config, "[paths]\ndefault = %s\n", repoURL)
+	if auth, ok := FindHTTPAuth(repoURL, opts).(*githttp.BasicAuth); ok {
+		fmt.Fprintf(&config, "[auth]\nscafall.prefix = %s\nscafall.username = %s\nscafall.password = %s\n", repoURL, auth.Username, auth.Password)
+	}
+	if u, err := neturl.Parse(opts.Proxy); err == nil && u.Host != "" {
+		fmt.Fprintf(&config, "[http_proxy]\nhost = %s\n", u.Host)
+	}
+	if opts.CABundle != "" {
+		// Mercurial trusts only the bundle, rather than the bundle and the
+		// system certificates
+		fmt.Fprintf(&config, "[web]\ncacerts = %s\n", opts.CABundle)
+	}
+	return config.String()
+}
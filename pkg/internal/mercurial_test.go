@@ -0,0 +1,77 @@
+package internal_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testMercurial(t *testing.T, when spec.G, it spec.S) {
+	when("a url is checked", func() {
+		it("recognises Mercurial urls", func() {
+			h.AssertTrue(t, internal.IsMercurial("hg+https://hg.example.com/templates"))
+			h.AssertEq(t, internal.IsMercurial("https://github.com/org/repo"), false)
+		})
+	})
+
+	when("a Mercurial repository is fetched", func() {
+		var (
+			repoDir string
+			tmpDir  string
+		)
+
+		it.Before(func() {
+			if _, err := exec.LookPath("hg"); err != nil {
+				t.Skip("hg is not installed")
+			}
+			var err error
+			repoDir, err = os.MkdirTemp("", "scafall-hg")
+			h.AssertNil(t, err)
+			tmpDir, err = os.MkdirTemp("", "scafall")
+			h.AssertNil(t, err)
+
+			hg := func(args ...string) {
+				cmd := exec.Command("hg", append([]string{"--config", "ui.username=test"}, args...)...)
+				cmd.Dir = repoDir
+				_, err := cmd.CombinedOutput()
+				h.AssertNil(t, err)
+			}
+			hg("init")
+			h.AssertNil(t, os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("v1"), 0600))
+			hg("add", "README.md")
+			hg("commit", "-m", "v1")
+			hg("tag", "v1")
+			h.AssertNil(t, os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("v2"), 0600))
+			hg("commit", "-m", "v2")
+		})
+
+		it.After(func() {
+			os.RemoveAll(repoDir)
+			os.RemoveAll(tmpDir)
+		})
+
+		it("fetches the latest revision", func() {
+			inFs, err := internal.URLToFs("hg+"+repoDir, tmpDir, internal.FetchOptions{})
+			h.AssertNil(t, err)
+			content, err := os.ReadFile(filepath.Join(inFs, "README.md"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(content), "v2")
+			_, err = os.Stat(filepath.Join(inFs, ".hg", "hgrc"))
+			h.AssertNotNil(t, err)
+		})
+
+		it("fetches a tag", func() {
+			inFs, err := internal.URLToFs("hg+"+repoDir, tmpDir, internal.FetchOptions{Ref: "v1"})
+			h.AssertNil(t, err)
+			content, err := os.ReadFile(filepath.Join(inFs, "README.md"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(content), "v1")
+		})
+	})
+}
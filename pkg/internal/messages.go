@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code identifies a user-facing message, so that it can be searched for
+// whatever language the message is written in
+type Code string
+
+// Codes of the messages in the Messages catalogue, grouped by the step that
+// fails.  A code is never reused for another message, even once its message
+// is removed.
+const (
+	// fetching the template
+	CodeAuthRequired   Code = "SCFL-0101"
+	CodeAuthToken      Code = "SCFL-0102"
+	CodeAuthRejected   Code = "SCFL-0103"
+	CodeHostKeyUnknown Code = "SCFL-0104"
+	CodeHostKeyChanged Code = "SCFL-0105"
+
+	// reading the template
+	CodeNotATemplate Code = "SCFL-0201"
+	CodeEmptyOutput  Code = "SCFL-0202"
+	CodePromptFile   Code = "SCFL-0203"
+
+	// prompting
+	CodeMissingValue    Code = "SCFL-0301"
+	CodeMissingValues   Code = "SCFL-0302"
+	CodeInvalidAnswers  Code = "SCFL-0303"
+	CodePromptFailed    Code = "SCFL-0304"
+	CodePromptAborted   Code = "SCFL-0305"
+	CodeNotConfirmed    Code = "SCFL-0306"
+	CodeInvalidArgument Code = "SCFL-0307"
+
+	// writing the project
+	CodeRenderFailed    Code = "SCFL-0401"
+	CodeRenderTimeout   Code = "SCFL-0402"
+	CodeRefusedFunction Code = "SCFL-0403"
+	CodeUndeclaredVars  Code = "SCFL-0404"
+	CodeNoSpace         Code = "SCFL-0405"
+)
+
+// Messages is the catalogue of user-facing messages, each is a format for
+// fmt.Sprintf
+var Messages = map[Code]string{
+	CodeAuthRequired:    "%s requires authentication: provide a username and password or access token",
+	CodeAuthToken:       "%s requires authentication: set %s to an access token",
+	CodeAuthRejected:    "the credentials for %s were rejected: %s",
+	CodeHostKeyUnknown:  "the host key of %s is not in %s; verify the key and add it with ssh-keyscan %s >> %s, or use --host-key-checking %s",
+	CodeHostKeyChanged:  "the host key of %s does not match the key in %s; the connection may be intercepted, once the new key is verified remove the old key with ssh-keygen -R %s",
+	CodeNotATemplate:    "no %s found in the top-level folder, which contains: %s",
+	CodeEmptyOutput:     "project template %s renders no files; check that the url and sub path point to a project template",
+	CodePromptFile:      "%s: %s",
+	CodeMissingValue:    "%s is required and has no default value",
+	CodeMissingValues:   "%s are required and have no default value",
+	CodeInvalidAnswers:  "invalid answers: %s",
+	CodePromptFailed:    "failed to ask %s: %s",
+	CodePromptAborted:   "prompt aborted",
+	CodeNotConfirmed:    "the project was not confirmed",
+	CodeInvalidArgument: "the value given for %s is invalid: %s",
+	CodeRenderFailed:    "failed to transform %s: %s",
+	CodeRenderTimeout:   "rendering took longer than %s",
+	CodeRefusedFunction: "%s calls functions refused by policy: %s",
+	CodeUndeclaredVars:  "the template uses undeclared variables, declare them in prompts.toml or give them as arguments:",
+	CodeNoSpace:         "not enough space to create the project in %s: about %d %s are needed but only %d are available",
+}
+
+// Format the message with code
+func message(code Code, args ...interface{}) string {
+	return fmt.Sprintf(Messages[code], args...)
+}
+
+// codedError is an error that is only its message, such as ErrPromptAborted
+type codedError struct {
+	code Code
+}
+
+// NewError creates an error whose text is the message with code, errors with
+// the same code are equal so that they can be found with errors.Is
+func NewError(code Code) error {
+	return codedError{code: code}
+}
+
+func (e codedError) Error() string {
+	return message(e.code)
+}
+
+func (e codedError) Code() Code {
+	return e.code
+}
+
+// CodeOf finds the code of the outermost error in the chain of err that has a
+// code
+func CodeOf(err error) (Code, bool) {
+	var coded interface{ Code() Code }
+	if errors.As(err, &coded) {
+		return coded.Code(), true
+	}
+	return "", false
+}
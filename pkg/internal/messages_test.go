@@ -0,0 +1,58 @@
+package internal_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/pkg/errors"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testMessages(t *testing.T, when spec.G, it spec.S) {
+	when("messages are catalogued", func() {
+		it("gives every message a code of the form SCFL-xxxx", func() {
+			pattern := regexp.MustCompile(`^SCFL-\d{4}$`)
+			for code, message := range internal.Messages {
+				h.AssertTrue(t, pattern.MatchString(string(code)))
+				h.AssertNotEq(t, message, "")
+			}
+		})
+	})
+
+	when("an error has a code", func() {
+		it("finds the code through wrapping", func() {
+			err := errors.Wrap(internal.ErrPromptAborted, "failed to prompt for values")
+			code, ok := internal.CodeOf(err)
+			h.AssertTrue(t, ok)
+			h.AssertEq(t, code, internal.CodePromptAborted)
+			h.AssertTrue(t, errors.Is(err, internal.ErrPromptAborted))
+		})
+
+		it("finds the code of the cause of a file error", func() {
+			err := internal.FileError{FilePath: "main.go", Err: internal.RenderTimeoutError{Timeout: time.Second}}
+			code, ok := internal.CodeOf(err)
+			h.AssertTrue(t, ok)
+			h.AssertEq(t, code, internal.CodeRenderTimeout)
+			h.AssertEq(t, err.Error(), "failed to transform main.go: rendering took longer than 1s")
+		})
+
+		it("uses a code for each form of a message", func() {
+			code, _ := internal.CodeOf(internal.MissingValuesError{Names: []string{"a"}})
+			h.AssertEq(t, code, internal.CodeMissingValue)
+			code, _ = internal.CodeOf(internal.MissingValuesError{Names: []string{"a", "b"}})
+			h.AssertEq(t, code, internal.CodeMissingValues)
+		})
+	})
+
+	when("an error has no code", func() {
+		it("reports no code", func() {
+			_, ok := internal.CodeOf(fmt.Errorf("unexpected"))
+			h.AssertTrue(t, !ok)
+		})
+	})
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ModePolicy overrides the permissions of generated files and folders, such
+// as when a CI system creates a project to be used by other users
+type ModePolicy struct {
+	// FileMode and DirMode are set on every generated file and folder, a
+	// mode of zero leaves permissions unchanged
+	FileMode fs.FileMode
+	DirMode  fs.FileMode
+	// Clamp treats FileMode and DirMode as the most permissive modes allowed,
+	// permissions outside of them are removed rather than the modes being set
+	Clamp bool
+}
+
+// ParseMode parses an octal file mode such as 0644
+func ParseMode(mode string) (fs.FileMode, error) {
+	m, err := strconv.ParseUint(strings.TrimSpace(mode), 8, 32)
+	if err != nil || m > 0777 {
+		return 0, fmt.Errorf("%s is not an octal file mode", mode)
+	}
+	return fs.FileMode(m), nil
+}
+
+// ApplyModePolicy applies policy to files, slash separated paths relative to
+// outputDir, and to the folders within outputDir that contain them.  The
+// permissions of outputDir itself are left unchanged.
+func ApplyModePolicy(outputDir string, files []string, policy ModePolicy) error {
+	if policy.FileMode == 0 && policy.DirMode == 0 {
+		return nil
+	}
+
+	dirs := map[string]bool{}
+	for _, file := range files {
+		if policy.FileMode != 0 {
+			if err := applyMode(filepath.Join(outputDir, filepath.FromSlash(file)), policy.FileMode, policy.Clamp); err != nil {
+				return err
+			}
+		}
+		for dir := path.Dir(file); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+	if policy.DirMode == 0 {
+		return nil
+	}
+
+	// folders are changed from the deepest, so that a folder is still
+	// writable while its contents are changed
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return strings.Count(sorted[i], "/") > strings.Count(sorted[j], "/")
+	})
+	for _, dir := range sorted {
+		if err := applyMode(filepath.Join(outputDir, filepath.FromSlash(dir)), policy.DirMode, policy.Clamp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set the permissions of target to mode, or remove the permissions of target
+// that are not in mode when clamping
+func applyMode(target string, mode fs.FileMode, clamp bool) error {
+	if clamp {
+		info, err := os.Stat(target)
+		if err != nil {
+			return err
+		}
+		mode = info.Mode().Perm() & mode
+	}
+	return os.Chmod(target, mode)
+}
@@ -0,0 +1,81 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testModePolicy(t *testing.T, when spec.G, it spec.S) {
+	var outputDir string
+
+	it.Before(func() {
+		outputDir, _ = os.MkdirTemp("", "test")
+		os.MkdirAll(filepath.Join(outputDir, "bin"), 0777)
+		os.WriteFile(filepath.Join(outputDir, "bin", "run.sh"), []byte("#!/bin/sh"), 0777)
+		os.WriteFile(filepath.Join(outputDir, "README.md"), []byte("# shop"), 0666)
+		os.Chmod(filepath.Join(outputDir, "bin"), 0777)
+		os.Chmod(filepath.Join(outputDir, "bin", "run.sh"), 0777)
+		os.Chmod(filepath.Join(outputDir, "README.md"), 0666)
+	})
+
+	it.After(func() {
+		os.RemoveAll(outputDir)
+	})
+
+	mode := func(file string) os.FileMode {
+		info, err := os.Stat(filepath.Join(outputDir, file))
+		h.AssertNil(t, err)
+		return info.Mode().Perm()
+	}
+
+	when("modes are set", func() {
+		it("sets the mode of every file and folder", func() {
+			policy := internal.ModePolicy{FileMode: 0644, DirMode: 0750}
+			err := internal.ApplyModePolicy(outputDir, []string{"bin/run.sh", "README.md"}, policy)
+			h.AssertNil(t, err)
+			h.AssertEq(t, mode("bin/run.sh"), os.FileMode(0644))
+			h.AssertEq(t, mode("README.md"), os.FileMode(0644))
+			h.AssertEq(t, mode("bin"), os.FileMode(0750))
+		})
+	})
+
+	when("modes are clamped", func() {
+		it("removes permissions outside of the modes", func() {
+			policy := internal.ModePolicy{FileMode: 0755, DirMode: 0755, Clamp: true}
+			err := internal.ApplyModePolicy(outputDir, []string{"bin/run.sh", "README.md"}, policy)
+			h.AssertNil(t, err)
+			h.AssertEq(t, mode("bin/run.sh"), os.FileMode(0755))
+			h.AssertEq(t, mode("README.md"), os.FileMode(0644))
+			h.AssertEq(t, mode("bin"), os.FileMode(0755))
+		})
+	})
+
+	when("no modes are given", func() {
+		it("leaves permissions unchanged", func() {
+			err := internal.ApplyModePolicy(outputDir, []string{"bin/run.sh", "README.md"}, internal.ModePolicy{Clamp: true})
+			h.AssertNil(t, err)
+			h.AssertEq(t, mode("bin/run.sh"), os.FileMode(0777))
+		})
+	})
+
+	when("a mode is parsed", func() {
+		it("accepts octal modes", func() {
+			m, err := internal.ParseMode("0640")
+			h.AssertNil(t, err)
+			h.AssertEq(t, m, os.FileMode(0640))
+		})
+
+		it("rejects other modes", func() {
+			_, err := internal.ParseMode("rwxr-xr-x")
+			h.AssertNotNil(t, err)
+			_, err = internal.ParseMode("01777")
+			h.AssertNotNil(t, err)
+		})
+	})
+}
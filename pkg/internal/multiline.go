@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+)
+
+// multilineQuestionTemplate is survey.MultilineQuestionTemplate with a hint
+// for ending the text at the first empty line
+var multilineQuestionTemplate = strings.Replace(survey.MultilineQuestionTemplate,
+	"[Enter 2 empty lines to finish]", "[Enter an empty line or Ctrl-D to finish]", 1)
+
+// listQuestionTemplate is survey.MultilineQuestionTemplate with a hint for
+// entering the items of a list
+var listQuestionTemplate = strings.Replace(survey.MultilineQuestionTemplate,
+	"[Enter 2 empty lines to finish]", "[Enter one item per line and an empty line to finish]", 1)
+
+// multiline asks for text spanning several lines, such as a description or a
+// license header, or for the items of a list, one per line.  Unlike
+// survey.Multiline the text ends at the first empty line, or when Ctrl-D is
+// pressed on an empty line, and indentation is kept.  Entering the help
+// input, such as ?, as the first line shows the help text.
+type multiline struct {
+	survey.Multiline
+	list bool
+}
+
+func (m *multiline) questionTemplate() string {
+	if m.list {
+		return listQuestionTemplate
+	}
+	return multilineQuestionTemplate
+}
+
+func (m *multiline) Prompt(config *survey.PromptConfig) (interface{}, error) {
+	err := m.Render(m.questionTemplate(), survey.MultilineTemplateData{Multiline: m.Multiline, Config: config})
+	if err != nil {
+		return "", err
+	}
+
+	rr := m.NewRuneReader()
+	_ = rr.SetTermMode()
+	defer func() {
+		_ = rr.RestoreTermMode()
+	}()
+
+	lines := []string{}
+	helpLines := 0
+	for {
+		line, err := rr.ReadLine(0)
+		if err != nil {
+			return "", err
+		}
+		if len(line) == 0 {
+			break
+		}
+		if len(lines) == 0 && helpLines == 0 && m.Help != "" && string(line) == config.HelpInput {
+			// the help input ended its line, which is erased with the prompt
+			m.AppendRenderedText("\n")
+			err := m.Render(m.questionTemplate(), survey.MultilineTemplateData{Multiline: m.Multiline, ShowHelp: true, Config: config})
+			if err != nil {
+				return "", err
+			}
+			helpLines = strings.Count(m.Help, "\n") + 1
+			continue
+		}
+		lines = append(lines, string(line))
+	}
+
+	// erase the prompt and the text, in the same way as survey.Multiline, so
+	// that only the answer is shown by Cleanup
+	cursor := m.NewCursor()
+	erased := len(lines) + helpLines + 2
+	cursor.PreviousLine(erased)
+	for i := 0; i < erased; i++ {
+		terminal.EraseLine(m.Stdio().Out, terminal.ERASE_LINE_ALL)
+		cursor.NextLine(1)
+	}
+	cursor.PreviousLine(erased)
+
+	if len(lines) == 0 {
+		return m.Default, nil
+	}
+	text := strings.Join(lines, "\n")
+	m.AppendRenderedText(text)
+	return text, nil
+}
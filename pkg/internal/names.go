@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+const (
+	// NameFormsVariable holds the NameForms of the ProjectName variable, such
+	// as {{ .name_forms.env }}
+	NameFormsVariable string = "name_forms"
+	// NameFormsFunction computes the NameForms of any name, such as
+	// {{ (nameForms .ServiceName).go }}
+	NameFormsFunction string = "nameForms"
+	// NameFormsBase is the variable from which NameFormsVariable is derived
+	NameFormsBase string = "ProjectName"
+
+	// maximum length of a docker tag
+	maxDockerTag = 128
+)
+
+// caseVariants derive forms of every variable, named by a suffix of the
+// variable name, so that {{ .ServiceName_snake }} is the snake case form of
+// ServiceName.  For a value such as Order Service the variants are:
+//
+//	_snake:  order_service
+//	_kebab:  order-service
+//	_camel:  orderService
+//	_pascal: OrderService
+var caseVariants = map[string]func([]string) string{
+	"_snake": func(words []string) string {
+		return strings.ToLower(strings.Join(words, "_"))
+	},
+	"_kebab": func(words []string) string {
+		return strings.ToLower(strings.Join(words, "-"))
+	},
+	"_camel": func(words []string) string {
+		form := ""
+		for i, word := range words {
+			if i == 0 {
+				form += strings.ToLower(word)
+			} else {
+				form += capitalize(word)
+			}
+		}
+		return form
+	},
+	"_pascal": func(words []string) string {
+		form := ""
+		for _, word := range words {
+			form += capitalize(word)
+		}
+		return form
+	},
+}
+
+// CaseVariants derives the case variants of each of vars.  Variants of
+// reserved and list variables are not derived, and no variant replaces a
+// variable of the same name.
+func CaseVariants(vars map[string]string) map[string]string {
+	lists := map[string]bool{}
+	for _, name := range strings.Split(vars[ListsVariable], ",") {
+		lists[name] = true
+	}
+	variants := map[string]string{}
+	for name, value := range vars {
+		if strings.HasPrefix(name, ReservedPrefix) || lists[name] {
+			continue
+		}
+		words := splitWords(value)
+		for suffix, variant := range caseVariants {
+			if _, exists := vars[name+suffix]; !exists {
+				variants[name+suffix] = variant(words)
+			}
+		}
+	}
+	return variants
+}
+
+// Report the variable from which a case variant, such as ServiceName_snake,
+// is derived
+func caseVariantOf(name string) (string, bool) {
+	for suffix := range caseVariants {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return strings.TrimSuffix(name, suffix), true
+		}
+	}
+	return name, false
+}
+
+// Upper case the first letter of word and lower case the rest
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	if len(runes) == 0 {
+		return ""
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// NameForms derives sanitized forms of name for use in different contexts.
+// For a name such as My Shop/API the forms are:
+//
+//	path:   a single file or folder name, My Shop-API
+//	env:    an environment variable name, MY_SHOP_API
+//	go:     a Go identifier, myShopApi
+//	docker: a docker image name or tag, my-shop-api
+func NameForms(name string) map[string]string {
+	words := splitWords(name)
+	return map[string]string{
+		"path":   pathForm(name),
+		"env":    envForm(words),
+		"go":     goForm(words),
+		"docker": dockerForm(words),
+	}
+}
+
+// Split name into words at punctuation, spaces and changes of case, so that
+// "my shop-API", "MyShopAPI" and "my_shop_api" have the same words
+func splitWords(name string) []string {
+	words := []string{}
+	runes := []rune(name)
+	current := []rune{}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(current) != 0 {
+				words = append(words, string(current))
+				current = []rune{}
+			}
+			continue
+		}
+		if len(current) != 0 && unicode.IsUpper(r) {
+			previous := current[len(current)-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// break before the start of a word, such as Shop in MyShop, or
+			// Shop in APIShop
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				words = append(words, string(current))
+				current = []rune{}
+			}
+		}
+		current = append(current, r)
+	}
+	if len(current) != 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
+
+// Replace characters that cannot be used in file names on any platform
+func pathForm(name string) string {
+	form := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	return strings.Trim(form, " .")
+}
+
+func envForm(words []string) string {
+	form := strings.ToUpper(strings.Join(asciiWords(words), "_"))
+	if form != "" && unicode.IsDigit(rune(form[0])) {
+		form = "_" + form
+	}
+	return form
+}
+
+func goForm(words []string) string {
+	words = asciiWords(words)
+	form := ""
+	for i, word := range words {
+		if i == 0 {
+			form += strings.ToLower(word)
+		} else {
+			form += strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+		}
+	}
+	if form != "" && (unicode.IsDigit(rune(form[0])) || token.IsKeyword(form)) {
+		form = "_" + form
+	}
+	return form
+}
+
+func dockerForm(words []string) string {
+	form := strings.ToLower(strings.Join(asciiWords(words), "-"))
+	if len(form) > maxDockerTag {
+		form = strings.TrimRight(form[:maxDockerTag], "-")
+	}
+	return form
+}
+
+// Remove the characters of words that are not ASCII letters or digits,
+// dropping words that are left empty
+func asciiWords(words []string) []string {
+	ascii := []string{}
+	for _, word := range words {
+		word = strings.Map(func(r rune) rune {
+			if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return r
+			}
+			return -1
+		}, word)
+		if word != "" {
+			ascii = append(ascii, word)
+		}
+	}
+	return ascii
+}
@@ -0,0 +1,84 @@
+package internal_test
+
+import (
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testNameForms(t *testing.T, when spec.G, it spec.S) {
+	when("forms of a name are derived", func() {
+		it("splits words at punctuation and changes of case", func() {
+			expected := map[string]string{"env": "MY_SHOP_API", "go": "myShopApi", "docker": "my-shop-api"}
+			for _, name := range []string{"My Shop-API", "MyShopAPI", "my_shop_api"} {
+				forms := internal.NameForms(name)
+				delete(forms, "path")
+				h.AssertEq(t, forms, expected)
+			}
+		})
+
+		it("replaces characters that cannot be used in file names", func() {
+			h.AssertEq(t, internal.NameForms("shop/api: v2.")["path"], "shop-api- v2")
+		})
+
+		it("makes identifiers of names starting with a digit or keyword", func() {
+			h.AssertEq(t, internal.NameForms("2fast")["env"], "_2FAST")
+			h.AssertEq(t, internal.NameForms("2fast")["go"], "_2fast")
+			h.AssertEq(t, internal.NameForms("type")["go"], "_type")
+		})
+	})
+
+	when("a template uses name forms", func() {
+		vars := map[string]string{"ProjectName": "My Shop"}
+
+		it("renders the forms of the project name", func() {
+			rendered, err := internal.RenderString("{{ .name_forms.env }} {{ .name_forms.docker }}", vars)
+			h.AssertNil(t, err)
+			h.AssertEq(t, rendered, "MY_SHOP my-shop")
+		})
+
+		it("renders the forms of any name", func() {
+			rendered, err := internal.RenderString(`{{ (nameForms "order service").go }}`, vars)
+			h.AssertNil(t, err)
+			h.AssertEq(t, rendered, "orderService")
+		})
+
+		it("renders the forms of the project name as placeholders", func() {
+			rendered := internal.RenderPlaceholders("{{ name_forms.go }} {{ .name_forms.path }} {{ name_forms.unknown }}", vars)
+			h.AssertEq(t, rendered, "myShop My Shop {{ name_forms.unknown }}")
+		})
+	})
+
+	when("a template uses case variants", func() {
+		vars := map[string]string{"ServiceName": "Order Service", "Kind": "HTTPServer"}
+
+		it("derives the variants of every variable", func() {
+			variants := internal.CaseVariants(vars)
+			h.AssertEq(t, variants["ServiceName_snake"], "order_service")
+			h.AssertEq(t, variants["ServiceName_kebab"], "order-service")
+			h.AssertEq(t, variants["ServiceName_camel"], "orderService")
+			h.AssertEq(t, variants["ServiceName_pascal"], "OrderService")
+			h.AssertEq(t, variants["Kind_snake"], "http_server")
+		})
+
+		it("renders the variants", func() {
+			rendered, err := internal.RenderString("{{ .ServiceName_pascal }} {{ .Kind_kebab }}", vars)
+			h.AssertNil(t, err)
+			h.AssertEq(t, rendered, "OrderService http-server")
+		})
+
+		it("renders the variants as placeholders", func() {
+			rendered := internal.RenderPlaceholders("{{ ServiceName_camel }}", vars)
+			h.AssertEq(t, rendered, "orderService")
+		})
+
+		it("does not replace a variable of the same name", func() {
+			rendered, err := internal.RenderString("{{ .Kind_snake }}", map[string]string{"Kind": "HTTPServer", "Kind_snake": "custom"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, rendered, "custom")
+		})
+	})
+}
@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Schemes of the urls from which templates can be fetched
+var urlSchemes = []string{"https", "http", "ssh", "git", "file", "oci", "hg+https", "hg+http", "hg+ssh"}
+
+// Urls of folders in the web interface of well known git hosts, such as
+// https://github.com/org/repo/tree/main/go
+var webURLPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^(?P<repo>https?://(?:www\.)?github\.com/[^/]+/[^/]+?)(?:\.git)?/(?P<kind>tree|blob)/(?P<ref>[^/]+)(?:/(?P<path>.*))?$`),
+	regexp.MustCompile(`^(?P<repo>https?://[^/]+/.+?)/-/(?P<kind>tree|blob)/(?P<ref>[^/]+)(?:/(?P<path>.*))?$`),
+	regexp.MustCompile(`^(?P<repo>https?://(?:www\.)?bitbucket\.org/[^/]+/[^/]+)/(?P<kind>src)/(?P<ref>[^/]+)(?:/(?P<path>.*))?$`),
+}
+
+// Schemes written by other tools, such as the git+ssh:// of npm and pip, and
+// the scheme understood by git that each is rewritten to
+var schemeAliases = map[string]string{
+	"git+ssh":   "ssh",
+	"ssh+git":   "ssh",
+	"git+https": "https",
+	"git+http":  "http",
+	"git+file":  "file",
+}
+
+// Hosts on which a repository url names both an owner and a repository
+var repositoryHosts = regexp.MustCompile(`^https?://(?:www\.)?(github\.com|gitlab\.com|bitbucket\.org)(/.*)?$`)
+
+var (
+	// git@github.com/org/repo, where scp syntax requires a colon
+	scpWithSlash = regexp.MustCompile(`^([\w.-]+@[\w.-]+)/(.+)$`)
+	// ssh://git@github.com:org/repo, where an ssh url requires a slash
+	sshWithColon = regexp.MustCompile(`^ssh://([^/:]+):([^0-9/][^/]*/.*)$`)
+	// github.com/org/repo, without a scheme
+	missingScheme = regexp.MustCompile(`^[\w-]+(?:\.[\w-]+)+/[^/]+/.+$`)
+	// the scheme of a url, such as https://
+	urlScheme = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]*)://`)
+)
+
+// NormalizeURL checks the url of a remote template before it is fetched, so
+// that common mistakes are reported or fixed rather than surfacing as
+// confusing git errors.  Scp-style urls with a slash, ssh urls with a colon,
+// urls without a scheme and schemes such as git+ssh:// are fixed.  The url of
+// a folder in the web interface of GitHub, GitLab or Bitbucket is split into
+// the repository url, git ref and sub path.  Local paths are returned
+// unchanged.
+func NormalizeURL(url string) (string, string, string, error) {
+	if _, ok := LocalPath(url); ok {
+		return url, "", "", nil
+	}
+	if scheme := urlScheme.FindStringSubmatch(url); scheme != nil {
+		if alias, ok := schemeAliases[strings.ToLower(scheme[1])]; ok {
+			url = alias + url[len(scheme[1]):]
+		}
+	}
+
+	switch {
+	case scpWithSlash.MatchString(url):
+		url = scpWithSlash.ReplaceAllString(url, "$1:$2")
+	case sshWithColon.MatchString(url):
+		url = sshWithColon.ReplaceAllString(url, "ssh://$1/$2")
+	case missingScheme.MatchString(url) && !IsArchive(url):
+		url = "https://" + url
+	}
+
+	scheme := urlScheme.FindStringSubmatch(url)
+	if scheme == nil {
+		return url, "", "", nil
+	}
+	if !containsFold(urlSchemes, scheme[1]) {
+		return "", "", "", fmt.Errorf("unsupported scheme %s in %s; expected one of %s", scheme[1], url, strings.Join(urlSchemes, ", "))
+	}
+	if IsOCI(url) || IsMercurial(url) || IsArchive(url) {
+		return url, "", "", nil
+	}
+
+	url = strings.TrimRight(url, "/")
+	for _, pattern := range webURLPatterns {
+		match := pattern.FindStringSubmatch(url)
+		if match == nil {
+			continue
+		}
+		repo := match[pattern.SubexpIndex("repo")]
+		ref := match[pattern.SubexpIndex("ref")]
+		subPath := match[pattern.SubexpIndex("path")]
+		if match[pattern.SubexpIndex("kind")] == "blob" {
+			return "", "", "", fmt.Errorf("%s is a file rather than a template folder; use the folder containing the template, such as %s", url, suggestURL(repo, ref, path.Dir(subPath)))
+		}
+		return repo, ref, subPath, nil
+	}
+
+	if host := repositoryHosts.FindStringSubmatch(url); host != nil {
+		if len(strings.Split(strings.Trim(host[2], "/"), "/")) < 2 {
+			return "", "", "", fmt.Errorf("%s is not a repository; expected a url such as https://%s/org/repo", url, host[1])
+		}
+	}
+	return url, "", "", nil
+}
+
+// Write a repository url, git ref and sub path in the form accepted by scafall
+func suggestURL(repo string, ref string, subPath string) string {
+	suggestion := repo
+	if subPath != "" && subPath != "." {
+		suggestion += "//" + subPath
+	}
+	return suggestion + "#" + ref
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
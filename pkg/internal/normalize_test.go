@@ -0,0 +1,70 @@
+package internal_test
+
+import (
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testNormalizeURL(t *testing.T, when spec.G, it spec.S) {
+	type TestCase struct {
+		url     string
+		repo    string
+		ref     string
+		subPath string
+	}
+	testCases := []TestCase{
+		{"https://github.com/org/repo", "https://github.com/org/repo", "", ""},
+		{"https://github.com/org/repo.git/", "https://github.com/org/repo.git", "", ""},
+		{"git@github.com/org/repo.git", "git@github.com:org/repo.git", "", ""},
+		{"git@github.com:org/repo.git", "git@github.com:org/repo.git", "", ""},
+		{"ssh://git@github.com:org/repo.git", "ssh://git@github.com/org/repo.git", "", ""},
+		{"ssh://git@example.com:2222/org/repo.git", "ssh://git@example.com:2222/org/repo.git", "", ""},
+		{"git+ssh://git@github.com/org/repo.git", "ssh://git@github.com/org/repo.git", "", ""},
+		{"ssh+git://git@github.com/org/repo.git", "ssh://git@github.com/org/repo.git", "", ""},
+		{"git+ssh://git@github.com:org/repo.git", "ssh://git@github.com/org/repo.git", "", ""},
+		{"git+https://github.com/org/repo.git", "https://github.com/org/repo.git", "", ""},
+		{"github.com/org/repo", "https://github.com/org/repo", "", ""},
+		{"https://github.com/org/repo/tree/main/go/cli", "https://github.com/org/repo", "main", "go/cli"},
+		{"https://github.com/org/repo/tree/v1.0.0", "https://github.com/org/repo", "v1.0.0", ""},
+		{"https://gitlab.com/group/sub/repo/-/tree/main/template", "https://gitlab.com/group/sub/repo", "main", "template"},
+		{"https://bitbucket.org/org/repo/src/main/template/", "https://bitbucket.org/org/repo", "main", "template"},
+		{"oci://ghcr.io/org/template:v1", "oci://ghcr.io/org/template:v1", "", ""},
+		{"https://example.com/template.tar.gz", "https://example.com/template.tar.gz", "", ""},
+	}
+	for _, testCase := range testCases {
+		current := testCase
+		when("a url is normalized", func() {
+			it("fixes the url or splits the web url "+current.url, func() {
+				repo, ref, subPath, err := internal.NormalizeURL(current.url)
+				h.AssertNil(t, err)
+				h.AssertEq(t, repo, current.repo)
+				h.AssertEq(t, ref, current.ref)
+				h.AssertEq(t, subPath, current.subPath)
+			})
+		})
+	}
+
+	when("a url cannot be fixed", func() {
+		it("suggests the folder of a file url", func() {
+			_, _, _, err := internal.NormalizeURL("https://github.com/org/repo/blob/main/go/cli/main.go")
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "https://github.com/org/repo//go/cli#main")
+		})
+
+		it("reports a url that is not a repository", func() {
+			_, _, _, err := internal.NormalizeURL("https://github.com/org")
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "https://github.com/org/repo")
+		})
+
+		it("reports an unknown scheme", func() {
+			_, _, _, err := internal.NormalizeURL("htps://github.com/org/repo")
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "unsupported scheme htps")
+		})
+	})
+}
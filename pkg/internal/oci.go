@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+const OCIScheme string = "oci://"
+
+// IsOCI reports whether url points to a template in an OCI registry
+func IsOCI(url string) bool {
+	return strings.HasPrefix(url, OCIScheme)
+}
+
+// Pull a template packaged as an OCI artifact and extract each of its layers
+// into tmpDir.  Registry credentials are read from the docker config file.
+func fetchOCI(url string, tmpDir string, opts FetchOptions) error {
+	ref, err := name.ParseReference(strings.TrimPrefix(url, OCIScheme))
+	if err != nil {
+		return fmt.Errorf("invalid OCI reference %s: %s", url, err)
+	}
+	client, err := newHTTPClient(opts)
+	if err != nil {
+		return err
+	}
+	image, err := remote.Image(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithTransport(client.Transport))
+	if err != nil {
+		return fmt.Errorf("failed to pull %s: %s", url, err)
+	}
+	layers, err := image.Layers()
+	if err != nil {
+		return err
+	}
+
+	remaining := MaxArchiveSize
+	for i, layer := range layers {
+		reportProgress(url, opts, "Pulling layers", i*100/len(layers))
+		r, err := layer.Uncompressed()
+		if err != nil {
+			return err
+		}
+		err = extractTar(r, tmpDir, &remaining, opts)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("failed to extract layer of %s: %s", url, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package internal_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testOCI(t *testing.T, when spec.G, it spec.S) {
+	var (
+		server *httptest.Server
+		host   string
+		tmpDir string
+	)
+
+	it.Before(func() {
+		server = httptest.NewServer(registry.New())
+		host = strings.TrimPrefix(server.URL, "http://")
+		tmpDir, _ = os.MkdirTemp("", "test")
+
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		content := "{{.Foo}}"
+		tw.WriteHeader(&tar.Header{Name: "{{.Foo}}.txt", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+		tw.Close()
+
+		layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(buf.Bytes())), nil
+		})
+		h.AssertNil(t, err)
+		image, err := mutate.AppendLayers(empty.Image, layer)
+		h.AssertNil(t, err)
+		ref, err := name.ParseReference(host + "/template:v1")
+		h.AssertNil(t, err)
+		h.AssertNil(t, remote.Write(ref, image))
+	})
+
+	it.After(func() {
+		server.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	when("the url is an OCI reference", func() {
+		it("extracts the layers of the artifact", func() {
+			root, err := internal.URLToFs(internal.OCIScheme+host+"/template:v1", tmpDir, internal.FetchOptions{})
+			h.AssertNil(t, err)
+
+			content, err := internal.ReadFile(filepath.Join(root, "{{.Foo}}.txt"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, content, "{{.Foo}}")
+		})
+
+		it("fails for an unknown tag", func() {
+			_, err := internal.URLToFs(internal.OCIScheme+host+"/template:v2", tmpDir, internal.FetchOptions{})
+			h.AssertNotNil(t, err)
+		})
+	})
+}
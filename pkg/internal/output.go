@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var unsafeFolderCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SanitizeFolderName replaces characters that are unsafe in folder names
+func SanitizeFolderName(name string) string {
+	sanitized := unsafeFolderCharacters.ReplaceAllString(strings.TrimSpace(name), "-")
+	return strings.Trim(sanitized, "-.")
+}
+
+// DefaultOutputFolder names the output folder after the project name, when
+// the template has a project name variable, otherwise after the template url
+func DefaultOutputFolder(values map[string]string, url string) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, alias := range factPrompts[ProjectNameFact] {
+		for _, name := range names {
+			if strings.EqualFold(alias, name) {
+				if folder := SanitizeFolderName(values[name]); folder != "" {
+					return folder
+				}
+			}
+		}
+	}
+
+	url, _ = SplitRef(url)
+	url, subPath := SplitSubPath(url)
+	name := path.Base(strings.TrimRight(ExpandURL(url), "/"))
+	if subPath != "" {
+		name = path.Base(subPath)
+	}
+	for _, suffix := range append([]string{".git"}, archiveSuffixes...) {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	if i := strings.IndexAny(name, ":@"); i > 0 {
+		name = name[:i]
+	}
+	if folder := SanitizeFolderName(name); folder != "" {
+		return folder
+	}
+	return "."
+}
@@ -0,0 +1,36 @@
+package internal_test
+
+import (
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testDefaultOutputFolder(t *testing.T, when spec.G, it spec.S) {
+	type TestCase struct {
+		values   map[string]string
+		url      string
+		expected string
+	}
+	testCases := []TestCase{
+		{map[string]string{"ProjectName": "My Project!"}, "https://github.com/org/repo", "My-Project"},
+		{map[string]string{"name": "../../etc"}, "https://github.com/org/repo", "etc"},
+		{map[string]string{"Foo": "bar"}, "https://github.com/org/python-eg.git#v1.0.0", "python-eg"},
+		{map[string]string{}, "gh:org/repo", "repo"},
+		{map[string]string{}, "https://github.com/org/templates//go/cli#v1", "cli"},
+		{map[string]string{}, "https://example.com/template-v1.0.0.tar.gz", "template-v1.0.0"},
+		{map[string]string{}, "oci://ghcr.io/org/template:v1", "template"},
+		{map[string]string{}, "", "."},
+	}
+	for _, testCase := range testCases {
+		current := testCase
+		when("no output folder is provided", func() {
+			it("names the output folder after the project", func() {
+				h.AssertEq(t, internal.DefaultOutputFolder(current.values, current.url), current.expected)
+			})
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The existence required of the answer to a path prompt
+const (
+	// ExistingFile requires a path to name an existing file
+	ExistingFile string = "file"
+	// ExistingDirectory requires a path to name an existing directory
+	ExistingDirectory string = "directory"
+	// ExistingPath requires a path to name an existing file or directory
+	ExistingPath string = "any"
+	// NewPath requires that nothing exists at a path
+	NewPath string = "none"
+)
+
+var PathExistence = []string{ExistingFile, ExistingDirectory, ExistingPath, NewPath}
+
+// Descriptions of the existence required of a path, as listed in answers
+// files
+var pathExistenceDetails = map[string]string{
+	ExistingFile:      "must be an existing file",
+	ExistingDirectory: "must be an existing directory",
+	ExistingPath:      "must exist",
+	NewPath:           "must not exist",
+}
+
+// Trim space around a path and expand a leading ~ to the home directory of
+// the end-user
+func normalizePath(value string) string {
+	path := strings.TrimSpace(value)
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return home + path[1:]
+}
+
+// CheckPath reports the answer to a path prompt that does not exist, or that
+// exists, as required by the prompt.  Relative paths are found from the
+// working directory.  Empty values are left to the required check.
+func CheckPath(prompt Prompt, value string) error {
+	if prompt.Type != PathType || prompt.Exists == "" || value == "" {
+		return nil
+	}
+	info, err := os.Stat(value)
+	switch {
+	case prompt.Exists == NewPath && err == nil:
+		return fmt.Errorf("%s already exists", value)
+	case prompt.Exists == NewPath && os.IsNotExist(err):
+		return nil
+	case os.IsNotExist(err):
+		return fmt.Errorf("%s does not exist", value)
+	case err != nil:
+		return err
+	case prompt.Exists == ExistingFile && info.IsDir():
+		return fmt.Errorf("%s is a directory rather than a file", value)
+	case prompt.Exists == ExistingDirectory && !info.IsDir():
+		return fmt.Errorf("%s is a file rather than a directory", value)
+	}
+	return nil
+}
+
+// Complete a partly typed path with the names of the files and directories
+// it may name, directories are completed with a trailing separator so that
+// completion can continue within them.  Files are not offered when the
+// prompt requires a directory.
+func suggestPaths(prompt Prompt) func(string) []string {
+	return func(toComplete string) []string {
+		// suggestions keep the folder as typed, so a leading ~ is kept
+		prefix, base := filepath.Split(strings.TrimSpace(toComplete))
+		dir := normalizePath(prefix)
+		if dir == "" {
+			dir = "."
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil
+		}
+		suggestions := []string{}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+				continue
+			}
+			switch {
+			case entry.IsDir():
+				suggestions = append(suggestions, prefix+name+string(filepath.Separator))
+			case prompt.Exists != ExistingDirectory:
+				suggestions = append(suggestions, prefix+name)
+			}
+		}
+		return suggestions
+	}
+}
@@ -0,0 +1,135 @@
+package internal_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testPaths(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir    string
+		file      string
+		directory string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+		file = filepath.Join(tmpDir, "schema.json")
+		directory = filepath.Join(tmpDir, "schemas")
+		h.AssertNil(t, os.WriteFile(file, []byte("{}"), 0600))
+		h.AssertNil(t, os.Mkdir(directory, 0700))
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	pathPrompt := func(exists string) internal.Prompt {
+		return internal.Prompt{Name: "Schema", Prompt: "Schema file", Type: internal.PathType, Exists: exists}
+	}
+
+	when("a path must exist", func() {
+		it("accepts a path of the required kind", func() {
+			h.AssertNil(t, internal.CheckPath(pathPrompt(internal.ExistingFile), file))
+			h.AssertNil(t, internal.CheckPath(pathPrompt(internal.ExistingDirectory), directory))
+			h.AssertNil(t, internal.CheckPath(pathPrompt(internal.ExistingPath), file))
+			h.AssertNil(t, internal.CheckPath(pathPrompt(internal.ExistingPath), directory))
+		})
+
+		it("reports a missing path", func() {
+			err := internal.CheckPath(pathPrompt(internal.ExistingFile), filepath.Join(tmpDir, "missing.json"))
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "missing.json does not exist")
+		})
+
+		it("reports a path of the wrong kind", func() {
+			err := internal.CheckPath(pathPrompt(internal.ExistingFile), directory)
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "is a directory rather than a file")
+			err = internal.CheckPath(pathPrompt(internal.ExistingDirectory), file)
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "is a file rather than a directory")
+		})
+	})
+
+	when("a path must not exist", func() {
+		it("reports an existing path", func() {
+			h.AssertNil(t, internal.CheckPath(pathPrompt(internal.NewPath), filepath.Join(tmpDir, "new.json")))
+			err := internal.CheckPath(pathPrompt(internal.NewPath), file)
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "schema.json already exists")
+		})
+	})
+
+	when("a path begins with ~", func() {
+		it("is normalized from the home directory", func() {
+			home, err := os.UserHomeDir()
+			h.AssertNil(t, err)
+			value, err := internal.Normalize(pathPrompt(""), " ~/schema.json ", internal.CurrentLocale())
+			h.AssertNil(t, err)
+			h.AssertEq(t, value, home+"/schema.json")
+		})
+	})
+
+	when("a partly typed path is completed", func() {
+		it("suggests the files and directories it may name", func() {
+			question := internal.NewQuestion(pathPrompt(internal.ExistingFile))
+			input, ok := question.Prompt.(*survey.Input)
+			h.AssertTrue(t, ok)
+			h.AssertEq(t, input.Suggest(filepath.Join(tmpDir, "sch")), []string{file, directory + string(filepath.Separator)})
+		})
+
+		it("ignores space around the typed path", func() {
+			question := internal.NewQuestion(pathPrompt(internal.ExistingFile))
+			input := question.Prompt.(*survey.Input)
+			h.AssertEq(t, input.Suggest(" "+filepath.Join(tmpDir, "sch")+" "), []string{file, directory + string(filepath.Separator)})
+			h.AssertNil(t, os.WriteFile(filepath.Join(directory, "orders.json"), []byte("{}"), 0600))
+			h.AssertEq(t, input.Suggest(directory+string(filepath.Separator)+" "), []string{filepath.Join(directory, "orders.json")})
+		})
+
+		it("suggests only directories when a directory is required", func() {
+			question := internal.NewQuestion(pathPrompt(internal.ExistingDirectory))
+			input := question.Prompt.(*survey.Input)
+			h.AssertEq(t, input.Suggest(filepath.Join(tmpDir, "sch")), []string{directory + string(filepath.Separator)})
+		})
+	})
+
+	when("an answer to a path prompt does not exist", func() {
+		it("is listed with the other invalid answers", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(`[[prompt]]
+name = "Schema"
+prompt = "Schema file"
+type = "path"
+exists = "file"
+`)), nil, nil)
+			h.AssertNil(t, err)
+			_, err = template.Answer(map[string]interface{}{"Schema": filepath.Join(tmpDir, "missing.json")})
+			var answerErr internal.AnswerError
+			h.AssertTrue(t, errors.As(err, &answerErr))
+			h.AssertContains(t, answerErr.Problems[0], "missing.json does not exist")
+		})
+	})
+
+	when("a prompt that is not a path requires a path to exist", func() {
+		it("is reported", func() {
+			_, err := internal.NewTemplate(io.NopCloser(strings.NewReader(`[[prompt]]
+name = "Schema"
+prompt = "Schema file"
+exists = "file"
+`)), nil, nil)
+			var fileErr internal.PromptFileError
+			h.AssertTrue(t, errors.As(err, &fileErr))
+			h.AssertEq(t, fileErr.Problems[0].Message, "prompt Schema is not a path and cannot require a path to exist")
+		})
+	})
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// Plan records the template and the values of all template variables so that
+// a project can be created later without prompting
+type Plan struct {
+	URL       string            `toml:"url"`
+	Ref       string            `toml:"ref,omitempty"`
+	SubPath   string            `toml:"sub-path,omitempty"`
+	Template  string            `toml:"template,omitempty"`
+	Digest    string            `toml:"digest"`
+	Variables map[string]string `toml:"variables"`
+}
+
+func WritePlan(plan Plan, planFile string) error {
+	f, err := os.OpenFile(planFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return toml.NewEncoder(f).Encode(plan)
+}
+
+func ReadPlan(planFile string) (Plan, error) {
+	plan := Plan{}
+	planData, err := ReadFile(planFile)
+	if err != nil {
+		return plan, err
+	}
+
+	if _, err := toml.Decode(planData, &plan); err != nil {
+		return plan, errors.Wrap(err, fmt.Sprintf("%s file does not match required format", planFile))
+	}
+	if plan.URL == "" || plan.Digest == "" {
+		return plan, fmt.Errorf("%s file is missing required field; url and digest required", planFile)
+	}
+	if plan.Variables == nil {
+		plan.Variables = map[string]string{}
+	}
+	return plan, nil
+}
@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"fmt"
+	neturl "net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/scafall/pkg/internal/util"
+)
+
+// Policy restricts the templates that may be used.  Patterns match the host
+// and path of a template url, such as github.com/acme/*, or any leading part
+// of it, such as github.com/acme.  A url matching a deny pattern is refused,
+// when allow patterns are given a url must match one of them.  Templates
+// requiring a disabled capability, or calling a refused function, are
+// refused.
+type Policy struct {
+	Allow     []string       `toml:"allow"`
+	Deny      []string       `toml:"deny"`
+	Disable   []string       `toml:"disable"`
+	Functions FunctionPolicy `toml:"functions"`
+}
+
+// ReadPolicy reads the Policy in policyFile, a missing file is a Policy that
+// allows every template
+func ReadPolicy(policyFile string) (Policy, error) {
+	policy := Policy{}
+	if _, err := os.Stat(policyFile); err != nil {
+		return policy, nil
+	}
+	policyData, err := ReadFile(policyFile)
+	if err != nil {
+		return policy, err
+	}
+
+	if _, err := toml.Decode(policyData, &policy); err != nil {
+		return policy, errors.Wrap(err, fmt.Sprintf("%s file does not match required format", policyFile))
+	}
+	for _, pattern := range append(policy.Allow, policy.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return policy, fmt.Errorf("%s file contains invalid pattern %s", policyFile, pattern)
+		}
+	}
+	for _, capability := range policy.Disable {
+		if !util.Contains(Capabilities, capability) {
+			return policy, fmt.Errorf("%s file disables unknown capability %s; expected one of %s", policyFile, capability, strings.Join(Capabilities, ", "))
+		}
+	}
+	return policy, nil
+}
+
+// Check that the template at url may be used
+func (p Policy) Check(url string) error {
+	if IsOCI(url) && util.Contains(p.Disable, OCISourceCapability) {
+		return fmt.Errorf("template %s is an OCI artifact and %s is disabled by policy", url, OCISourceCapability)
+	}
+	if IsArchive(url) && util.Contains(p.Disable, ArchiveSourceCapability) {
+		return fmt.Errorf("template %s is an archive and %s is disabled by policy", url, ArchiveSourceCapability)
+	}
+	location := policyLocation(url)
+	for _, pattern := range p.Deny {
+		if matchLocation(pattern, location) {
+			return fmt.Errorf("template %s is denied by policy pattern %s", url, pattern)
+		}
+	}
+	if len(p.Allow) == 0 {
+		return nil
+	}
+	for _, pattern := range p.Allow {
+		if matchLocation(pattern, location) {
+			return nil
+		}
+	}
+	return fmt.Errorf("template %s is not allowed by policy; allowed templates match %s", url, strings.Join(p.Allow, ", "))
+}
+
+// Reduce a url to the host and path of the template, such as
+// github.com/acme/template, whether it is given as an HTTPS, SSH, scp-like or
+// OCI url
+func policyLocation(url string) string {
+	location := strings.TrimPrefix(url, OCIScheme)
+	if u, err := neturl.Parse(location); err == nil && u.Host != "" {
+		location = u.Host + u.Path
+	} else if i := strings.Index(location, ":"); i >= 0 && !strings.Contains(location[:i], "/") {
+		// scp-like urls, such as git@github.com:acme/template.git
+		location = location[:i] + "/" + location[i+1:]
+	}
+	if i := strings.Index(location, "@"); i >= 0 && !strings.Contains(location[:i], "/") {
+		location = location[i+1:]
+	}
+	location = strings.TrimSuffix(strings.TrimSuffix(location, "/"), ".git")
+	return strings.ToLower(location)
+}
+
+// A pattern matches the location or any leading part of its path
+func matchLocation(pattern string, location string) bool {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "/"))
+	segments := strings.Split(location, "/")
+	for i := range segments {
+		if matched, _ := path.Match(pattern, strings.Join(segments[:i+1], "/")); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testPolicy(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("no policy file exists", func() {
+		it("allows every template", func() {
+			policy, err := internal.ReadPolicy(filepath.Join(tmpDir, "policy.toml"))
+			h.AssertNil(t, err)
+			h.AssertNil(t, policy.Check("https://github.com/acme/template"))
+		})
+	})
+
+	when("a policy file exists", func() {
+		it("reads the allow and deny patterns", func() {
+			policyFile := filepath.Join(tmpDir, "policy.toml")
+			err := os.WriteFile(policyFile, []byte(`
+allow = ["github.com/acme/*"]
+deny = ["github.com/acme/legacy"]
+`), 0600)
+			h.AssertNil(t, err)
+
+			policy, err := internal.ReadPolicy(policyFile)
+			h.AssertNil(t, err)
+			h.AssertEq(t, policy.Allow, []string{"github.com/acme/*"})
+			h.AssertEq(t, policy.Deny, []string{"github.com/acme/legacy"})
+		})
+
+		it("fails for an invalid pattern", func() {
+			policyFile := filepath.Join(tmpDir, "policy.toml")
+			err := os.WriteFile(policyFile, []byte(`allow = ["github.com/[acme"]`), 0600)
+			h.AssertNil(t, err)
+
+			_, err = internal.ReadPolicy(policyFile)
+			h.AssertNotNil(t, err)
+		})
+	})
+
+	when("templates are allowed", func() {
+		policy := internal.Policy{Allow: []string{"github.com/acme/*", "ghcr.io/acme"}}
+
+		it("allows matching urls in any form", func() {
+			for _, url := range []string{
+				"https://github.com/acme/template.git",
+				"https://GitHub.com/Acme/template",
+				"git@github.com:acme/template.git",
+				"ssh://git@github.com/acme/template",
+				"oci://ghcr.io/acme/template:v1",
+			} {
+				h.AssertNil(t, policy.Check(url))
+			}
+		})
+
+		it("refuses other urls", func() {
+			h.AssertNotNil(t, policy.Check("https://github.com/other/template"))
+			h.AssertNotNil(t, policy.Check("https://gitlab.com/acme/template"))
+		})
+	})
+
+	when("templates are denied", func() {
+		it("refuses matching urls even when they are allowed", func() {
+			policy := internal.Policy{Allow: []string{"github.com/acme"}, Deny: []string{"github.com/acme/legacy-*"}}
+			h.AssertNil(t, policy.Check("https://github.com/acme/template"))
+			h.AssertNotNil(t, policy.Check("https://github.com/acme/legacy-template"))
+		})
+	})
+}
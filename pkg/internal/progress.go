@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FetchEvent reports progress while a template is fetched
+type FetchEvent struct {
+	// URL of the template being fetched
+	URL string
+	// Stage describes what is being done, such as "Receiving objects"
+	Stage string
+	// Percent of the stage that is complete, or -1 when unknown
+	Percent int
+	// Done is true once the template has been fetched
+	Done bool
+}
+
+var percentPattern = regexp.MustCompile(`(\d+)%`)
+
+// Report an event to the progress callback of opts, if there is one
+func reportProgress(url string, opts FetchOptions, stage string, percent int) {
+	if opts.Progress != nil {
+		opts.Progress(FetchEvent{URL: url, Stage: stage, Percent: percent})
+	}
+}
+
+// progressWriter turns the progress lines written by git, such as
+// "Receiving objects:  42% (21/50)", into FetchEvents
+type progressWriter struct {
+	url      string
+	progress func(FetchEvent)
+	line     []byte
+}
+
+// Create a writer for git progress, or nil when progress is not reported
+func newProgressWriter(url string, opts FetchOptions) io.Writer {
+	if opts.Progress == nil {
+		return nil
+	}
+	return &progressWriter{url: url, progress: opts.Progress}
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.line = append(w.line, p...)
+	for {
+		// git rewrites a line in place using a carriage return
+		i := bytes.IndexAny(w.line, "\r\n")
+		if i < 0 {
+			return len(p), nil
+		}
+		w.report(string(w.line[:i]))
+		w.line = w.line[i+1:]
+	}
+}
+
+func (w *progressWriter) report(line string) {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "remote:"))
+	if line == "" {
+		return
+	}
+	stage, percent := line, -1
+	if i := strings.Index(line, ":"); i >= 0 {
+		stage = line[:i]
+		if match := percentPattern.FindStringSubmatch(line[i:]); match != nil {
+			percent, _ = strconv.Atoi(match[1])
+		}
+	}
+	w.progress(FetchEvent{URL: w.url, Stage: stage, Percent: percent})
+}
+
+// progressReader reports the percentage of a download that has been read
+type progressReader struct {
+	io.Reader
+	url     string
+	opts    FetchOptions
+	size    int64
+	read    int64
+	percent int
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+	if r.size > 0 {
+		if percent := int(r.read * 100 / r.size); percent != r.percent {
+			r.percent = percent
+			reportProgress(r.url, r.opts, "Downloading", percent)
+		}
+	}
+	return n, err
+}
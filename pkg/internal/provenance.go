@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"fmt"
+	neturl "net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TemplateURLVariable and TemplateRefVariable describe the template to
+	// the provenance section
+	TemplateURLVariable string = ReservedPrefix + "TemplateURL"
+	TemplateRefVariable string = ReservedPrefix + "TemplateRef"
+
+	// DefaultProvenanceFile is the file to which the provenance section is
+	// appended unless another file is named
+	DefaultProvenanceFile string = "README.md"
+	// DefaultProvenanceTemplate is the provenance section unless the template
+	// provides its own
+	DefaultProvenanceTemplate string = "## Provenance\n\nGenerated by [scafall](https://github.com/buildpacks/scafall) from {{.__TemplateURL}} at {{.__TemplateRef}}.\n"
+
+	provenanceStart = "<!-- scafall:provenance -->"
+	provenanceEnd   = "<!-- /scafall:provenance -->"
+)
+
+// Provenance describes a section, recording the template from which a project
+// was generated, that is appended to a file of the output project
+type Provenance struct {
+	// File is the output file to which the section is appended, it is
+	// created when it does not exist
+	File string `toml:"file,omitempty"`
+	// Template is the content of the section, which may use template
+	// variables as well as TemplateURLVariable and TemplateRefVariable
+	Template string `toml:"template,omitempty"`
+	// When is a condition on the answers to prompts, the section is only
+	// written when the condition is true
+	When string `toml:"when,omitempty"`
+}
+
+// WriteProvenance appends the provenance section to its file in outputDir.
+// The section is marked, so that scaffolding the same project again replaces
+// the section rather than appending another.  Credentials in the url of the
+// template are not written to the project.
+func WriteProvenance(outputDir string, provenance Provenance, vars map[string]string, engine string, url string, ref string) error {
+	if provenance.When != "" {
+		applies, err := EvalCondition(provenance.When, vars)
+		if err != nil {
+			return errors.Wrap(err, "invalid condition for provenance")
+		}
+		if !applies {
+			return nil
+		}
+	}
+
+	file := provenance.File
+	if file == "" {
+		file = DefaultProvenanceFile
+	}
+	if isOutside(path.Clean(filepath.ToSlash(file))) {
+		return fmt.Errorf("provenance file %s is outside of the output folder", file)
+	}
+	content := provenance.Template
+	if content == "" {
+		content = DefaultProvenanceTemplate
+	}
+
+	provenanceVars := map[string]string{TemplateURLVariable: withoutCredentials(url), TemplateRefVariable: ref}
+	for name, value := range vars {
+		provenanceVars[name] = value
+	}
+	section, err := Render(engine, content, provenanceVars)
+	if err != nil {
+		return errors.Wrap(err, "failed to render provenance")
+	}
+	section = provenanceStart + "\n" + strings.TrimRight(section, "\n") + "\n" + provenanceEnd + "\n"
+
+	target := filepath.Join(outputDir, file)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(target)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(target, []byte(replaceProvenance(string(existing), section)), 0644)
+}
+
+// Replace the marked provenance section of content, or append the section
+// when content has none
+func replaceProvenance(content string, section string) string {
+	start := strings.Index(content, provenanceStart)
+	end := strings.Index(content, provenanceEnd)
+	if start >= 0 && end > start {
+		rest := strings.TrimPrefix(content[end+len(provenanceEnd):], "\n")
+		return content[:start] + section + rest
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + section
+}
+
+// Remove the user information, which may hold an access token or password,
+// from url.  The user of an ssh url without a password is kept, as it is
+// needed to fetch the template again.
+func withoutCredentials(url string) string {
+	parsed, err := neturl.Parse(url)
+	if err != nil || parsed.User == nil {
+		return url
+	}
+	if _, hasPassword := parsed.User.Password(); !hasPassword && parsed.Scheme == "ssh" {
+		return url
+	}
+	parsed.User = nil
+	return parsed.String()
+}
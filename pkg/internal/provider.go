@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	EnvProviderName   string = "env"
+	FileProviderName  string = "file"
+	VaultProviderName string = "vault"
+
+	// defaultVaultField is read from a vault secret when a reference names
+	// no field
+	defaultVaultField = "value"
+)
+
+// ValueProvider resolves the key of a provider:key reference to a value, such
+// as a secret read from a secret store
+type ValueProvider interface {
+	Resolve(key string) (string, error)
+}
+
+// ValueProviders are keyed by the name used in references.  A value of the
+// form name:key, where name is a registered provider, is replaced by the
+// value resolved by the provider; other values are unchanged.
+type ValueProviders map[string]ValueProvider
+
+// ResolveValue resolves value when it is a reference to a registered provider
+func (p ValueProviders) ResolveValue(value string) (string, error) {
+	i := strings.Index(value, ":")
+	if i < 0 {
+		return value, nil
+	}
+	provider, ok := p[value[:i]]
+	if !ok {
+		return value, nil
+	}
+	resolved, err := provider.Resolve(value[i+1:])
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %s", value, err)
+	}
+	return resolved, nil
+}
+
+// ResolveValues returns a copy of values with every reference resolved
+func (p ValueProviders) ResolveValues(values map[string]string) (map[string]string, error) {
+	if len(p) == 0 || values == nil {
+		return values, nil
+	}
+	resolved := make(map[string]string, len(values))
+	for name, value := range values {
+		v, err := p.ResolveValue(value)
+		if err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+		resolved[name] = v
+	}
+	return resolved, nil
+}
+
+// ResolveAnswers returns a copy of answers with every string answer that is
+// a reference resolved, other answers are unchanged
+func (p ValueProviders) ResolveAnswers(answers map[string]interface{}) (map[string]interface{}, error) {
+	if len(p) == 0 || answers == nil {
+		return answers, nil
+	}
+	resolved := make(map[string]interface{}, len(answers))
+	for name, answer := range answers {
+		resolved[name] = answer
+		if value, ok := answer.(string); ok {
+			v, err := p.ResolveValue(value)
+			if err != nil {
+				return nil, errors.Wrap(err, name)
+			}
+			resolved[name] = v
+		}
+	}
+	return resolved, nil
+}
+
+// EnvProvider resolves env:NAME to the value of the environment variable NAME
+type EnvProvider struct{}
+
+func (EnvProvider) Resolve(key string) (string, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", key)
+	}
+	return value, nil
+}
+
+// FileProvider resolves file:PATH to the content of the file at PATH, without
+// a trailing newline
+type FileProvider struct{}
+
+func (FileProvider) Resolve(key string) (string, error) {
+	content, err := os.ReadFile(key)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// VaultProvider resolves vault:PATH#FIELD to a field of the secret at PATH,
+// such as secret/data/myapp#password, read from a HashiCorp Vault server.
+// Both version 1 and version 2 key/value secrets are read; the field defaults
+// to value.
+type VaultProvider struct {
+	// Address and Token default to VAULT_ADDR and VAULT_TOKEN
+	Address string
+	Token   string
+	Client  *http.Client
+}
+
+func (v VaultProvider) Resolve(key string) (string, error) {
+	address, token := v.Address, v.Token
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	if address == "" {
+		return "", fmt.Errorf("no vault address; set VAULT_ADDR")
+	}
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	secretPath, field := key, defaultVaultField
+	if i := strings.LastIndex(key, "#"); i >= 0 {
+		secretPath, field = key[:i], key[i+1:]
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(address, "/")+"/v1/"+strings.TrimLeft(secretPath, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s for %s", resp.Status, secretPath)
+	}
+
+	secret := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("vault returned an unreadable secret for %s: %s", secretPath, err)
+	}
+	data := secret.Data
+	// version 2 secrets nest their data beside metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no field %s", secretPath, field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// DefaultValueProviders returns the built in providers, keyed by name
+func DefaultValueProviders() ValueProviders {
+	return ValueProviders{
+		EnvProviderName:   EnvProvider{},
+		FileProviderName:  FileProvider{},
+		VaultProviderName: VaultProvider{},
+	}
+}
@@ -0,0 +1,152 @@
+package internal_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+type constantProvider map[string]string
+
+func (c constantProvider) Resolve(key string) (string, error) {
+	return c[key], nil
+}
+
+func testValueProviders(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir    string
+		providers internal.ValueProviders
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+		providers = internal.ValueProviders{
+			"org": constantProvider{"owner": "platform-team"},
+			"env": internal.EnvProvider{},
+		}
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a value references a registered provider", func() {
+		it("is resolved by the provider", func() {
+			value, err := providers.ResolveValue("org:owner")
+			h.AssertNil(t, err)
+			h.AssertEq(t, value, "platform-team")
+		})
+	})
+
+	when("a value does not reference a registered provider", func() {
+		it("is unchanged", func() {
+			value, err := providers.ResolveValue("https://example.com")
+			h.AssertNil(t, err)
+			h.AssertEq(t, value, "https://example.com")
+		})
+	})
+
+	when("a provider cannot resolve a reference", func() {
+		it("names the variable and the reference", func() {
+			_, err := providers.ResolveValues(map[string]string{"Token": "env:SCAFALL_TEST_UNSET"})
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "Token: failed to resolve env:SCAFALL_TEST_UNSET")
+		})
+	})
+
+	when("a file is referenced", func() {
+		it("resolves to the content of the file", func() {
+			secret := filepath.Join(tmpDir, "secret")
+			h.AssertNil(t, os.WriteFile(secret, []byte("s3cr3t\n"), 0600))
+			value, err := internal.FileProvider{}.Resolve(secret)
+			h.AssertNil(t, err)
+			h.AssertEq(t, value, "s3cr3t")
+		})
+	})
+
+	when("a vault secret is referenced", func() {
+		var server *httptest.Server
+
+		it.Before(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Vault-Token") != "root" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				switch r.URL.Path {
+				case "/v1/secret/data/app":
+					w.Write([]byte(`{"data": {"data": {"password": "hunter2"}, "metadata": {"version": 1}}}`))
+				case "/v1/kv/app":
+					w.Write([]byte(`{"data": {"value": "v1-secret"}}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("reads a field of a version 2 secret", func() {
+			value, err := internal.VaultProvider{Address: server.URL, Token: "root"}.Resolve("secret/data/app#password")
+			h.AssertNil(t, err)
+			h.AssertEq(t, value, "hunter2")
+		})
+
+		it("reads the value field of a version 1 secret", func() {
+			value, err := internal.VaultProvider{Address: server.URL, Token: "root"}.Resolve("kv/app")
+			h.AssertNil(t, err)
+			h.AssertEq(t, value, "v1-secret")
+		})
+
+		it("reports a missing field", func() {
+			_, err := internal.VaultProvider{Address: server.URL, Token: "root"}.Resolve("secret/data/app#user")
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "has no field user")
+		})
+
+		it("reports a rejected token", func() {
+			_, err := internal.VaultProvider{Address: server.URL, Token: "wrong"}.Resolve("kv/app")
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "403")
+		})
+	})
+
+	when("overrides and answers reference providers", func() {
+		it("resolves them before the values are used", func() {
+			prompts := `[[prompt]]
+name = "Owner"
+prompt = "Owning team"
+
+[[prompt]]
+name = "Maintainer"
+prompt = "Maintainer"
+`
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, internal.PromptFile), []byte(prompts), 0644))
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, internal.OverrideFile), []byte(`Owner = "org:owner"`), 0644))
+
+			values, err := internal.DefaultValues(tmpDir, nil, map[string]interface{}{"Maintainer": "org:owner"}, providers, nil, internal.ChoiceMatching{}, nil, nil)
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["Owner"], "platform-team")
+			h.AssertEq(t, values["Maintainer"], "platform-team")
+		})
+
+		it("leaves references unresolved without providers", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader("")), map[string]string{"Owner": "org:owner"}, nil)
+			h.AssertNil(t, err)
+			values, err := template.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["Owner"], "org:owner")
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+
+	t "github.com/coveooss/gotemplate/v3/template"
+)
+
+// SeedVariable holds the seed of the random functions of templates, when they
+// are seeded, so that a project is rendered with the same random values each
+// time
+const SeedVariable string = ReservedPrefix + "Seed"
+
+const (
+	alphaCharacters   = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	numericCharacters = "0123456789"
+)
+
+// Replace the random functions of template with functions seeded by the
+// SeedVariable of vars, when there is one.  Each key, such as the path of a
+// file, draws its own sequence so that files do not share random values and
+// a file renders the same however many files are rendered before it.
+func seedFunctions(template *t.Template, vars map[string]string, key string) error {
+	value, ok := vars[SeedVariable]
+	if !ok {
+		return nil
+	}
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("seed %s is not a whole number", value)
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	random := rand.New(rand.NewSource(seed ^ int64(hash.Sum64())))
+	addFunctions(template, randomFunctions(random))
+	return nil
+}
+
+// The random functions of sprig drawing from random
+func randomFunctions(random *rand.Rand) map[string]interface{} {
+	randString := func(characters string, count int) string {
+		b := make([]byte, count)
+		for i := range b {
+			b[i] = characters[random.Intn(len(characters))]
+		}
+		return string(b)
+	}
+	ascii := make([]byte, 0, 95)
+	for c := byte(' '); c <= '~'; c++ {
+		ascii = append(ascii, c)
+	}
+	uuid := func() string {
+		b := make([]byte, 16)
+		random.Read(b)
+		// version 4, variant 10
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	}
+	return map[string]interface{}{
+		"randAlpha":    func(count int) string { return randString(alphaCharacters, count) },
+		"randNumeric":  func(count int) string { return randString(numericCharacters, count) },
+		"randAlphaNum": func(count int) string { return randString(alphaCharacters+numericCharacters, count) },
+		"randAscii":    func(count int) string { return randString(string(ascii), count) },
+		"randBytes": func(count int) string {
+			b := make([]byte, count)
+			random.Read(b)
+			return base64.StdEncoding.EncodeToString(b)
+		},
+		"randInt": func(min, max int) int { return random.Intn(max-min) + min },
+		"shuffle": func(s string) string {
+			r := []rune(s)
+			random.Shuffle(len(r), func(i, j int) { r[i], r[j] = r[j], r[i] })
+			return string(r)
+		},
+		"uuidv4": uuid,
+		"uuid":   uuid,
+		"guid":   uuid,
+		"GUID":   uuid,
+	}
+}
@@ -0,0 +1,56 @@
+package internal_test
+
+import (
+	"regexp"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testSeed(t *testing.T, when spec.G, it spec.S) {
+	content := `{{randAlphaNum 16}} {{randInt 0 1000}} {{uuidv4}} {{shuffle "abcdefgh"}}`
+	render := func(file string, seed string) string {
+		vars := map[string]string{internal.SeedVariable: seed}
+		output, err := internal.SourceFile{FilePath: file, FileContent: content}.Replace(vars)
+		h.AssertNil(t, err)
+		return output.FileContent
+	}
+
+	when("the random functions are seeded", func() {
+		it("renders a file the same way every time", func() {
+			h.AssertEq(t, render("main.go", "42"), render("main.go", "42"))
+		})
+
+		it("renders differently with another seed", func() {
+			h.AssertNotEq(t, render("main.go", "42"), render("main.go", "43"))
+		})
+
+		it("gives each file its own values", func() {
+			h.AssertNotEq(t, render("main.go", "42"), render("config.yaml", "42"))
+		})
+
+		it("writes version 4 uuids", func() {
+			uuid := regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}`)
+			h.AssertTrue(t, uuid.MatchString(render("main.go", "42")))
+		})
+
+		it("renders defaults the same way every time", func() {
+			vars := map[string]string{internal.SeedVariable: "42"}
+			first, err := internal.RenderString("{{randAlpha 12}}", vars)
+			h.AssertNil(t, err)
+			second, err := internal.RenderString("{{randAlpha 12}}", vars)
+			h.AssertNil(t, err)
+			h.AssertEq(t, first, second)
+		})
+	})
+
+	when("the seed is not a number", func() {
+		it("is reported", func() {
+			_, err := internal.SourceFile{FilePath: "main.go", FileContent: content}.Replace(map[string]string{internal.SeedVariable: "abc"})
+			h.AssertError(t, err, "seed abc is not a whole number")
+		})
+	})
+}
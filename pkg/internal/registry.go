@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// RegistryEntry describes a template listed by a registry
+type RegistryEntry struct {
+	Name        string   `toml:"name"`
+	URL         string   `toml:"url"`
+	Description string   `toml:"description,omitempty"`
+	Tags        []string `toml:"tags,omitempty"`
+	// Template chooses a template when URL is a collection of templates
+	Template string `toml:"template,omitempty"`
+}
+
+// Registry is an index of templates, written as a TOML file of [[template]]
+// tables, that can be browsed by end-users who do not know template urls
+type Registry struct {
+	Templates []RegistryEntry `toml:"template"`
+}
+
+// IsRegistry reports whether url names a registry index rather than a
+// template
+func IsRegistry(url string) bool {
+	return strings.HasSuffix(strings.ToLower(url), ".toml")
+}
+
+// ReadRegistry reads the registry index at url, a local file or a file
+// downloaded over HTTP
+func ReadRegistry(url string, opts FetchOptions) (Registry, error) {
+	registry := Registry{}
+	registryFile := url
+	if _, err := os.Stat(url); err != nil {
+		downloaded, err := download(url, opts)
+		if err != nil {
+			return registry, err
+		}
+		defer os.Remove(downloaded)
+		registryFile = downloaded
+	}
+
+	registryData, err := ReadFile(registryFile)
+	if err != nil {
+		return registry, err
+	}
+	if _, err := toml.Decode(registryData, &registry); err != nil {
+		return registry, errors.Wrap(err, fmt.Sprintf("registry %s does not match required format", url))
+	}
+	for i, entry := range registry.Templates {
+		if entry.URL == "" {
+			return registry, fmt.Errorf("template %d of registry %s has no url", i+1, url)
+		}
+		if entry.Name == "" {
+			registry.Templates[i].Name = entry.URL
+		}
+	}
+	return registry, nil
+}
+
+// Matches reports whether every word of query is found, ignoring case, in the
+// name, description or tags of the entry
+func (e RegistryEntry) Matches(query string) bool {
+	text := strings.ToLower(strings.Join(append([]string{e.Name, e.Description}, e.Tags...), " "))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindReadme finds the README of the template in dir, if it has one
+func FindReadme(dir string) (string, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(strings.ToUpper(entry.Name()), "README") {
+			return filepath.Join(dir, entry.Name()), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,77 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testRegistry(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a registry index is read", func() {
+		it("lists its templates", func() {
+			registryFile := filepath.Join(tmpDir, "registry.toml")
+			h.AssertNil(t, os.WriteFile(registryFile, []byte(`
+[[template]]
+name = "python"
+url = "https://github.com/example/python-template"
+description = "A Python web service"
+tags = ["python", "web"]
+
+[[template]]
+url = "https://github.com/example/templates"
+template = "go"
+`), 0644))
+
+			registry, err := internal.ReadRegistry(registryFile, internal.FetchOptions{})
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(registry.Templates), 2)
+			h.AssertEq(t, registry.Templates[0].Tags, []string{"python", "web"})
+			h.AssertEq(t, registry.Templates[1].Name, "https://github.com/example/templates")
+			h.AssertEq(t, registry.Templates[1].Template, "go")
+		})
+
+		it("reports a template without a url", func() {
+			registryFile := filepath.Join(tmpDir, "registry.toml")
+			h.AssertNil(t, os.WriteFile(registryFile, []byte("[[template]]\nname = \"python\"\n"), 0644))
+
+			_, err := internal.ReadRegistry(registryFile, internal.FetchOptions{})
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "template 1 of registry")
+		})
+	})
+
+	when("registry entries are searched", func() {
+		it("matches every word against the name, description and tags", func() {
+			entry := internal.RegistryEntry{Name: "python", Description: "A web service", Tags: []string{"flask"}}
+			h.AssertTrue(t, entry.Matches("Flask web"))
+			h.AssertTrue(t, entry.Matches(""))
+			h.AssertEq(t, entry.Matches("flask cli"), false)
+		})
+	})
+
+	when("a template has a README", func() {
+		it("is found", func() {
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# Template"), 0644))
+			readme, ok := internal.FindReadme(tmpDir)
+			h.AssertTrue(t, ok)
+			h.AssertEq(t, readme, filepath.Join(tmpDir, "README.md"))
+		})
+	})
+}
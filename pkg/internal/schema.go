@@ -0,0 +1,616 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/buildpacks/scafall/pkg/internal/util"
+)
+
+// Problem is a single mistake in a prompts.toml file
+type Problem struct {
+	// Line of the mistake, or 0 when the line is not known
+	Line int
+	// Key is the path of the field in error, such as prompt.default
+	Key string
+	// Index is the position of the [[prompt]] table in error, counting from
+	// 1, or 0 when the mistake is not within a prompt
+	Index   int
+	Message string
+}
+
+// Path names the field in error, such as prompt.2.default for the default of
+// the second prompt
+func (p Problem) Path() string {
+	if p.Index == 0 || !strings.HasPrefix(p.Key, "prompt") {
+		return p.Key
+	}
+	return joinKey(fmt.Sprintf("prompt.%d", p.Index), strings.TrimPrefix(strings.TrimPrefix(p.Key, "prompt"), "."))
+}
+
+// PromptFileError lists every mistake found in a prompts.toml file
+type PromptFileError struct {
+	File     string
+	Problems []Problem
+}
+
+func (e PromptFileError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		location := e.File
+		if p.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, p.Line)
+		}
+		if path := p.Path(); path != "" {
+			location = fmt.Sprintf("%s: %s", location, path)
+		}
+		problems[i] = message(CodePromptFile, location, p.Message)
+	}
+	return strings.Join(problems, "\n")
+}
+
+func (e PromptFileError) Code() Code {
+	return CodePromptFile
+}
+
+const (
+	tomlString  = "a string"
+	tomlBool    = "a boolean"
+	tomlInteger = "an integer"
+	tomlStrings = "an array of strings"
+	tomlTable   = "a table"
+	tomlTables  = "an array of tables"
+	tomlChoices = "an array of strings or of tables with a label and value"
+)
+
+// ReservedPrefix begins the names of variables that are reserved for scafall
+const ReservedPrefix string = "__"
+
+// Prompt names are used as template variables, such as {{.Name}}
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// variablePattern finds the variables, written as {{ .name }}, used by a default
+var variablePattern = regexp.MustCompile(`{{\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*}}`)
+
+var (
+	promptFileFields = map[string]string{
+		"requires": tomlStrings,
+		"prompt":   tomlTables,
+		"settings": tomlTable,
+	}
+	promptFields = map[string]string{
+		"name":            tomlString,
+		"prompt":          tomlString,
+		"required":        tomlBool,
+		"default":         tomlString,
+		"choices":         tomlChoices,
+		"type":            tomlString,
+		"format":          tomlString,
+		"group":           tomlString,
+		"help":            tomlString,
+		"pattern":         tomlString,
+		"pattern-message": tomlString,
+		"error-message":   tomlString,
+		"when":            tomlString,
+		"record":          tomlBool,
+		"exists":          tomlString,
+		"min-length":      tomlInteger,
+		"max-length":      tomlInteger,
+		"labels":          tomlTable,
+	}
+	translationFields = map[string]string{
+		"prompt":          tomlString,
+		"help":            tomlString,
+		"pattern-message": tomlString,
+		"error-message":   tomlString,
+		"choices":         tomlTable,
+	}
+	provenanceFields = map[string]string{
+		"file":     tomlString,
+		"template": tomlString,
+		"when":     tomlString,
+	}
+	codeOwnersFields = map[string]string{
+		"variable": tomlString,
+		"file":     tomlString,
+		"when":     tomlString,
+	}
+)
+
+// Validate the structure of a prompts.toml file before it is decoded, so that
+// every mistake is reported together with its line
+func validatePromptFile(source string, file string) error {
+	raw := map[string]interface{}{}
+	if _, err := toml.Decode(source, &raw); err != nil {
+		if parseErr, ok := err.(toml.ParseError); ok {
+			// the message is only available with the position prepended
+			message := parseErr.Error()
+			if i := strings.Index(message, ": "); i >= 0 && parseErr.LastKey == "" {
+				message = strings.SplitN(message[i+2:], ": ", 2)[1]
+			} else if i := strings.Index(message, "): "); i >= 0 {
+				message = message[i+3:]
+			}
+			return PromptFileError{File: file, Problems: []Problem{{Line: parseErr.Position.Line, Key: parseErr.LastKey, Message: message}}}
+		}
+		return PromptFileError{File: file, Problems: []Problem{{Message: err.Error()}}}
+	}
+
+	v := schemaValidator{lines: keyLines(source)}
+	for _, key := range sortedKeys(raw) {
+		if !v.checkField("the prompt file", "", key, raw[key], promptFileFields) {
+			continue
+		}
+		if key == "prompt" {
+			names := map[string]int{}
+			for i, prompt := range raw[key].([]map[string]interface{}) {
+				v.checkPrompt(i, prompt, names)
+			}
+			v.checkGroups(raw[key].([]map[string]interface{}))
+		}
+		if key == "settings" {
+			v.checkSettings(raw[key].(map[string]interface{}))
+		}
+	}
+	if len(v.problems) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		return v.problems[i].Line < v.problems[j].Line
+	})
+	return PromptFileError{File: file, Problems: v.problems}
+}
+
+type schemaValidator struct {
+	lines    map[string]int
+	problems []Problem
+}
+
+func (v *schemaValidator) report(table string, key string, message string, args ...interface{}) {
+	// a key within an inline table is reported on the line of its table
+	line, ok := 0, false
+	for name := joinKey(table, key); !ok && name != ""; name = parentKey(name) {
+		line, ok = v.lines[name]
+	}
+	problem := Problem{Line: line, Key: joinKey(table, key), Message: fmt.Sprintf(message, args...)}
+	// the tables of prompts are named prompt.n, counting from 0
+	var index int
+	if _, err := fmt.Sscanf(table, "prompt.%d", &index); err == nil {
+		problem.Key = "prompt" + strings.TrimPrefix(problem.Key, fmt.Sprintf("prompt.%d", index))
+		problem.Index = index + 1
+	}
+	v.problems = append(v.problems, problem)
+}
+
+// Check that a field is known and has the expected type
+func (v *schemaValidator) checkField(owner string, table string, key string, value interface{}, fields map[string]string) bool {
+	expected, ok := fields[key]
+	if !ok {
+		v.report(table, key, "unknown field %s in %s; expected one of %s", key, owner, strings.Join(sortedKeys(fields), ", "))
+		return false
+	}
+	if found := tomlType(value); found != expected && !(expected == tomlChoices && isChoiceArray(value)) {
+		v.report(table, key, "%s of %s must be %s, found %s", key, owner, expected, found)
+		return false
+	}
+	return true
+}
+
+// Check the prompt at index of the prompts, names maps the names of earlier
+// prompts to their index
+func (v *schemaValidator) checkPrompt(index int, prompt map[string]interface{}, names map[string]int) {
+	table := fmt.Sprintf("prompt.%d", index)
+	name, _ := prompt["name"].(string)
+	owner := fmt.Sprintf("prompt %d", index+1)
+	if name != "" {
+		owner = "prompt " + name
+	}
+
+	for _, key := range sortedKeys(prompt) {
+		v.checkField(owner, table, key, prompt[key], promptFields)
+	}
+	for _, key := range []string{"name", "prompt"} {
+		if value, ok := prompt[key]; !ok || value == "" {
+			v.report(table, "", "%s is missing required field %s", owner, key)
+		}
+	}
+
+	earlier, defined := names[name]
+	switch {
+	case name == "":
+	case defined:
+		v.report(table, "name", "prompt %s is defined more than once; it is first defined by prompt %d on line %d", name, earlier+1, v.lines[fmt.Sprintf("prompt.%d", earlier)])
+	case strings.HasPrefix(name, ReservedPrefix):
+		v.report(table, "name", "prompt %s uses a reserved name; names beginning with %s are reserved for scafall", name, ReservedPrefix)
+	case !identifierPattern.MatchString(name):
+		v.report(table, "name", "prompt %s is not a valid template variable; use letters, digits and underscores, not beginning with a digit", name)
+	}
+	if when, ok := prompt["when"].(string); ok {
+		variables, err := ConditionVariables(when)
+		if err != nil {
+			v.report(table, "when", "%s", err)
+		}
+		for _, variable := range variables {
+			if _, ok := names[variable]; !ok && !IsContextVariable(variable) {
+				v.report(table, "when", "condition of %s uses %s, which is not an earlier prompt", owner, variable)
+			}
+		}
+	}
+	if def, ok := prompt["default"].(string); ok {
+		for _, match := range variablePattern.FindAllStringSubmatch(def, -1) {
+			variable, _ := caseVariantOf(match[1])
+			if _, ok := names[match[1]]; ok {
+				continue
+			}
+			if _, ok := names[variable]; !ok && !strings.HasPrefix(match[1], ReservedPrefix) {
+				v.report(table, "default", "default of %s uses %s, which is not an earlier prompt", owner, match[1])
+			}
+		}
+	}
+	if !defined {
+		names[name] = index
+	}
+	if promptType, ok := prompt["type"].(string); ok && !util.Contains(PromptTypes, promptType) {
+		v.report(table, "type", "type %s of %s is unknown; expected one of %s", promptType, owner, strings.Join(PromptTypes, ", "))
+	}
+	if pattern, ok := prompt["pattern"].(string); ok {
+		compiled, err := compilePattern(pattern)
+		def, hasDefault := prompt["default"].(string)
+		switch {
+		case err != nil:
+			v.report(table, "pattern", "pattern %s of %s is not a regular expression: %s", pattern, owner, err)
+		case hasDefault && def != "" && !strings.Contains(def, "{{") && !matchesDefault(compiled, def, prompt["type"] == ListType):
+			v.report(table, "default", "default %s of %s does not match its pattern %s", def, owner, pattern)
+		}
+	}
+	if exists, ok := prompt["exists"].(string); ok {
+		switch {
+		case prompt["type"] != PathType:
+			v.report(table, "exists", "%s is not a path and cannot require a path to exist", owner)
+		case !util.Contains(PathExistence, exists):
+			v.report(table, "exists", "exists %s of %s is unknown; expected one of %s", exists, owner, strings.Join(PathExistence, ", "))
+		}
+	}
+	v.checkLengths(table, owner, prompt)
+	v.checkChoices(table, owner, prompt)
+	v.checkTranslations(table, owner, prompt)
+	choices, labels := choiceValues(prompt["choices"])
+	if def, ok := prompt["default"].(string); ok && len(choices) != 0 {
+		// each item of the default of a list must be one of the choices
+		items := []string{def}
+		if prompt["type"] == ListType {
+			items = SplitList(def)
+		}
+		for _, item := range items {
+			if util.Contains(choices, item) {
+				continue
+			}
+			if value, ok := labels[item]; ok {
+				v.report(table, "default", "default %s of %s is the label of a choice; use its value %s", item, owner, value)
+			} else {
+				v.report(table, "default", "default %s of %s is not one of its choices %s", item, owner, strings.Join(choices, ", "))
+			}
+		}
+	}
+}
+
+// Check that each choice of a prompt written as a table has a value and, at
+// most, a label
+func (v *schemaValidator) checkChoices(table string, owner string, prompt map[string]interface{}) {
+	if !isChoiceArray(prompt["choices"]) {
+		return
+	}
+	for i, choice := range choiceArray(prompt["choices"]) {
+		fields, ok := choice.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range sortedKeys(fields) {
+			if _, ok := fields[key].(string); !ok || (key != "label" && key != "value") {
+				v.report(table, "choices", "choice %d of %s has %s; a choice has a label and a value, both strings", i+1, owner, key)
+			}
+		}
+		if value, _ := fields["value"].(string); value == "" {
+			v.report(table, "choices", "choice %d of %s has no value", i+1, owner)
+		}
+	}
+}
+
+// Check that each translation of a prompt is a table of its text, and that
+// the choices it labels are choices of the prompt
+func (v *schemaValidator) checkTranslations(table string, owner string, prompt map[string]interface{}) {
+	translations, ok := prompt["labels"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	choices, _ := choiceValues(prompt["choices"])
+	for _, language := range sortedKeys(translations) {
+		key := joinKey("labels", language)
+		translation, ok := translations[language].(map[string]interface{})
+		if !ok {
+			v.report(table, key, "translation %s of %s must be %s, found %s", language, owner, tomlTable, tomlType(translations[language]))
+			continue
+		}
+		translationOwner := fmt.Sprintf("the %s translation of %s", language, owner)
+		for _, field := range sortedKeys(translation) {
+			v.checkField(translationOwner, joinKey(table, key), field, translation[field], translationFields)
+		}
+		labels, _ := translation["choices"].(map[string]interface{})
+		for _, choice := range sortedKeys(labels) {
+			if _, ok := labels[choice].(string); !ok {
+				v.report(table, joinKey(key, "choices."+choice), "label of %s in %s must be %s, found %s", choice, translationOwner, tomlString, tomlType(labels[choice]))
+			} else if !util.Contains(choices, choice) {
+				v.report(table, joinKey(key, "choices."+choice), "%s labels %s, which is not one of the choices %s", translationOwner, choice, strings.Join(choices, ", "))
+			}
+		}
+	}
+}
+
+// Check that the length limits of a prompt can be met and are met by its
+// default
+func (v *schemaValidator) checkLengths(table string, owner string, prompt map[string]interface{}) {
+	minLength, hasMin := prompt["min-length"].(int64)
+	maxLength, hasMax := prompt["max-length"].(int64)
+	switch {
+	case hasMin && minLength < 0:
+		v.report(table, "min-length", "min-length %d of %s must not be negative", minLength, owner)
+		return
+	case hasMax && maxLength < 1:
+		v.report(table, "max-length", "max-length %d of %s must be at least 1", maxLength, owner)
+		return
+	case hasMin && hasMax && minLength > maxLength:
+		v.report(table, "min-length", "min-length %d of %s is greater than its max-length %d", minLength, owner, maxLength)
+		return
+	}
+	def, ok := prompt["default"].(string)
+	if !ok || def == "" || strings.Contains(def, "{{") {
+		return
+	}
+	lengths := Prompt{MinLength: int(minLength), MaxLength: int(maxLength)}
+	if prompt["type"] == ListType {
+		lengths.Type = ListType
+	}
+	if err := CheckLength(lengths, def); err != nil {
+		v.report(table, "default", "default of %s does not meet its length limits: %s", owner, err)
+	}
+}
+
+// Report whether a default matches the pattern of its prompt, each item of a
+// list must match
+func matchesDefault(pattern *regexp.Regexp, def string, list bool) bool {
+	items := []string{def}
+	if list {
+		items = SplitList(def)
+	}
+	for _, item := range items {
+		if !pattern.MatchString(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check that the prompts of each group follow one another, so that each group
+// is asked as a single section
+func (v *schemaValidator) checkGroups(prompts []map[string]interface{}) {
+	finished := map[string]bool{}
+	current := ""
+	for i, prompt := range prompts {
+		group, _ := prompt["group"].(string)
+		if group == current {
+			continue
+		}
+		if finished[group] {
+			name, _ := prompt["name"].(string)
+			if name == "" {
+				name = fmt.Sprint(i + 1)
+			}
+			v.report(fmt.Sprintf("prompt.%d", i), "group", "prompt %s is separated from the earlier prompts of group %s; the prompts of a group must follow one another", name, group)
+		}
+		finished[current] = true
+		current = group
+	}
+}
+
+func (v *schemaValidator) checkSettings(settings map[string]interface{}) {
+	if provenance, ok := settings["provenance"]; ok {
+		v.checkProvenance(provenance)
+	}
+	if codeOwners, ok := settings["codeowners"]; ok {
+		v.checkCodeOwners(codeOwners)
+	}
+	engine, ok := settings["engine"]
+	if !ok {
+		return
+	}
+	if name, ok := engine.(string); !ok {
+		v.report("settings", "engine", "engine of settings must be %s, found %s", tomlString, tomlType(engine))
+	} else if err := CheckEngine(name); err != nil {
+		v.report("settings", "engine", "%s", err)
+	}
+}
+
+func (v *schemaValidator) checkProvenance(provenance interface{}) {
+	table, ok := provenance.(map[string]interface{})
+	if !ok {
+		v.report("settings", "provenance", "provenance of settings must be %s, found %s", tomlTable, tomlType(provenance))
+		return
+	}
+	for _, key := range sortedKeys(table) {
+		v.checkField("the provenance settings", "settings.provenance", key, table[key], provenanceFields)
+	}
+	if when, ok := table["when"].(string); ok {
+		if _, err := ConditionVariables(when); err != nil {
+			v.report("settings.provenance", "when", "%s", err)
+		}
+	}
+}
+
+func (v *schemaValidator) checkCodeOwners(codeOwners interface{}) {
+	table, ok := codeOwners.(map[string]interface{})
+	if !ok {
+		v.report("settings", "codeowners", "codeowners of settings must be %s, found %s", tomlTable, tomlType(codeOwners))
+		return
+	}
+	for _, key := range sortedKeys(table) {
+		v.checkField("the codeowners settings", "settings.codeowners", key, table[key], codeOwnersFields)
+	}
+	if _, ok := table["variable"]; !ok {
+		v.report("settings.codeowners", "variable", "the codeowners settings must name the variable that lists the owners")
+	}
+	if when, ok := table["when"].(string); ok {
+		if _, err := ConditionVariables(when); err != nil {
+			v.report("settings.codeowners", "when", "%s", err)
+		}
+	}
+}
+
+// Describe the type of a decoded TOML value
+func tomlType(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return tomlString
+	case bool:
+		return tomlBool
+	case int64:
+		return tomlInteger
+	case float64:
+		return "a float"
+	case map[string]interface{}:
+		return tomlTable
+	case []map[string]interface{}:
+		return tomlTables
+	case []interface{}:
+		for _, element := range value {
+			if _, ok := element.(string); !ok {
+				return "an array of " + strings.TrimPrefix(strings.TrimPrefix(tomlType(element), "a "), "an ") + "s"
+			}
+		}
+		return tomlStrings
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// The elements of an array of choices
+func choiceArray(value interface{}) []interface{} {
+	switch value := value.(type) {
+	case []interface{}:
+		return value
+	case []map[string]interface{}:
+		elements := make([]interface{}, len(value))
+		for i, element := range value {
+			elements[i] = element
+		}
+		return elements
+	}
+	return nil
+}
+
+// Report whether value is an array of choices, each a string or a table
+func isChoiceArray(value interface{}) bool {
+	if _, ok := value.([]map[string]interface{}); ok {
+		return true
+	}
+	if _, ok := value.([]interface{}); !ok {
+		return false
+	}
+	for _, element := range choiceArray(value) {
+		switch element.(type) {
+		case string, map[string]interface{}:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// The values of an array of choices, and the value of each label
+func choiceValues(value interface{}) ([]string, map[string]string) {
+	values := []string{}
+	labels := map[string]string{}
+	for _, element := range choiceArray(value) {
+		choice, label := readChoice(element)
+		values = append(values, choice)
+		if label != "" {
+			labels[label] = choice
+		}
+	}
+	return values, labels
+}
+
+// The key of the table containing key, empty for a top level key
+func parentKey(key string) string {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		return key[:i]
+	}
+	return ""
+}
+
+func joinKey(table string, key string) string {
+	switch {
+	case table == "":
+		return key
+	case key == "":
+		return table
+	}
+	return table + "." + key
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	switch m := m.(type) {
+	case map[string]interface{}:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]string:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Find the line on which each key is defined.  Keys of the nth [[prompt]] are
+// named prompt.n.key, and prompt.n is the line of its header.
+func keyLines(source string) map[string]int {
+	lines := map[string]int{}
+	table := ""
+	prompts := 0
+	for i, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[["):
+			table = strings.TrimSpace(strings.TrimPrefix(strings.SplitN(line, "]]", 2)[0], "[["))
+			if table == "prompt" {
+				table = fmt.Sprintf("prompt.%d", prompts)
+				prompts++
+			}
+		case strings.HasPrefix(line, "["):
+			table = strings.TrimSpace(strings.TrimPrefix(strings.SplitN(line, "]", 2)[0], "["))
+			// a table within a prompt, such as [prompt.labels.fr], belongs
+			// to the last [[prompt]]
+			if strings.HasPrefix(table, "prompt.") && prompts > 0 {
+				table = fmt.Sprintf("prompt.%d.%s", prompts-1, strings.TrimPrefix(table, "prompt."))
+			}
+		default:
+			j := strings.Index(line, "=")
+			if j <= 0 {
+				continue
+			}
+			key := joinKey(table, strings.Trim(strings.TrimSpace(line[:j]), `"'`))
+			if _, ok := lines[key]; !ok {
+				lines[key] = i + 1
+			}
+			continue
+		}
+		lines[table] = i + 1
+	}
+	return lines
+}
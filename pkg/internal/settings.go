@@ -0,0 +1,253 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Settings control how a project template is rendered.  Settings are read
+// from the [settings] table of the prompts.toml file.
+type Settings struct {
+	// Permissions maps output paths to file modes, both may use template
+	// variables and paths may be glob patterns
+	Permissions map[string]string `toml:"permissions,omitempty"`
+	// Roots maps folders of the template to folders of the output, only
+	// files within a root are rendered when any roots are declared
+	Roots map[string]string `toml:"roots,omitempty"`
+	// Exclude lists template paths, in addition to DefaultExclusions, that
+	// are not rendered
+	Exclude []string `toml:"exclude,omitempty"`
+	// Include lists DefaultExclusions that are rendered
+	Include []string `toml:"include,omitempty"`
+	// Raw lists template paths, or glob patterns, of files that are copied
+	// as they are rather than rendered, although their paths are rendered
+	Raw []string `toml:"raw,omitempty"`
+	// TemplateSuffix, such as .tmpl, when set renders only the files whose
+	// names end with it, which are written without it, every other file is
+	// copied as it is
+	TemplateSuffix string `toml:"template-suffix,omitempty"`
+	// Engine names the engine that renders the template, GoTemplateEngine
+	// when empty
+	Engine string `toml:"engine,omitempty"`
+	// Provenance, when set, appends a section recording the template to a
+	// file of the output project
+	Provenance *Provenance `toml:"provenance,omitempty"`
+	// CodeOwners, when set, adds an entry for the project to the CODEOWNERS
+	// file of the repository it is scaffolded into
+	CodeOwners *CodeOwners `toml:"codeowners,omitempty"`
+	// Functions are added to the functions of templates by programs using
+	// scafall, they cannot be given in prompts.toml
+	Functions Functions `toml:"-"`
+}
+
+// DefaultExclusions are the template's own CI configuration, scafall
+// configuration and tests, which are not rendered unless mapped by a root or
+// included in the settings
+var DefaultExclusions = []string{".github", ".scafall", "tests"}
+
+// Permission is a file mode to be set on output files matching Pattern
+type Permission struct {
+	Pattern string
+	Mode    fs.FileMode
+}
+
+// RenderPermissions renders the paths and modes of permissions using vars with
+// the named engine
+func RenderPermissions(permissions map[string]string, vars map[string]string, engine string) ([]Permission, error) {
+	rendered := make([]Permission, 0, len(permissions))
+	for pattern, mode := range permissions {
+		renderedPattern, err := Render(engine, pattern, vars)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to render permission path %s", pattern))
+		}
+		renderedMode, err := Render(engine, mode, vars)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to render permission mode %s", mode))
+		}
+		m, err := ParseMode(renderedMode)
+		if err != nil {
+			return nil, fmt.Errorf("permission for %s is not an octal file mode: %s", pattern, renderedMode)
+		}
+		rendered = append(rendered, Permission{Pattern: filepath.ToSlash(renderedPattern), Mode: m})
+	}
+	return rendered, nil
+}
+
+// MatchPermission finds the mode for an output file, a file path must match
+// a pattern exactly or as a glob
+func MatchPermission(permissions []Permission, filePath string) (fs.FileMode, bool) {
+	filePath = filepath.ToSlash(filePath)
+	for _, p := range permissions {
+		if matched, _ := filepath.Match(p.Pattern, filePath); matched || p.Pattern == filePath {
+			return p.Mode, true
+		}
+	}
+	return 0, false
+}
+
+// Root maps the Source folder of a template to the Target folder of the output
+type Root struct {
+	Source string
+	Target string
+}
+
+// ParseRoots validates roots and orders them so that the most specific
+// source folder is matched first
+func ParseRoots(roots map[string]string) ([]Root, error) {
+	parsed := make([]Root, 0, len(roots))
+	for source, target := range roots {
+		root := Root{Source: path.Clean(filepath.ToSlash(source)), Target: path.Clean(filepath.ToSlash(target))}
+		if isOutside(root.Source) {
+			return nil, fmt.Errorf("source root %s is outside of the template", source)
+		}
+		if isOutside(root.Target) {
+			return nil, fmt.Errorf("target %s of source root %s is outside of the output folder", target, source)
+		}
+		parsed = append(parsed, root)
+	}
+	sort.Slice(parsed, func(i, j int) bool {
+		if len(parsed[i].Source) != len(parsed[j].Source) {
+			return len(parsed[i].Source) > len(parsed[j].Source)
+		}
+		return parsed[i].Source < parsed[j].Source
+	})
+	return parsed, nil
+}
+
+func isOutside(p string) bool {
+	return path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../")
+}
+
+// MapRoot finds the output path of a template file.  Files outside of every
+// root are not mapped, all files are mapped to themselves when there are no
+// roots.
+func MapRoot(roots []Root, filePath string) (string, bool) {
+	if len(roots) == 0 {
+		return filePath, true
+	}
+	root, ok := matchRoot(roots, filePath)
+	if !ok {
+		return "", false
+	}
+	rel := filepath.ToSlash(filePath)
+	if root.Source != "." {
+		rel = strings.TrimPrefix(strings.TrimPrefix(rel, root.Source), "/")
+	}
+	return filepath.FromSlash(path.Join(root.Target, rel)), true
+}
+
+func matchRoot(roots []Root, filePath string) (Root, bool) {
+	for _, root := range roots {
+		if root.Source == "." || isWithin(filepath.ToSlash(filePath), root.Source) {
+			return root, true
+		}
+	}
+	return Root{}, false
+}
+
+// Reports whether p is folder, or is within folder, or matches the glob folder
+func isWithin(p string, folder string) bool {
+	if matched, _ := path.Match(folder, p); matched {
+		return true
+	}
+	return p == folder || strings.HasPrefix(p, folder+"/")
+}
+
+// Exclusions combines DefaultExclusions, less those included by the settings,
+// with the exclusions of the settings
+func (s Settings) Exclusions() []string {
+	exclusions := []string{}
+	for _, exclusion := range DefaultExclusions {
+		included := false
+		for _, include := range s.Include {
+			included = included || path.Clean(filepath.ToSlash(include)) == exclusion
+		}
+		if !included {
+			exclusions = append(exclusions, exclusion)
+		}
+	}
+	for _, exclusion := range s.Exclude {
+		exclusions = append(exclusions, path.Clean(filepath.ToSlash(exclusion)))
+	}
+	return exclusions
+}
+
+// IsExcluded reports whether a template file is excluded.  A file within an
+// excluded folder is rendered when it is mapped by a root whose source is
+// within the excluded folder.
+func IsExcluded(exclusions []string, roots []Root, filePath string) bool {
+	p := filepath.ToSlash(filePath)
+	for _, exclusion := range exclusions {
+		if !isWithin(p, exclusion) {
+			continue
+		}
+		if root, ok := matchRoot(roots, filePath); ok && root.Source != "." && isWithin(root.Source, exclusion) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// IsRaw reports whether a template file is copied as it is, rather than
+// rendered.  A pattern without a slash, such as *.png.tpl, matches the name
+// of a file in any folder, other patterns match the path of a file, or of a
+// folder it is within, and ** matches any number of folders.
+func IsRaw(patterns []string, filePath string) bool {
+	p := filepath.ToSlash(filePath)
+	for _, pattern := range patterns {
+		pattern = path.Clean(filepath.ToSlash(pattern))
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := path.Match(pattern, path.Base(p)); matched {
+				return true
+			}
+		}
+		if isWithin(p, pattern) || matchGlob(strings.Split(pattern, "/"), strings.Split(p, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// TrimTemplateSuffix removes suffix from the name of a template file,
+// reporting whether the file is rendered.  Every file is rendered when there
+// is no suffix, a file whose name is only the suffix is not.
+func TrimTemplateSuffix(suffix string, filePath string) (string, bool) {
+	if suffix == "" {
+		return filePath, true
+	}
+	name := filepath.Base(filePath)
+	if name == suffix || !strings.HasSuffix(name, suffix) {
+		return filePath, false
+	}
+	return strings.TrimSuffix(filePath, suffix), true
+}
+
+// Match the parts of a path against the parts of a glob pattern, in which a
+// part ** matches any number of parts
+func matchGlob(pattern []string, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchGlob(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], parts[0]); !matched {
+		return false
+	}
+	return matchGlob(pattern[1:], parts[1:])
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Prefixes of shorthand urls and the url of the host that each expands to
+var shorthandPrefixes = []struct {
+	prefixes []string
+	host     string
+}{
+	{[]string{"gh:", "github:"}, "https://github.com/"},
+	{[]string{"gl:", "gitlab:"}, "https://gitlab.com/"},
+	{[]string{"bb:", "bitbucket:"}, "https://bitbucket.org/"},
+}
+
+// An org/repo shorthand for a GitHub repository
+var githubShorthand = regexp.MustCompile(`^[\w.-]+/[\w.-]+$`)
+
+// ExpandURL expands shorthand urls, such as gh:org/repo, gl:group/repo,
+// bb:org/repo or org/repo, to the url of the repository.  Local paths,
+// including file:// urls and paths starting with ~, are resolved to absolute
+// paths, except file:// urls naming a git repository, and other urls are
+// returned unchanged.
+func ExpandURL(url string) string {
+	if path, ok := LocalPath(url); ok {
+		// a repository named by a file:// url is cloned rather than copied
+		if isFileRepository(url, path) {
+			return url
+		}
+		return path
+	}
+	for _, shorthand := range shorthandPrefixes {
+		for _, prefix := range shorthand.prefixes {
+			if strings.HasPrefix(url, prefix) {
+				return shorthand.host + strings.TrimPrefix(strings.TrimPrefix(url, prefix), "/")
+			}
+		}
+	}
+	if githubShorthand.MatchString(url) && !strings.HasPrefix(url, ".") && !IsArchive(url) {
+		return "https://github.com/" + url
+	}
+	return url
+}
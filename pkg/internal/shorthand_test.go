@@ -0,0 +1,37 @@
+package internal_test
+
+import (
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testExpandURL(t *testing.T, when spec.G, it spec.S) {
+	type TestCase struct {
+		url      string
+		expected string
+	}
+	testCases := []TestCase{
+		{"gh:org/repo", "https://github.com/org/repo"},
+		{"github:org/repo", "https://github.com/org/repo"},
+		{"gl:group/subgroup/repo", "https://gitlab.com/group/subgroup/repo"},
+		{"bb:org/repo", "https://bitbucket.org/org/repo"},
+		{"org/repo", "https://github.com/org/repo"},
+		{"https://github.com/org/repo", "https://github.com/org/repo"},
+		{"git@github.com:org/repo.git", "git@github.com:org/repo.git"},
+		{"oci://ghcr.io/org/template:v1", "oci://ghcr.io/org/template:v1"},
+		{"releases/template.tar.gz", "releases/template.tar.gz"},
+		{"./org/repo", "./org/repo"},
+	}
+	for _, testCase := range testCases {
+		current := testCase
+		when("a url is expanded", func() {
+			it("expands shorthand urls only", func() {
+				h.AssertEq(t, internal.ExpandURL(current.url), current.expected)
+			})
+		})
+	}
+}
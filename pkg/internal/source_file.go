@@ -5,8 +5,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
+	"time"
 
 	t "github.com/coveooss/gotemplate/v3/template"
 )
@@ -22,7 +22,11 @@ func (s SourceFile) Transform(inputDir string, outputDir string, vars map[string
 	if err != nil {
 		return err
 	}
+	return s.write(inputDir, outputDir, outputFile)
+}
 
+// Write outputFile, the rendered form of s, to outputDir
+func (s SourceFile) write(inputDir string, outputDir string, outputFile SourceFile) error {
 	dstDir := filepath.Join(outputDir, filepath.Dir(outputFile.FilePath))
 	mkdirErr := os.MkdirAll(dstDir, 0744)
 	if mkdirErr != nil {
@@ -37,15 +41,22 @@ func (s SourceFile) Transform(inputDir string, outputDir string, vars map[string
 			return fmt.Errorf("failed to rename %s to %s", s.FilePath, outputFile.FilePath)
 		}
 	} else {
-		os.WriteFile(outputPath, []byte(outputFile.FileContent), outputFile.FileMode|0600)
+		err := os.WriteFile(outputPath, []byte(outputFile.FileContent), outputFile.FileMode|0600)
+		if err != nil {
+			return fmt.Errorf("failed to write %s", outputFile.FilePath)
+		}
+	}
+
+	// the owner can always read and write generated files
+	if outputFile.FileMode != 0 {
+		return os.Chmod(outputPath, outputFile.FileMode|0600)
 	}
 	return nil
 }
 
-func replaceUnknownVars(vars map[string]string, content string) string {
-	regex := regexp.MustCompile(`{{[ \t]*\.\w+`)
+func replaceUnknownVars(vars map[string]interface{}, content string) string {
 	transformed := content
-	for _, token := range regex.FindAllString(content, -1) {
+	for _, token := range unknownVariablePattern.FindAllString(content, -1) {
 		candidate := strings.Split(token, ".")[1]
 		if _, exists := vars[candidate]; !exists {
 			// replace "{{\s*.candidate" with "{&{&\s*.candidate"
@@ -56,35 +67,192 @@ func replaceUnknownVars(vars map[string]string, content string) string {
 	return transformed
 }
 
-func (s SourceFile) Replace(vars map[string]string) (SourceFile, error) {
+// Functions are template functions provided by a program using scafall, such
+// as helpers for buildpacks.  They are added to the functions of sprig, and
+// take the place of functions with the same name.
+type Functions map[string]interface{}
+
+// Create a template for vars, with which helpers are associated and which can
+// call the functions
+func newTemplate(vars map[string]string, helpers Helpers, functions Functions) (*t.Template, error) {
 	opts := t.DefaultOptions().
 		Set(t.Overwrite, t.Sprig, t.StrictErrorCheck, t.AcceptNoValue).
 		Unset(t.Razor)
 	template, err := t.NewTemplate(
 		"",
-		vars,
+		templateContext(vars),
 		"",
 		opts)
+	if err != nil {
+		return nil, err
+	}
+	template = template.AddFunctions(map[string]interface{}{NameFormsFunction: NameForms}, "Scafall", nil)
+	if len(functions) != 0 {
+		template = template.AddFunctions(functions, "Scafall", nil)
+	}
+	if err := helpers.addTo(template, templateContext(vars)); err != nil {
+		return nil, err
+	}
+	// the context imports the helpers when it is created, so it is created
+	// once they are added
+	if len(functions) != 0 {
+		addFunctions(template, functions)
+	}
+	return template, nil
+}
+
+// Add functions to the context in which process renders content, where they
+// take the place of the functions of gotemplate and sprig with the same name.
+// gotemplate renders content in a context of its own, which adds its
+// functions after those of the template, so functions added to the template
+// alone are used only when the name is not taken.
+func addFunctions(template *t.Template, functions map[string]interface{}) {
+	template.AddFunctions(functions, "Scafall", nil)
+	template.GetNewContext(processFolder, true).AddFunctions(functions, "Scafall", nil)
+}
+
+// The variables available to templates, the ContextVariables, the NameForms of
+// the project name and the case variants of every variable are added to the
+// template variables and list variables are given as a slice of their items
+func templateContext(vars map[string]string) map[string]interface{} {
+	context := make(map[string]interface{}, len(vars)+1)
+	for name, value := range ContextVariables() {
+		context[name] = value
+	}
+	for name, value := range CaseVariants(vars) {
+		context[name] = value
+	}
+	for name, value := range vars {
+		context[name] = value
+	}
+	if lists, ok := vars[ListsVariable]; ok {
+		for _, name := range strings.Split(lists, ",") {
+			if value, ok := vars[name]; ok {
+				context[name] = SplitList(value)
+			}
+		}
+	}
+	if base, ok := vars[NameFormsBase]; ok {
+		if _, exists := vars[NameFormsVariable]; !exists {
+			context[NameFormsVariable] = NameForms(base)
+		}
+	}
+	return context
+}
+
+// The folder of the context in which gotemplate renders content given without
+// a file name
+const processFolder = "."
+
+// Process content with template, leaving any unknown variables in place
+func process(template *t.Template, vars map[string]string, content string) (string, error) {
+	transformed, err := template.ProcessContent(replaceUnknownVars(templateContext(vars), content), "")
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(transformed, ReplacementDelimiter, "{{"), nil
+}
+
+// RenderString renders a single string, such as a prompt choice, using vars.
+// Unknown variables are left in place in the same way as in files.
+func RenderString(content string, vars map[string]string) (string, error) {
+	return RenderStringWith(content, vars, nil)
+}
+
+// RenderStringWith renders a single string using vars, the functions can be
+// called in addition to the functions of every template
+func RenderStringWith(content string, vars map[string]string, functions Functions) (string, error) {
+	template, err := newTemplate(vars, nil, functions)
+	if err != nil {
+		return "", err
+	}
+	if err := seedFunctions(template, vars, content); err != nil {
+		return "", err
+	}
+	return process(template, vars, content)
+}
+
+func (s SourceFile) Replace(vars map[string]string) (SourceFile, error) {
+	return s.replace(vars, nil, nil)
+}
+
+// Replace template variables, the helper templates and functions can be used
+// by the file
+func (s SourceFile) replace(vars map[string]string, helpers Helpers, functions Functions) (SourceFile, error) {
+	template, err := newTemplate(vars, helpers, functions)
 	if err != nil {
 		return SourceFile{}, err
 	}
+	if err := seedFunctions(template, vars, s.FilePath); err != nil {
+		return SourceFile{}, err
+	}
 
-	filePath := replaceUnknownVars(vars, s.FilePath)
-	transformedFilePath, err := template.ProcessContent(filePath, "")
+	transformedFilePath, err := process(template, vars, s.FilePath)
 	if err != nil {
 		return SourceFile{}, err
 	}
-	transformedFilePath = strings.ReplaceAll(transformedFilePath, ReplacementDelimiter, "{{")
 
 	transformedFileContent := ""
 	if s.FileContent != "" {
-		fileContent := replaceUnknownVars(vars, s.FileContent)
-		transformedFileContent, err = template.ProcessContent(fileContent, "")
+		transformedFileContent, err = process(template, vars, s.FileContent)
 		if err != nil {
 			return SourceFile{}, err
 		}
-		transformedFileContent = strings.ReplaceAll(transformedFileContent, ReplacementDelimiter, "{{")
 	}
 
-	return SourceFile{FilePath: transformedFilePath, FileContent: transformedFileContent}, nil
+	return SourceFile{FilePath: transformedFilePath, FileContent: transformedFileContent, FileMode: s.FileMode}, nil
+}
+
+// Replace template variables using the engine of the settings, helper
+// templates and the functions of the settings are only used by
+// GoTemplateEngine
+func (s SourceFile) replaceWith(settings Settings, vars map[string]string, helpers Helpers) (SourceFile, error) {
+	if settings.Engine != PlaceholderEngine {
+		return s.replace(vars, helpers, settings.Functions)
+	}
+	return SourceFile{FilePath: RenderPlaceholders(s.FilePath, vars), FileContent: RenderPlaceholders(s.FileContent, vars), FileMode: s.FileMode}, nil
+}
+
+// RenderTimeoutError is raised when a file takes longer than the render
+// timeout to render
+type RenderTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e RenderTimeoutError) Error() string {
+	return message(CodeRenderTimeout, e.Timeout)
+}
+
+func (e RenderTimeoutError) Code() Code {
+	return CodeRenderTimeout
+}
+
+// Replace template variables, using the engine of the settings, giving up once timeout
+// has passed.  The timeout is not a safety boundary: neither the template
+// engine nor sprig can be interrupted, so a template that exceeds the timeout
+// keeps running in the background, using CPU and memory without limit, until
+// it completes or the program exits.  A timeout of zero or less never gives up.
+func (s SourceFile) ReplaceWithin(settings Settings, vars map[string]string, helpers Helpers, timeout time.Duration) (SourceFile, error) {
+	if timeout <= 0 {
+		return s.replaceWith(settings, vars, helpers)
+	}
+
+	type outcome struct {
+		file SourceFile
+		err  error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		file, err := s.replaceWith(settings, vars, helpers)
+		done <- outcome{file: file, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case o := <-done:
+		return o.file, o.err
+	case <-timer.C:
+		return SourceFile{}, RenderTimeoutError{Timeout: timeout}
+	}
 }
@@ -1,8 +1,10 @@
 package internal_test
 
 import (
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	h "github.com/buildpacks/pack/testhelpers"
@@ -42,6 +44,78 @@ func testReplace(t *testing.T, when spec.G, it spec.S) {
 	}
 }
 
+func testEscaping(t *testing.T, when spec.G, it spec.S) {
+	vars := map[string]string{"Greeting": `<"Tom" & 'Jerry'>`}
+
+	when("a file is rendered", func() {
+		it("writes characters special to HTML as they are", func() {
+			file := internal.SourceFile{FilePath: "main.go", FileContent: `if a < b && c > d { s := "{{.Greeting}}" }`}
+			output, err := file.Replace(vars)
+			h.AssertNil(t, err)
+			h.AssertEq(t, output.FileContent, `if a < b && c > d { s := "<"Tom" & 'Jerry'>" }`)
+		})
+	})
+
+	when("a template escapes a value", func() {
+		it("is written escaped", func() {
+			file := internal.SourceFile{FilePath: "index.html", FileContent: `<p>{{html .Greeting}}</p>`}
+			output, err := file.Replace(vars)
+			h.AssertNil(t, err)
+			h.AssertEq(t, output.FileContent, `<p>&lt;&#34;Tom&#34; &amp; &#39;Jerry&#39;&gt;</p>`)
+		})
+	})
+}
+
+func testTemplateFunctions(t *testing.T, when spec.G, it spec.S) {
+	functions := internal.Functions{
+		"buildpackID": func(name string) string { return "example/" + strings.ToLower(name) },
+		"upper":       func(s string) string { return "UPPER " + s },
+	}
+	vars := map[string]string{"Name": "Shop"}
+
+	when("a project is rendered", func() {
+		it("calls the functions in files and paths", func() {
+			tmpDir, _ := os.MkdirTemp("", "test")
+			defer os.RemoveAll(tmpDir)
+			outputDir, _ := os.MkdirTemp("", "test")
+			defer os.RemoveAll(outputDir)
+			os.WriteFile(filepath.Join(tmpDir, "{{buildpackID .Name | base}}.toml"), []byte(`id = "{{buildpackID .Name}}"`), 0600)
+
+			err := internal.Apply(tmpDir, vars, outputDir, internal.Settings{Functions: functions})
+			h.AssertNil(t, err)
+			content, err := internal.ReadFile(filepath.Join(outputDir, "shop.toml"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, content, `id = "example/shop"`)
+		})
+
+		it("takes the place of a function of sprig with the same name", func() {
+			output, err := internal.RenderStringWith("{{upper .Name}}", vars, functions)
+			h.AssertNil(t, err)
+			h.AssertEq(t, output, "UPPER Shop")
+		})
+	})
+
+	when("a prompt is rendered", func() {
+		it("calls the functions in its default", func() {
+			promptFile := io.NopCloser(strings.NewReader(`[[prompt]]
+name = "Name"
+prompt = "Buildpack name"
+default = "Shop"
+
+[[prompt]]
+name = "ID"
+prompt = "Buildpack ID"
+default = "{{buildpackID .Name}}"
+`))
+			template, err := internal.NewTemplate(promptFile, nil, nil)
+			h.AssertNil(t, err)
+			values, err := template.AddFunctions(functions).Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["ID"], "example/shop")
+		})
+	})
+}
+
 func testTransform(t *testing.T, when spec.G, it spec.S) {
 	type TestCase struct {
 		file            internal.SourceFile
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// OutputEstimate is the space a project is expected to take on the
+// filesystem of its output folder
+type OutputEstimate struct {
+	Bytes  uint64
+	Inodes uint64
+}
+
+// SpaceError reports that the filesystem of the output folder has too little
+// space, or too few inodes, for the project
+type SpaceError struct {
+	OutputDir string
+	// Resource is either bytes or inodes
+	Resource  string
+	Needed    uint64
+	Available uint64
+}
+
+func (e SpaceError) Error() string {
+	return message(CodeNoSpace, e.OutputDir, e.Needed, e.Resource, e.Available)
+}
+
+func (e SpaceError) Code() Code {
+	return CodeNoSpace
+}
+
+// filesystemSpace is the space left on a filesystem, Inodes is zero where
+// the filesystem does not limit the number of files
+type filesystemSpace struct {
+	Bytes     uint64
+	Inodes    uint64
+	BlockSize uint64
+}
+
+// EstimateOutput estimates the space taken by the project created from the
+// template in inputDir.  Each file and folder takes at least one block of
+// blockSize bytes, and text files are assumed to be the same size once
+// rendered.
+func EstimateOutput(inputDir string, settings Settings, blockSize uint64) (OutputEstimate, error) {
+	files, targets, err := selectFiles(inputDir, settings)
+	if err != nil {
+		return OutputEstimate{}, err
+	}
+	return estimateFiles(inputDir, files, targets, blockSize)
+}
+
+func estimateFiles(inputDir string, files []SourceFile, targets []string, blockSize uint64) (OutputEstimate, error) {
+	if blockSize == 0 {
+		blockSize = 1
+	}
+	blocks := func(size uint64) uint64 {
+		if size == 0 {
+			return 0
+		}
+		return (size + blockSize - 1) / blockSize * blockSize
+	}
+
+	estimate := OutputEstimate{}
+	folders := map[string]bool{}
+	for i, file := range files {
+		size := uint64(len(file.FileContent))
+		if file.FileContent == "" {
+			info, err := os.Stat(filepath.Join(inputDir, file.FilePath))
+			if err != nil {
+				return estimate, err
+			}
+			size = uint64(info.Size())
+		}
+		estimate.Bytes += blocks(size)
+		estimate.Inodes++
+		for dir := filepath.Dir(targets[i]); dir != "." && !folders[dir]; dir = filepath.Dir(dir) {
+			folders[dir] = true
+		}
+	}
+	estimate.Bytes += uint64(len(folders)) * blockSize
+	estimate.Inodes += uint64(len(folders))
+	return estimate, nil
+}
+
+// Fail before any file is written when the filesystem of outputDir has too
+// little space for the files selected from inputDir.  Where the free space
+// cannot be found the project is created regardless.
+func checkSpace(inputDir string, outputDir string, files []SourceFile, targets []string) error {
+	dir := outputDir
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	space, ok := freeSpace(dir)
+	if !ok {
+		return nil
+	}
+	estimate, err := estimateFiles(inputDir, files, targets, space.BlockSize)
+	if err != nil {
+		return err
+	}
+	if estimate.Bytes > space.Bytes {
+		return SpaceError{OutputDir: outputDir, Resource: "bytes", Needed: estimate.Bytes, Available: space.Bytes}
+	}
+	if space.Inodes != 0 && estimate.Inodes > space.Inodes {
+		return SpaceError{OutputDir: outputDir, Resource: "inodes", Needed: estimate.Inodes, Available: space.Inodes}
+	}
+	return nil
+}
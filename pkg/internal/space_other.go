@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package internal
+
+// The free space of a filesystem is not found on this platform
+func freeSpace(dir string) (filesystemSpace, bool) {
+	return filesystemSpace{}, false
+}
@@ -0,0 +1,50 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testSpace(t *testing.T, when spec.G, it spec.S) {
+	var inputDir string
+
+	it.Before(func() {
+		inputDir, _ = os.MkdirTemp("", "test")
+		os.MkdirAll(filepath.Join(inputDir, "{{.Name}}", "docs"), 0755)
+		os.WriteFile(filepath.Join(inputDir, internal.PromptFile), []byte(""), 0644)
+		os.WriteFile(filepath.Join(inputDir, "{{.Name}}", "main.go"), []byte("package main"), 0644)
+		os.WriteFile(filepath.Join(inputDir, "{{.Name}}", "docs", "index.md"), []byte("# {{.Name}}"), 0644)
+		os.WriteFile(filepath.Join(inputDir, "logo.bin"), make([]byte, 5000), 0644)
+	})
+
+	it.After(func() {
+		os.RemoveAll(inputDir)
+	})
+
+	when("the output of a template is estimated", func() {
+		it("rounds each file and folder up to whole blocks", func() {
+			estimate, err := internal.EstimateOutput(inputDir, internal.Settings{}, 4096)
+			h.AssertNil(t, err)
+			h.AssertEq(t, estimate, internal.OutputEstimate{Bytes: 6 * 4096, Inodes: 5})
+		})
+
+		it("counts bytes when no block size is known", func() {
+			estimate, err := internal.EstimateOutput(inputDir, internal.Settings{}, 0)
+			h.AssertNil(t, err)
+			h.AssertEq(t, estimate, internal.OutputEstimate{Bytes: 12 + 11 + 5000 + 2, Inodes: 5})
+		})
+	})
+
+	when("the output folder has too little space", func() {
+		it("reports the space needed and available", func() {
+			err := internal.SpaceError{OutputDir: "out", Resource: "bytes", Needed: 8192, Available: 4096}
+			h.AssertEq(t, err.Error(), "not enough space to create the project in out: about 8192 bytes are needed but only 4096 are available")
+		})
+	})
+}
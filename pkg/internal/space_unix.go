@@ -0,0 +1,23 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package internal
+
+import "syscall"
+
+// The space left on the filesystem of dir for an unprivileged user
+func freeSpace(dir string) (filesystemSpace, bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return filesystemSpace{}, false
+	}
+	space := filesystemSpace{
+		Bytes:     uint64(stat.Bavail) * uint64(stat.Bsize),
+		BlockSize: uint64(stat.Bsize),
+	}
+	// filesystems that allocate inodes as they are needed report none
+	if stat.Files != 0 {
+		space.Inodes = uint64(stat.Ffree)
+	}
+	return space, true
+}
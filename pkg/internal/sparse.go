@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// Clone only the sub path of a repository using a partial clone, which
+// fetches no file contents, and a sparse checkout of the sub path.  go-git
+// supports neither, so the git command line is used.
+func sparseClone(url string, tmpDir string, opts FetchOptions) error {
+	// a ref is given to git checkout, which cannot separate it from options
+	if strings.HasPrefix(opts.Ref, "-") {
+		return fmt.Errorf("ref %s must not start with -", opts.Ref)
+	}
+	gitCmd, err := exec.LookPath("git")
+	if err != nil {
+		return err
+	}
+	// configuration is passed in the environment so that credentials are not
+	// visible in the process list
+	config := [][2]string{}
+	if auth, ok := FindHTTPAuth(url, opts).(*githttp.BasicAuth); ok {
+		credentials := base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		config = append(config, [2]string{"http.extraHeader", "Authorization: Basic " + credentials})
+	}
+	if endpoint, err := transport.NewEndpoint(url); err == nil && endpoint.Protocol == "ssh" {
+		config = append(config, [2]string{"core.sshCommand", "ssh -o " + sshHostKeyOption(opts.HostKeyChecking)})
+	}
+	if opts.Proxy != "" {
+		config = append(config, [2]string{"http.proxy", opts.Proxy})
+	}
+	if opts.CABundle != "" {
+		// git trusts only the bundle, rather than the bundle and the system
+		// certificates, a failed sparse clone falls back to a full clone
+		config = append(config, [2]string{"http.sslCAInfo", opts.CABundle})
+	}
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0", fmt.Sprintf("GIT_CONFIG_COUNT=%d", len(config)))
+	for i, c := range config {
+		env = append(env, fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, c[0]), fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, c[1]))
+	}
+	progress := newProgressWriter(url, opts)
+	verbosity := "--quiet"
+	if progress != nil {
+		verbosity = "--progress"
+	}
+	run := func(args ...string) error {
+		cmd := exec.Command(gitCmd, args...)
+		cmd.Env = env
+		output := bytes.Buffer{}
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		if progress != nil {
+			cmd.Stderr = io.MultiWriter(&output, progress)
+		}
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(output.String()))
+		}
+		return nil
+	}
+
+	cloneArgs := []string{"clone", verbosity, "--filter=blob:none", "--no-checkout"}
+	if opts.Ref == "" {
+		cloneArgs = append(cloneArgs, "--depth", "1")
+	}
+	// -- ends the options, so that a url starting with - is not an option
+	if err := run(append(cloneArgs, "--", url, tmpDir)...); err != nil {
+		return err
+	}
+	if err := run("-C", tmpDir, "sparse-checkout", "init", "--cone"); err != nil {
+		return err
+	}
+	if err := run("-C", tmpDir, "sparse-checkout", "set", "--", opts.SubPath); err != nil {
+		return err
+	}
+	checkoutArgs := []string{"-C", tmpDir, "checkout", verbosity}
+	if opts.Ref != "" {
+		checkoutArgs = append(checkoutArgs, opts.Ref)
+	}
+	if err := run(checkoutArgs...); err != nil {
+		return err
+	}
+	if opts.Submodules {
+		return run("-C", tmpDir, "submodule", "update", "--init", "--recursive")
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package internal_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testSparseClone(t *testing.T, when spec.G, it spec.S) {
+	var (
+		repoDir string
+		tmpDir  string
+	)
+
+	it.Before(func() {
+		repoDir, _ = os.MkdirTemp("", "test")
+		tmpDir, _ = os.MkdirTemp("", "test")
+
+		os.MkdirAll(filepath.Join(repoDir, "go", "cli"), 0755)
+		os.MkdirAll(filepath.Join(repoDir, "python"), 0755)
+		os.WriteFile(filepath.Join(repoDir, "go", "cli", "prompts.toml"), []byte{}, 0600)
+		os.WriteFile(filepath.Join(repoDir, "python", "prompts.toml"), []byte{}, 0600)
+		for _, args := range [][]string{
+			{"init", "--quiet"},
+			{"add", "--all"},
+			{"-c", "user.name=scafall", "-c", "user.email=scafall@example.com", "commit", "--quiet", "--message", "templates"},
+		} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = repoDir
+			h.AssertNil(t, cmd.Run())
+		}
+	})
+
+	it.After(func() {
+		os.RemoveAll(repoDir)
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a sub path of a repository is requested", func() {
+		it("checks out only the sub path", func() {
+			root, err := internal.URLToFs("file://"+repoDir, tmpDir, internal.FetchOptions{SubPath: "go/cli"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, root, filepath.Join(tmpDir, "go", "cli"))
+			_, err = os.Stat(filepath.Join(root, internal.PromptFile))
+			h.AssertNil(t, err)
+			_, err = os.Stat(filepath.Join(tmpDir, "python"))
+			h.AssertNotNil(t, err)
+		})
+
+		it("refuses a ref that git would read as an option", func() {
+			_, err := internal.URLToFs("file://"+repoDir, tmpDir, internal.FetchOptions{SubPath: "go/cli", Ref: "--detach"})
+			h.AssertNotNil(t, err)
+		})
+	})
+}
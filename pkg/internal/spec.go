@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// Job is a single scaffold described in a spec file
+type Job struct {
+	URL          string            `toml:"url"`
+	SubPath      string            `toml:"sub-path"`
+	Template     string            `toml:"template"`
+	OutputFolder string            `toml:"output-folder"`
+	Arguments    map[string]string `toml:"arguments"`
+}
+
+// Spec is a list of scaffold jobs that are executed in a single invocation
+type Spec struct {
+	Parallel bool  `toml:"parallel"`
+	Jobs     []Job `toml:"scaffold"`
+}
+
+func ReadSpec(specFile string) (Spec, error) {
+	spec := Spec{}
+	specData, err := ReadFile(specFile)
+	if err != nil {
+		return spec, err
+	}
+
+	if _, err := toml.Decode(specData, &spec); err != nil {
+		return spec, errors.Wrap(err, fmt.Sprintf("%s file does not match required format", specFile))
+	}
+
+	for i, job := range spec.Jobs {
+		if job.URL == "" {
+			return spec, fmt.Errorf("%s file contains scaffold %d with missing required field; url required", specFile, i+1)
+		}
+		if job.Arguments == nil {
+			spec.Jobs[i].Arguments = map[string]string{}
+		}
+	}
+	return spec, nil
+}
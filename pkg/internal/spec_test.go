@@ -0,0 +1,61 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testReadSpec(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir   string
+		specFile string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+		specFile = filepath.Join(tmpDir, "spec.toml")
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("Reading a spec file", func() {
+		it("reads each scaffold job", func() {
+			content := `parallel = true
+
+[[scaffold]]
+url = "testdata/one"
+output-folder = "one"
+
+[[scaffold]]
+url = "testdata/two"
+sub-path = "two"
+arguments = { Foo = "Bar" }
+`
+			os.WriteFile(specFile, []byte(content), 0600)
+
+			s, err := internal.ReadSpec(specFile)
+			h.AssertNil(t, err)
+			h.AssertTrue(t, s.Parallel)
+			h.AssertEq(t, len(s.Jobs), 2)
+			h.AssertEq(t, s.Jobs[0].OutputFolder, "one")
+			h.AssertEq(t, s.Jobs[0].Arguments, map[string]string{})
+			h.AssertEq(t, s.Jobs[1].SubPath, "two")
+			h.AssertEq(t, s.Jobs[1].Arguments, map[string]string{"Foo": "Bar"})
+		})
+
+		it("fails when a job has no url", func() {
+			os.WriteFile(specFile, []byte("[[scaffold]]\noutput-folder = \"one\""), 0600)
+
+			_, err := internal.ReadSpec(specFile)
+			h.AssertError(t, err, "contains scaffold 1 with missing required field; url required")
+		})
+	})
+}
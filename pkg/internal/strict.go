@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// unknownVariablePattern matches a variable used by a gotemplate action, such
+// as {{.name}} or {{ .name | upper }}
+var unknownVariablePattern = regexp.MustCompile(`{{[ \t]*\.(\w+)`)
+
+// VariableUse is a variable used by a file of a template
+type VariableUse struct {
+	FilePath string
+	Variable string
+}
+
+// UndeclaredVariableError lists the variables used by the files of a template
+// that are neither prompted for in prompts.toml nor given as arguments
+type UndeclaredVariableError struct {
+	Uses []VariableUse
+}
+
+func (e UndeclaredVariableError) Error() string {
+	var b strings.Builder
+	b.WriteString(message(CodeUndeclaredVars))
+	for _, use := range e.Uses {
+		fmt.Fprintf(&b, "\n\t%s: %s", use.FilePath, use.Variable)
+	}
+	return b.String()
+}
+
+func (e UndeclaredVariableError) Code() Code {
+	return CodeUndeclaredVars
+}
+
+// List the variables used in content that are not in vars, in the order in
+// which they are first used.  These variables are left in place when content
+// is rendered with engine.
+func unknownVariables(engine string, vars map[string]string, content string) []string {
+	context := templateContext(vars)
+	pattern := unknownVariablePattern
+	if engine == PlaceholderEngine {
+		pattern = placeholderPattern
+	}
+
+	unknown := []string{}
+	seen := map[string]bool{}
+	for _, match := range pattern.FindAllStringSubmatch(content, -1) {
+		name := match[1]
+		if _, ok := context[name]; ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		unknown = append(unknown, name)
+	}
+	return unknown
+}
+
+// CheckUndeclared fails with an UndeclaredVariableError when the paths or the
+// text of the files of the template in inputDir use variables that are not in
+// vars, so that a mistyped variable is not left in the output
+func CheckUndeclared(inputDir string, vars map[string]string, settings Settings) error {
+	files, targets, err := selectFiles(inputDir, settings)
+	if err != nil {
+		return err
+	}
+	uses := []VariableUse{}
+	for i, file := range files {
+		for _, name := range unknownVariables(settings.Engine, vars, targets[i]+"\n"+file.FileContent) {
+			uses = append(uses, VariableUse{FilePath: file.FilePath, Variable: name})
+		}
+	}
+	if len(uses) != 0 {
+		return UndeclaredVariableError{Uses: uses}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package internal_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testStrictVariables(t *testing.T, when spec.G, it spec.S) {
+	var inputDir string
+
+	it.Before(func() {
+		inputDir, _ = os.MkdirTemp("", "test")
+		os.MkdirAll(filepath.Join(inputDir, "{{.Nmae}}"), 0755)
+		os.WriteFile(filepath.Join(inputDir, "{{.Nmae}}", "main.go"), []byte("package {{.Name_snake}} // {{ .Owner }} {{.Owner}}"), 0644)
+		os.WriteFile(filepath.Join(inputDir, "index.md"), []byte("# {{ .Name }}, {{ .__Year }}"), 0644)
+	})
+
+	it.After(func() {
+		os.RemoveAll(inputDir)
+	})
+
+	when("every variable is declared", func() {
+		it("succeeds", func() {
+			vars := map[string]string{"Name": "shop", "Nmae": "shop", "Owner": "me"}
+			err := internal.CheckUndeclared(inputDir, vars, internal.Settings{})
+			h.AssertNil(t, err)
+		})
+	})
+
+	when("variables are not declared", func() {
+		it("lists each variable once with the file that uses it", func() {
+			err := internal.CheckUndeclared(inputDir, map[string]string{"Name": "shop"}, internal.Settings{})
+			h.AssertNotNil(t, err)
+			var undeclared internal.UndeclaredVariableError
+			h.AssertTrue(t, errors.As(err, &undeclared))
+			h.AssertEq(t, undeclared.Uses, []internal.VariableUse{
+				{FilePath: filepath.Join("{{.Nmae}}", "main.go"), Variable: "Nmae"},
+				{FilePath: filepath.Join("{{.Nmae}}", "main.go"), Variable: "Owner"},
+			})
+			h.AssertContains(t, err.Error(), "main.go: Nmae")
+		})
+
+		it("checks placeholders with the placeholder engine", func() {
+			os.WriteFile(filepath.Join(inputDir, "index.md"), []byte("# {{ Name }}, {{ Title }}"), 0644)
+			os.RemoveAll(filepath.Join(inputDir, "{{.Nmae}}"))
+			err := internal.CheckUndeclared(inputDir, map[string]string{"Name": "shop"}, internal.Settings{Engine: internal.PlaceholderEngine})
+			var undeclared internal.UndeclaredVariableError
+			h.AssertTrue(t, errors.As(err, &undeclared))
+			h.AssertEq(t, undeclared.Uses, []internal.VariableUse{{FilePath: "index.md", Variable: "Title"}})
+		})
+	})
+}
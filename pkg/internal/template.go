@@ -3,63 +3,207 @@ package internal
 import (
 	"fmt"
 	"io"
+	"os"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/BurntSushi/toml"
 	"github.com/pkg/errors"
 )
 
 type Prompt struct {
-	Name     string   `toml:"name" binding:"required"`
-	Prompt   string   `toml:"prompt" binding:"required"`
-	Required bool     `toml:"required"`
-	Default  string   `toml:"default"`
-	Choices  []string `toml:"choices,omitempty"`
+	Name     string `toml:"name" binding:"required"`
+	Prompt   string `toml:"prompt" binding:"required"`
+	Help     string `toml:"help,omitempty"`
+	Required bool   `toml:"required"`
+	Default  string `toml:"default"`
+	// Choices are decoded by decodeChoices, as a choice may be written as a
+	// table with a label shown to the end-user in place of its value
+	Choices []string `toml:"-"`
+	// Labels are shown to the end-user in place of the choice at the same
+	// index, a choice without a label is shown as it is
+	Labels []string `toml:"-"`
+	Type   string   `toml:"type,omitempty"`
+	Format string   `toml:"format,omitempty"`
+	// Pattern is a regular expression that an answer must match in full,
+	// PatternMessage is reported when an answer does not match
+	Pattern        string `toml:"pattern,omitempty"`
+	PatternMessage string `toml:"pattern-message,omitempty"`
+	// ErrorMessage is reported in place of the reason that an answer is
+	// invalid, such as an empty answer to a required prompt or a number that
+	// is not a number.  PatternMessage takes precedence for the pattern.
+	ErrorMessage string `toml:"error-message,omitempty"`
+	// When is a condition on the answers to earlier prompts, the prompt is
+	// only asked when the condition is true
+	When string `toml:"when,omitempty"`
+	// Group names the section in which the prompt is asked
+	Group string `toml:"group,omitempty"`
+	// Record is false for a prompt whose answer, such as a password, must
+	// not be recorded in plans or reports
+	Record *bool `toml:"record,omitempty"`
+	// Exists is one of PathExistence, requiring the answer to a path prompt
+	// to exist or not to exist
+	Exists string `toml:"exists,omitempty"`
+	// MinLength and MaxLength limit the number of characters of an answer,
+	// or of each item of a list, a MaxLength of 0 is unlimited
+	MinLength int `toml:"min-length,omitempty"`
+	MaxLength int `toml:"max-length,omitempty"`
+	// Translations localize the text of the prompt, keyed by a language such
+	// as fr or pt-BR
+	Translations map[string]Translation `toml:"labels,omitempty"`
+}
+
+// Recorded reports whether the answer to the prompt may be recorded
+func (p Prompt) Recorded() bool {
+	return p.Record == nil || *p.Record
+}
+
+// Unrecorded lists the names of the prompts whose answers must not be
+// recorded
+func Unrecorded(prompts []Prompt) []string {
+	names := []string{}
+	for _, prompt := range prompts {
+		if !prompt.Recorded() {
+			names = append(names, prompt.Name)
+		}
+	}
+	return names
+}
+
+// RecordedValues returns a copy of values without the answers to prompts
+// that must not be recorded
+func RecordedValues(values map[string]string, prompts []Prompt) map[string]string {
+	recorded := make(map[string]string, len(values))
+	for name, value := range values {
+		recorded[name] = value
+	}
+	for _, name := range Unrecorded(prompts) {
+		delete(recorded, name)
+	}
+	return recorded
 }
 
 type Prompts struct {
-	Prompts []Prompt `toml:"prompt"`
+	// Requires lists the capabilities of scafall needed by the template
+	Requires []string `toml:"requires,omitempty"`
+	Prompts  []Prompt `toml:"prompt"`
+	Settings Settings `toml:"settings"`
 }
 
 type Template interface {
 	Arguments() []Prompt
+	Settings() Settings
+	Requires() []string
 	Ask(...survey.AskOpt) (map[string]string, error)
+	Defaults() (map[string]string, error)
+	Suggest(facts map[string]string) Template
+	Resolve(providers ValueProviders) (Template, error)
+	Validate(validators Validators) Template
+	MatchChoices(matching ChoiceMatching) Template
+	Localize(language string) Template
+	AddFunctions(functions Functions) Template
+	Answer(answers map[string]interface{}) (Template, error)
 }
 
 type TemplateImpl struct {
 	TPrompts   Prompts
-	TQuestions []*survey.Question
 	TArguments map[string]string
 	TOverrides map[string]string
+	TFacts     map[string]string
+	// TAnswers are answers provided before prompting, already checked and
+	// normalized by Answer
+	TAnswers map[string]string
+	// TValidators check answers in addition to the rules of the prompts
+	TValidators Validators
+	// TMatching controls how provided values are matched against choices
+	TMatching ChoiceMatching
+	// TLanguage is the language in which prompts are asked
+	TLanguage string
+}
+
+// MissingValuesError lists every required variable that was neither provided
+// nor has a default value when prompting is disabled
+type MissingValuesError struct {
+	Names []string
+}
+
+func (e MissingValuesError) Error() string {
+	return message(e.Code(), strings.Join(e.Names, ", "))
+}
+
+func (e MissingValuesError) Code() Code {
+	if len(e.Names) == 1 {
+		return CodeMissingValue
+	}
+	return CodeMissingValues
 }
 
+// choicesPageSize is the number of choices shown at once, more choices are
+// reached by scrolling or by typing to filter the choices
+const choicesPageSize = 10
+
 func NewQuestion(prompt Prompt) survey.Question {
 	p := survey.Question{
 		Name: prompt.Name,
 	}
-	if len(prompt.Choices) != 0 {
+	if len(prompt.Choices) != 0 && prompt.Type == ListType {
+		p.Prompt = &survey.MultiSelect{
+			Message:  prompt.Prompt,
+			Help:     prompt.Help,
+			Options:  prompt.Options(),
+			Default:  prompt.labelsOf(SplitList(prompt.Default)),
+			PageSize: choicesPageSize,
+		}
+	} else if len(prompt.Choices) != 0 {
 		sselect := survey.Select{
-			Message: prompt.Prompt,
-			Options: prompt.Choices,
-			Default: prompt.Choices[0],
+			Message:  prompt.Prompt,
+			Help:     prompt.Help,
+			Options:  prompt.Options(),
+			Default:  prompt.Options()[0],
+			PageSize: choicesPageSize,
 		}
 		if prompt.Default != "" {
-			sselect.Default = prompt.Default
+			sselect.Default = prompt.labelsOf([]string{prompt.Default})[0]
 		}
 		p.Prompt = &sselect
+	} else if prompt.Type == TextType || prompt.Type == ListType {
+		p.Prompt = &multiline{Multiline: survey.Multiline{
+			Message: prompt.Prompt,
+			Default: prompt.Default,
+			Help:    prompt.Help,
+		}, list: prompt.Type == ListType}
 	} else {
 		input := survey.Input{
 			Message: prompt.Prompt,
+			Help:    prompt.Help,
 		}
 		if prompt.Default != "" {
 			input.Default = prompt.Default
 		}
+		if prompt.Type == PathType {
+			input.Suggest = suggestPaths(prompt)
+		}
 		p.Prompt = &input
 	}
 
+	validators := []survey.Validator{}
 	if prompt.Required {
-		p.Validate = survey.ComposeValidators(survey.Required)
+		validators = append(validators, func(ans interface{}) error {
+			return prompt.invalid(survey.Required(ans))
+		})
+	}
+	if len(prompt.Choices) == 0 && (prompt.Type == NumberType || prompt.Type == DateType || prompt.Type == PathType || prompt.Pattern != "" || prompt.MinLength != 0 || prompt.MaxLength != 0) {
+		locale := CurrentLocale()
+		validators = append(validators, func(ans interface{}) error {
+			value := fmt.Sprint(ans)
+			_, err := prompt.check(value, TypedLocale(prompt, value, locale))
+			return err
+		})
+	}
+	if len(validators) != 0 {
+		p.Validate = survey.ComposeValidators(validators...)
 	}
 	return p
 }
@@ -78,29 +222,23 @@ func NewTemplate(promptFile io.ReadCloser, arguments map[string]string, override
 			return nil, err
 		}
 
-		if _, err := toml.Decode(string(promptData), &prompts); err != nil {
-			return nil, errors.Wrap(err, fmt.Sprintf("%s file does not match required format", promptFile))
+		name := PromptFile
+		if f, ok := promptFile.(interface{ Name() string }); ok {
+			name = f.Name()
 		}
-	}
-
-	questions := make([]*survey.Question, 0)
-	for _, prompt := range prompts.Prompts {
-		if prompt.Name == "" || prompt.Prompt == "" {
-			return nil, fmt.Errorf("%s file contains prompt with missing required field; name or prompt required", promptFile)
+		if err := validatePromptFile(string(promptData), name); err != nil {
+			return nil, err
 		}
-
-		// Remove question from survey if an argument has been provided
-		_, arg := arguments[prompt.Name]
-		_, ovr := overrides[prompt.Name]
-		if !arg && !ovr {
-			question := NewQuestion(prompt)
-			questions = append(questions, &question)
+		if _, err := toml.Decode(string(promptData), &prompts); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("%s file does not match required format", name))
+		}
+		if err := decodeChoices(string(promptData), prompts.Prompts); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("%s file does not match required format", name))
 		}
 	}
 
 	return TemplateImpl{
 		TPrompts:   prompts,
-		TQuestions: questions,
 		TArguments: arguments,
 		TOverrides: overrides,
 	}, nil
@@ -110,26 +248,364 @@ func (t TemplateImpl) Arguments() []Prompt {
 	return t.TPrompts.Prompts
 }
 
-func (t TemplateImpl) Ask(opts ...survey.AskOpt) (map[string]string, error) {
-	response := map[string]interface{}{}
-	if len(t.TQuestions) != 0 {
-		err := survey.Ask(t.TQuestions, &response, opts...)
+func (t TemplateImpl) Settings() Settings {
+	return t.TPrompts.Settings
+}
+
+func (t TemplateImpl) Requires() []string {
+	return t.TPrompts.Requires
+}
+
+// Suggest facts about an existing project, as found by Introspect, as the
+// defaults of matching prompts
+func (t TemplateImpl) Suggest(facts map[string]string) Template {
+	t.TFacts = facts
+	return t
+}
+
+// Resolve the provider:key references among the arguments and overrides
+func (t TemplateImpl) Resolve(providers ValueProviders) (Template, error) {
+	arguments, err := providers.ResolveValues(t.TArguments)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := providers.ResolveValues(t.TOverrides)
+	if err != nil {
+		return nil, errors.Wrap(err, OverrideFile)
+	}
+	t.TArguments = arguments
+	t.TOverrides = overrides
+	return t, nil
+}
+
+// Validate answers with validators, keyed by the name of their prompt, as
+// well as with the rules of the prompts
+func (t TemplateImpl) Validate(validators Validators) Template {
+	t.TValidators = validators
+	return t
+}
+
+// Match arguments, overrides, answers and facts against the choices of the
+// prompts with matching, a matched value is replaced by its choice
+func (t TemplateImpl) MatchChoices(matching ChoiceMatching) Template {
+	t.TMatching = matching
+	return t
+}
+
+// Add functions to those that the files, paths and prompts of the template
+// can call
+func (t TemplateImpl) AddFunctions(functions Functions) Template {
+	t.TPrompts.Settings.Functions = functions
+	return t
+}
+
+// Ask prompts in language using the translations of the template
+func (t TemplateImpl) Localize(language string) Template {
+	t.TLanguage = language
+	return t
+}
+
+// The names of the list prompts
+func (t TemplateImpl) lists() []string {
+	lists := []string{}
+	for _, prompt := range t.TPrompts.Prompts {
+		if prompt.Type == ListType {
+			lists = append(lists, prompt.Name)
+		}
+	}
+	return lists
+}
+
+// Render the templated parts of a prompt using the answers to earlier prompts
+// with the engine of the settings.  Labels, such as Port for {{.Name}}, and
+// defaults, such as {{.ProjectName}}-api, are rendered so that they can be
+// derived from earlier answers.
+func renderPrompt(prompt Prompt, answers map[string]string, settings Settings) (Prompt, error) {
+	label, err := render(settings, prompt.Prompt, answers)
+	if err != nil {
+		return prompt, errors.Wrap(err, fmt.Sprintf("failed to render prompt of %s", prompt.Name))
+	}
+	prompt.Prompt = label
+	help, err := render(settings, prompt.Help, answers)
+	if err != nil {
+		return prompt, errors.Wrap(err, fmt.Sprintf("failed to render help of %s", prompt.Name))
+	}
+	prompt.Help = help
+
+	defaultRendered := false
+	choices := make([]string, len(prompt.Choices))
+	for i, choice := range prompt.Choices {
+		rendered, err := render(settings, choice, answers)
 		if err != nil {
+			return prompt, errors.Wrap(err, fmt.Sprintf("failed to render choice %s of %s", choice, prompt.Name))
+		}
+		if !defaultRendered && prompt.Default == choice {
+			prompt.Default = rendered
+			defaultRendered = true
+		}
+		choices[i] = rendered
+	}
+	prompt.Choices = choices
+	labels := make([]string, len(prompt.Labels))
+	for i, label := range prompt.Labels {
+		rendered, err := render(settings, label, answers)
+		if err != nil {
+			return prompt, errors.Wrap(err, fmt.Sprintf("failed to render label %s of %s", label, prompt.Name))
+		}
+		labels[i] = rendered
+	}
+	if prompt.Labels != nil {
+		prompt.Labels = labels
+	}
+	if !defaultRendered && prompt.Default != "" {
+		rendered, err := render(settings, prompt.Default, answers)
+		if err != nil {
+			return prompt, errors.Wrap(err, fmt.Sprintf("failed to render default %s of %s", prompt.Default, prompt.Name))
+		}
+		prompt.Default = rendered
+	}
+	return prompt, nil
+}
+
+// groupHeadingTemplate introduces the prompts of a group
+var groupHeadingTemplate = `{{"\n"}}{{color "default+hbu"}}{{.}}{{color "reset"}}{{"\n"}}`
+
+// invalidArgumentTemplate explains why an argument is asked for again, in the
+// way that survey shows an invalid answer
+var invalidArgumentTemplate = `{{color "red"}}X {{.}}{{color "reset"}}{{"\n"}}`
+
+func (t TemplateImpl) Ask(opts ...survey.AskOpt) (map[string]string, error) {
+	// the options are applied here to find where headings are written
+	options := survey.AskOptions{Stdio: terminal.Stdio{In: os.Stdin, Out: os.Stdout, Err: os.Stderr}}
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
 			return nil, err
 		}
 	}
 
-	answers := make(map[string]string, len(response))
-	for key, value := range response {
-		val := ""
-		core.WriteAnswer(&val, key, value)
-		answers[key] = val
+	group := ""
+	width := terminalWidth(options.Stdio.Out)
+	ask := func(prompt Prompt) (string, error) {
+		// headings are only shown for groups with a prompt that is asked
+		if prompt.Group != group {
+			group = prompt.Group
+			if group != "" {
+				heading, _, err := core.RunTemplate(groupHeadingTemplate, WrapText(group, width))
+				if err != nil {
+					return "", err
+				}
+				fmt.Fprint(options.Stdio.Out, heading)
+			}
+		}
+
+		// the choices of a prompt keep their order, so a truncated label
+		// still gives its choice
+		prompt = prompt.fit(width)
+		question := NewQuestion(prompt)
+		t.TValidators.addTo(&question, prompt)
+		response := map[string]interface{}{}
+		err := PromptError(survey.Ask([]*survey.Question{&question}, &response, opts...))
+		if errors.Is(err, ErrPromptAborted) {
+			return "", err
+		}
+		if err != nil {
+			return "", AskError{Prompt: prompt.Name, Err: err}
+		}
+		return answerValue(prompt, response[prompt.Name]), nil
+	}
+	reask := func(prompt Prompt, invalid error) (string, error) {
+		text, _, err := core.RunTemplate(invalidArgumentTemplate, message(CodeInvalidArgument, prompt.Name, invalid))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprint(options.Stdio.Out, text)
+		return ask(prompt)
+	}
+	return t.answer(ask, reask)
+}
+
+// The value of an answer given by the end-user to prompt, the options chosen
+// from a multi-select are written one per line.  An option is the label of a
+// choice, so the choice is found by its index.
+func answerValue(prompt Prompt, ans interface{}) string {
+	switch ans := ans.(type) {
+	case core.OptionAnswer:
+		return prompt.choiceOf(ans)
+	case []core.OptionAnswer:
+		items := make([]string, len(ans))
+		for i, option := range ans {
+			items[i] = prompt.choiceOf(option)
+		}
+		return strings.Join(items, "\n")
 	}
+	value := ""
+	core.WriteAnswer(&value, "", ans)
+	return value
+}
+
+// ErrPromptAborted is returned when the end-user dismisses a prompt, or when
+// stdin is closed before a prompt is answered
+var ErrPromptAborted = NewError(CodePromptAborted)
+
+// PromptError reports an interrupted or unanswerable prompt as
+// ErrPromptAborted, other errors are returned unchanged
+func PromptError(err error) error {
+	if errors.Is(err, terminal.InterruptErr) || errors.Is(err, io.EOF) {
+		return ErrPromptAborted
+	}
+	return err
+}
+
+// AskError reports the prompt that could not be asked, such as when there is
+// no terminal to ask it on
+type AskError struct {
+	Prompt string
+	Err    error
+}
+
+func (e AskError) Error() string {
+	return message(CodePromptFailed, e.Prompt, e.Err)
+}
+
+func (e AskError) Code() Code {
+	return CodePromptFailed
+}
+
+func (e AskError) Unwrap() error {
+	return e.Err
+}
+
+// Defaults answers every prompt that is not provided as an argument with its
+// default value, without prompting the end-user
+func (t TemplateImpl) Defaults() (map[string]string, error) {
+	missing := []string{}
+	values, err := t.answer(func(prompt Prompt) (string, error) {
+		switch {
+		case prompt.Default != "":
+			return prompt.Default, nil
+		case len(prompt.Choices) != 0 && prompt.Type != ListType:
+			return prompt.Choices[0], nil
+		case prompt.Required:
+			missing = append(missing, prompt.Name)
+		}
+		return "", nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) != 0 {
+		return nil, MissingValuesError{Names: missing}
+	}
+	return values, nil
+}
+
+// Answer each prompt not provided as an argument using ask.  An argument that
+// is not a valid answer to its prompt is given to reask, together with the
+// reason it is invalid, when reask is not nil and fails otherwise.
+func (t TemplateImpl) answer(ask func(Prompt) (string, error), reask func(Prompt, error) (string, error)) (map[string]string, error) {
+	answers := map[string]string{}
 	for key, value := range t.TArguments {
 		answers[key] = value
 	}
 	for key, value := range t.TOverrides {
 		answers[key] = value
 	}
+	for key, value := range t.TAnswers {
+		answers[key] = value
+	}
+
+	// list variables are named so that templates are given their items
+	if lists := t.lists(); len(lists) != 0 {
+		answers[ListsVariable] = strings.Join(lists, ",")
+	}
+
+	// Prompts are asked in order so that earlier answers can be used in later prompts
+	locale := CurrentLocale()
+	for _, prompt := range t.TPrompts.Prompts {
+		if _, checked := t.TAnswers[prompt.Name]; checked {
+			continue
+		}
+		// arguments and defaults are written in canonical form, only the
+		// answers typed by the end-user are written in their locale
+		valueLocale := defaultLocale
+		value, provided := answers[prompt.Name]
+		if provided && len(prompt.Choices) != 0 && t.TMatching.Enabled() {
+			rendered, err := renderPrompt(prompt, answers, t.TPrompts.Settings)
+			if err != nil {
+				return nil, err
+			}
+			value, _ = t.TMatching.Match(rendered.Choices, value)
+		}
+		if !provided {
+			rendered, err := renderPrompt(prompt.Localize(t.TLanguage), answers, t.TPrompts.Settings)
+			if err != nil {
+				return nil, err
+			}
+			if fact, ok := FactForPrompt(t.TFacts, prompt.Name); ok {
+				if len(rendered.Choices) == 0 {
+					rendered.Default = fact
+				} else if choice, matched := t.TMatching.Match(rendered.Choices, fact); matched {
+					rendered.Default = choice
+				}
+			}
+			applies := true
+			if prompt.When != "" {
+				applies, err = EvalCondition(prompt.When, answers)
+				if err != nil {
+					return nil, errors.Wrap(err, fmt.Sprintf("invalid condition for %s", prompt.Name))
+				}
+			}
+			if applies {
+				value, err = ask(rendered)
+				if err != nil {
+					return nil, err
+				}
+				valueLocale = TypedLocale(rendered, value, locale)
+			} else {
+				// prompts that are not asked take their default value so that
+				// templates can still use them, the value is not checked as
+				// the end-user could not have given another
+				value = rendered.Default
+				if value == "" && len(rendered.Choices) != 0 && prompt.Type != ListType {
+					value = rendered.Choices[0]
+				}
+				if normalized, err := Normalize(prompt, value, defaultLocale); err == nil {
+					value = normalized
+				}
+				answers[prompt.Name] = value
+				continue
+			}
+		}
+
+		// the messages of a failed check are shown in the language of the prompt
+		localized := prompt.Localize(t.TLanguage)
+		normalized, err := t.check(localized, value, valueLocale)
+		if err != nil && provided && reask != nil {
+			// an invalid argument is asked for again rather than failing
+			rendered, renderErr := renderPrompt(localized, answers, t.TPrompts.Settings)
+			if renderErr != nil {
+				return nil, renderErr
+			}
+			value, err = reask(rendered, err)
+			if err != nil {
+				return nil, err
+			}
+			normalized, err = t.check(localized, value, TypedLocale(rendered, value, locale))
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("invalid value for %s", prompt.Name))
+		}
+		answers[prompt.Name] = normalized
+	}
 	return answers, nil
 }
+
+// Normalize value and check it against the prompt and the validators of the
+// template
+func (t TemplateImpl) check(prompt Prompt, value string, locale Locale) (string, error) {
+	normalized, err := prompt.check(value, locale)
+	if err == nil {
+		err = t.TValidators.Check(prompt.Name, normalized)
+	}
+	return normalized, err
+}
@@ -1,9 +1,13 @@
 package internal_test
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/buildpacks/scafall/pkg/internal"
@@ -36,11 +40,97 @@ func testReadPrompt(t *testing.T, when spec.G, it spec.S) {
 			h.AssertEq(t, len(template.Arguments()), 1)
 		})
 
+		it("reports the line of every problem", func() {
+			promptFile := io.NopCloser(strings.NewReader(`[[prompt]]
+name = "Language"
+prompt = "Which language"
+choices = ["go", "python"]
+default = "rust"
+
+[[prompt]]
+name = "Version"
+promt = "Which version"
+`))
+			_, err := internal.NewTemplate(promptFile, nil, nil)
+			var fileErr internal.PromptFileError
+			h.AssertTrue(t, errors.As(err, &fileErr))
+			h.AssertEq(t, fileErr.Problems, []internal.Problem{
+				{Line: 5, Key: "prompt.default", Index: 1, Message: "default rust of prompt Language is not one of its choices go, python"},
+				{Line: 7, Key: "prompt", Index: 2, Message: "prompt Version is missing required field prompt"},
+				{Line: 9, Key: "prompt.promt", Index: 2, Message: "unknown field promt in prompt Version; expected one of choices, default, error-message, exists, format, group, help, labels, max-length, min-length, name, pattern, pattern-message, prompt, record, required, type, when"},
+			})
+			h.AssertContains(t, err.Error(), "prompts.toml:9: prompt.2.promt: unknown field promt in prompt Version")
+		})
+
+		it("names prompts without a name by their position", func() {
+			promptFile := io.NopCloser(strings.NewReader(`[[prompt]]
+name = "Language"
+prompt = "Which language"
+
+[[prompt]]
+prompt = "Which version"
+
+[settings.provenance]
+fiel = "NOTICE"
+`))
+			_, err := internal.NewTemplate(promptFile, nil, nil)
+			var fileErr internal.PromptFileError
+			h.AssertTrue(t, errors.As(err, &fileErr))
+			h.AssertEq(t, fileErr.Problems[0].Message, "prompt 2 is missing required field name")
+			h.AssertEq(t, fileErr.Problems[0].Path(), "prompt.2")
+			h.AssertEq(t, fileErr.Problems[1].Key, "settings.provenance.fiel")
+			h.AssertEq(t, fileErr.Problems[1].Index, 0)
+		})
+
+		it("lists every conflicting prompt", func() {
+			promptFile := io.NopCloser(strings.NewReader(`[[prompt]]
+name = "Name"
+prompt = "Project name"
+
+[[prompt]]
+name = "Name"
+prompt = "Author name"
+
+[[prompt]]
+name = "__Url"
+prompt = "Where"
+
+[[prompt]]
+name = "go-version"
+prompt = "Which Go"
+`))
+			_, err := internal.NewTemplate(promptFile, nil, nil)
+			var fileErr internal.PromptFileError
+			h.AssertTrue(t, errors.As(err, &fileErr))
+			h.AssertEq(t, len(fileErr.Problems), 3)
+			h.AssertEq(t, fileErr.Problems[0].Line, 6)
+			h.AssertEq(t, fileErr.Problems[0].Message, "prompt Name is defined more than once; it is first defined by prompt 1 on line 1")
+			h.AssertEq(t, fileErr.Problems[1].Line, 10)
+			h.AssertEq(t, fileErr.Problems[2].Line, 14)
+		})
+
 		var incorrectPromptFiles = []string{
 			"incorrect",
 			"[[prompt]]",
 			"[[prompt]]\nname=\"test\"",
 			"[[prompt]]\nprompt=\"test\"",
+			"[[prompt]]\nname=\"test\"\nprompt=\"test\"\ndefualt=\"test\"",
+			"[[prompt]]\nname=\"test\"\nprompt=\"test\"\nchoices=true",
+			"[[prompt]]\nname=\"test\"\nprompt=\"test\"\nchoices=[\"a\", \"b\"]\ndefault=\"c\"",
+			"[[prompt]]\nname=\"test\"\nprompt=\"test\"\nchoices=[{label=\"A\"}, \"b\"]",
+			"[[prompt]]\nname=\"test\"\nprompt=\"test\"\nchoices=[{label=\"A\", value=\"a\", help=\"first\"}]",
+			"[[prompt]]\nname=\"test\"\nprompt=\"test\"\nchoices=[{label=\"A\", value=\"a\"}]\ndefault=\"A\"",
+			"[[prompt]]\nname=\"test\"\nprompt=\"test\"\n[[prompt]]\nname=\"test\"\nprompt=\"again\"",
+			"[[prompt]]\nname=\"__ScaffoldUrl\"\nprompt=\"test\"",
+			"[[prompt]]\nname=\"project-name\"\nprompt=\"test\"",
+			"[[prompt]]\nname=\"1st\"\nprompt=\"test\"",
+			"[settings]\nengine=\"jinja\"\n[[prompt]]\nname=\"test\"\nprompt=\"test\"",
+			"[[prompt]]\nname=\"test\"\nprompt=\"test\"\npattern=\"[a-z\"",
+			"[[prompt]]\nname=\"test\"\nprompt=\"test\"\npattern=\"[a-z]+\"\ndefault=\"Test\"",
+			"[[prompt]]\nname=\"test\"\nprompt=\"test\"\nwhen=\"test ==\"",
+			"[[prompt]]\nname=\"test\"\nprompt=\"test\"\nwhen=\"later == 'yes'\"\n[[prompt]]\nname=\"later\"\nprompt=\"later\"",
+			"[[prompt]]\nname=\"test\"\nprompt=\"test\"\ndefault=\"{{.later}}-api\"\n[[prompt]]\nname=\"later\"\nprompt=\"later\"",
+			"[[prompt]]\nname=\"a\"\nprompt=\"a\"\ngroup=\"CI\"\n[[prompt]]\nname=\"b\"\nprompt=\"b\"\n[[prompt]]\nname=\"c\"\nprompt=\"c\"\ngroup=\"CI\"",
 		}
 		for _, file := range incorrectPromptFiles {
 			var incorrectPromptFile = file
@@ -157,6 +247,45 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 		Choices: []string{"moo", "quack", "baa"},
 	}
 
+	templatedSelection := internal.Prompt{
+		Name:    "Noise",
+		Prompt:  "Choose a noise",
+		Choices: []string{"{{.Duck}}", "{{.Duck | upper}}"},
+	}
+	text := internal.Prompt{
+		Name:   "Licence",
+		Prompt: "Licence header",
+		Type:   internal.TextType,
+	}
+	textWithHelp := internal.Prompt{
+		Name:   "Licence",
+		Prompt: "Licence header",
+		Help:   "Added to the top of every source file",
+		Type:   internal.TextType,
+	}
+	grouped := internal.Prompt{
+		Name:   "Runner",
+		Prompt: "Which runner",
+		Group:  "CI",
+	}
+	templatedLabel := internal.Prompt{
+		Name:   "Volume",
+		Prompt: "How loud is {{.Duck}}",
+	}
+	multiSelection := internal.Prompt{
+		Name:    "Services",
+		Prompt:  "Which services",
+		Type:    internal.ListType,
+		Choices: []string{"api", "web", "worker"},
+		Default: "api",
+	}
+	labelled := internal.Prompt{
+		Name:    "Database",
+		Prompt:  "Which database",
+		Choices: []string{"postgres", "mysql"},
+		Labels:  []string{"PostgreSQL (recommended)", "MySQL"},
+	}
+
 	duckQuack := map[string]string{"Duck": "quack"}
 	testCases := []TestCase{
 		{
@@ -223,21 +352,93 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 			expected:  duckQuack,
 			arguments: duckQuack,
 		},
+		{
+			prompts: []internal.Prompt{prompt, templatedSelection},
+			text: func(c expectConsole) {
+				c.ExpectString("Make noise")
+				c.SendLine("quack")
+				c.ExpectString("Choose a noise")
+				c.ExpectString("QUACK")
+				c.SendLine("\x1b\x5b\x42\x0d")
+				c.ExpectEOF()
+			},
+			expected: map[string]string{"Duck": "quack", "Noise": "QUACK"},
+		},
+		{
+			prompts: []internal.Prompt{prompt, templatedLabel},
+			text: func(c expectConsole) {
+				c.ExpectString("Make noise")
+				c.SendLine("quack")
+				c.ExpectString("How loud is quack")
+				c.SendLine("very")
+				c.ExpectEOF()
+			},
+			expected: map[string]string{"Duck": "quack", "Volume": "very"},
+		},
+		{
+			prompts: []internal.Prompt{text},
+			text: func(c expectConsole) {
+				c.ExpectString("Licence header")
+				c.SendLine("Copyright 2022")
+				c.SendLine("  Apache-2.0")
+				c.SendLine("")
+				c.ExpectEOF()
+			},
+			expected: map[string]string{"Licence": "Copyright 2022\n  Apache-2.0"},
+		},
+		{
+			prompts: []internal.Prompt{textWithHelp},
+			text: func(c expectConsole) {
+				c.ExpectString("Licence header")
+				c.SendLine("?")
+				c.ExpectString("Added to the top of every source file")
+				c.SendLine("Copyright 2022")
+				c.SendLine("")
+				c.ExpectEOF()
+			},
+			expected: map[string]string{"Licence": "Copyright 2022"},
+		},
+		{
+			prompts: []internal.Prompt{prompt, grouped},
+			text: func(c expectConsole) {
+				c.ExpectString("Make noise")
+				c.SendLine("quack")
+				c.ExpectString("CI")
+				c.ExpectString("Which runner")
+				c.SendLine("ubuntu")
+				c.ExpectEOF()
+			},
+			expected: map[string]string{"Duck": "quack", "Runner": "ubuntu"},
+		},
+		// a space toggles the highlighted choice of a multi-select
+		{
+			prompts: []internal.Prompt{multiSelection},
+			text: func(c expectConsole) {
+				c.ExpectString("Which services")
+				c.SendLine("\x1b\x5b\x42 \x0d")
+				c.ExpectEOF()
+			},
+			expected: map[string]string{"Services": "api\nweb", internal.ListsVariable: "Services"},
+		},
+		// the label of a choice is shown and its value is answered
+		{
+			prompts: []internal.Prompt{labelled},
+			text: func(c expectConsole) {
+				c.ExpectString("PostgreSQL (recommended)")
+				c.SendLine("\x1b\x5b\x42")
+				c.ExpectEOF()
+			},
+			expected: map[string]string{"Database": "mysql"},
+		},
 	}
 
 	for _, test := range testCases {
 		currentCase := test
 		when("When the user is prompted", func() {
 			it("produces valid prompt values", func() {
-				questions := []*survey.Question{}
-				for _, p := range currentCase.prompts {
-					q := internal.NewQuestion(p)
-					questions = append(questions, &q)
-				}
 				prompts := internal.Prompts{Prompts: currentCase.prompts}
 				template := internal.TemplateImpl{
 					TPrompts:   prompts,
-					TQuestions: questions,
 					TArguments: currentCase.arguments,
 				}
 
@@ -248,4 +449,161 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 	}
+
+	when("required prompts have no value", func() {
+		promptFile := `[[prompt]]
+name = "project_name"
+prompt = "Project name"
+required = true
+
+[[prompt]]
+name = "owner"
+prompt = "Owner"
+required = true
+
+[[prompt]]
+name = "licence"
+prompt = "Licence"
+required = true
+default = "MIT"
+`
+		it("lists every missing variable", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), nil, nil)
+			h.AssertNil(t, err)
+			_, err = template.Defaults()
+			var missing internal.MissingValuesError
+			h.AssertTrue(t, errors.As(err, &missing))
+			h.AssertEq(t, missing.Names, []string{"project_name", "owner"})
+			h.AssertEq(t, err.Error(), "project_name, owner are required and have no default value")
+		})
+
+		it("succeeds once the variables are provided", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), map[string]string{"project_name": "shop", "owner": "ops"}, nil)
+			h.AssertNil(t, err)
+			values, err := template.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["licence"], "MIT")
+		})
+	})
+
+	when("a default uses an earlier answer", func() {
+		promptFile := `[[prompt]]
+name = "project_name"
+prompt = "Project name"
+default = "shop"
+
+[[prompt]]
+name = "service_name"
+prompt = "Service name"
+default = "{{ .project_name }}-api"
+`
+		it("renders the default against the earlier answer", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), map[string]string{"project_name": "cart"}, nil)
+			h.AssertNil(t, err)
+			values, err := template.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["service_name"], "cart-api")
+		})
+
+		it("renders the default against the earlier default", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), nil, nil)
+			h.AssertNil(t, err)
+			values, err := template.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["service_name"], "shop-api")
+		})
+	})
+
+	when("a prompt with constraints is not asked", func() {
+		promptFile := `[[prompt]]
+name = "use_database"
+prompt = "Database"
+choices = ["none", "postgres"]
+
+[[prompt]]
+name = "dbname"
+prompt = "Database name"
+min-length = 3
+when = "use_database == 'postgres'"
+`
+		it("takes its default without checking it", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), nil, nil)
+			h.AssertNil(t, err)
+			values, err := template.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["dbname"], "")
+		})
+
+		it("checks the value once it is asked", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), map[string]string{"use_database": "postgres"}, nil)
+			h.AssertNil(t, err)
+			_, err = template.Defaults()
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "invalid value for dbname")
+		})
+	})
+
+	when("the end-user writes numbers in another locale", func() {
+		promptFile := `[[prompt]]
+name = "replicas"
+prompt = "Replicas"
+type = "number"
+default = "1.000"
+
+[[prompt]]
+name = "ratio"
+prompt = "Ratio"
+type = "number"
+default = "0.5"
+`
+		it.Before(func() {
+			t.Setenv("LC_ALL", "de_DE.UTF-8")
+		})
+
+		it("reads the defaults in canonical form", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), nil, nil)
+			h.AssertNil(t, err)
+			values, err := template.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["replicas"], "1")
+			h.AssertEq(t, values["ratio"], "0.5")
+		})
+
+		it("reads the arguments in canonical form", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), map[string]string{"ratio": "1.5"}, nil)
+			h.AssertNil(t, err)
+			values, err := template.Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["ratio"], "1.5")
+		})
+	})
+
+	when("the end-user aborts a prompt", func() {
+		it("reports an interrupt as ErrPromptAborted", func() {
+			h.AssertTrue(t, errors.Is(internal.PromptError(terminal.InterruptErr), internal.ErrPromptAborted))
+		})
+
+		it("reports closed stdin as ErrPromptAborted", func() {
+			h.AssertTrue(t, errors.Is(internal.PromptError(io.EOF), internal.ErrPromptAborted))
+		})
+
+		it("reports a wrapped interrupt as ErrPromptAborted", func() {
+			err := fmt.Errorf("failed to read answer: %w", terminal.InterruptErr)
+			h.AssertTrue(t, errors.Is(internal.PromptError(err), internal.ErrPromptAborted))
+		})
+
+		it("leaves other errors unchanged", func() {
+			err := errors.New("no tty")
+			h.AssertTrue(t, errors.Is(internal.PromptError(err), err))
+		})
+	})
+
+	when("a prompt cannot be asked", func() {
+		it("names the prompt", func() {
+			cause := errors.New("no tty")
+			err := error(internal.AskError{Prompt: "project_name", Err: cause})
+			h.AssertEq(t, err.Error(), "failed to ask project_name: no tty")
+			h.AssertTrue(t, errors.Is(err, cause))
+		})
+	})
 }
@@ -5,26 +5,117 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/pkg/errors"
 
 	"github.com/gabriel-vasile/mimetype"
-
-	"github.com/buildpacks/scafall/pkg/internal/util"
 )
 
 const (
 	PromptFile           string = "prompts.toml"
 	OverrideFile         string = ".override.toml"
 	ReplacementDelimiter string = "{&{&"
-)
 
-var (
-	IgnoredNames       = []string{PromptFile, OverrideFile}
-	IgnoredDirectories = []string{".git", "node_modules"}
+	// DefaultRenderTimeout bounds the time taken to render each file
+	DefaultRenderTimeout time.Duration = time.Minute
 )
 
+// IsIgnoredName reports whether a file of a template configures scafall, so
+// is not rendered.  The names are constant so that templates can be rendered
+// concurrently.
+func IsIgnoredName(name string) bool {
+	return name == PromptFile || name == OverrideFile
+}
+
+// IsIgnoredDirectory reports whether a folder of a template, such as version
+// control metadata, is never rendered
+func IsIgnoredDirectory(name string) bool {
+	switch name {
+	case ".git", ".hg", "node_modules":
+		return true
+	}
+	return false
+}
+
+// FileError is an error raised while transforming a single file of a project
+// template
+type FileError struct {
+	FilePath string
+	Err      error
+}
+
+func (e FileError) Error() string {
+	return message(CodeRenderFailed, e.FilePath, e.Err)
+}
+
+// Code is the code of the cause of the failure, when it has one, so that a
+// file that takes too long to render is reported as such
+func (e FileError) Code() Code {
+	if code, ok := CodeOf(e.Err); ok {
+		return code
+	}
+	return CodeRenderFailed
+}
+
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// WriteError reports that a file of a project could not be written, such as
+// when the disk is full.  Written counts the files of the project that were
+// written before it.
+type WriteError struct {
+	Written int
+	Err     error
+}
+
+func (e WriteError) Error() string {
+	return e.Err.Error()
+}
+
+func (e WriteError) Unwrap() error {
+	return e.Err
+}
+
+// SkippedFile is a file of a project template that is not rendered
+type SkippedFile struct {
+	FilePath string
+	Reason   string
+}
+
+// maxListedSkippedFiles limits the number of skipped files listed in an error
+const maxListedSkippedFiles = 20
+
+// EmptyOutputError is returned when a project template renders no files
+type EmptyOutputError struct {
+	InputDir string
+	Skipped  []SkippedFile
+}
+
+func (e EmptyOutputError) Error() string {
+	var b strings.Builder
+	b.WriteString(message(CodeEmptyOutput, e.InputDir))
+	if len(e.Skipped) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("\nthe following files were skipped:")
+	for i, skipped := range e.Skipped {
+		if i == maxListedSkippedFiles {
+			fmt.Fprintf(&b, "\n\t... and %d more", len(e.Skipped)-maxListedSkippedFiles)
+			break
+		}
+		fmt.Fprintf(&b, "\n\t%s (%s)", skipped.FilePath, skipped.Reason)
+	}
+	return b.String()
+}
+
+func (e EmptyOutputError) Code() Code {
+	return CodeEmptyOutput
+}
+
 func ReadFile(path string) (string, error) {
 	buf, err := os.ReadFile(path)
 	if err != nil {
@@ -52,55 +143,234 @@ func ReadOverrides(overrideFile string) (map[string]string, error) {
 	return overrides, nil
 }
 
-func Apply(inputDir string, vars map[string]string, outputDir string) error {
+func Apply(inputDir string, vars map[string]string, outputDir string, settings Settings) error {
+	_, err := ApplyWithManifest(inputDir, vars, outputDir, settings, nil, DefaultRenderTimeout, false)
+	return err
+}
+
+// ApplyWithManifest renders the project template in the same way as Apply and
+// returns a Manifest of the written files.  Where a previous Manifest is
+// provided, files whose rendered content is unchanged since the previous
+// Manifest, and that are still in outputDir, are not written again.  Rendering
+// fails with a FileError naming the file should any one file take longer than
+// timeout to render; a timeout of zero or less disables the limit.  When
+// linkDuplicates is set, binary files with the same content and mode are
+// written once and hard linked, falling back to a copy where links are not
+// supported.
+func ApplyWithManifest(inputDir string, vars map[string]string, outputDir string, settings Settings, previous *Manifest, timeout time.Duration, linkDuplicates bool) (Manifest, error) {
+	manifest := Manifest{Files: map[string]string{}}
 	if vars == nil {
 		vars = map[string]string{}
 	}
-	files, err := findTransformableFiles(inputDir)
+	files, targets, err := selectFiles(inputDir, settings)
 	if err != nil {
-		return fmt.Errorf("failed to find files in input folder: %s %s", inputDir, err)
+		return manifest, err
 	}
+	if err := checkSpace(inputDir, outputDir, files, targets); err != nil {
+		return manifest, err
+	}
+	permissions, err := RenderPermissions(settings.Permissions, vars, settings.Engine)
+	if err != nil {
+		return manifest, err
+	}
+	helpers, err := readHelpers(inputDir, settings, vars)
+	if err != nil {
+		return manifest, err
+	}
+
+	// output paths of the binary files written so far, by checksum and mode
+	written := map[string]string{}
+	count := 0
+	for i, file := range files {
+		target := file
+		target.FilePath = targets[i]
+		rendered, err := target.ReplaceWithin(settings, vars, helpers, timeout)
+		if err != nil {
+			return manifest, FileError{FilePath: file.FilePath, Err: err}
+		}
+		if mode, ok := MatchPermission(permissions, rendered.FilePath); ok {
+			file.FileMode = mode
+			rendered.FileMode = mode
+		}
 
-	for _, file := range files {
-		err := file.Transform(inputDir, outputDir, vars)
+		sum, err := checksum(inputDir, file, rendered)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("failed to transform %s", file.FilePath))
+			return manifest, FileError{FilePath: file.FilePath, Err: err}
+		}
+		manifest.Files[filepath.ToSlash(rendered.FilePath)] = sum
+		if previous != nil && previous.Files[filepath.ToSlash(rendered.FilePath)] == sum {
+			if _, err := os.Stat(filepath.Join(outputDir, rendered.FilePath)); err == nil {
+				count++
+				continue
+			}
+		}
+
+		// text files are never linked as editing one would edit every copy
+		key := ""
+		if linkDuplicates && rendered.FileContent == "" && !isTextfile(filepath.Join(inputDir, file.FilePath)) {
+			key = fmt.Sprintf("%s %o", sum, rendered.FileMode)
+			if original, ok := written[key]; ok && linkFile(original, outputDir, rendered) == nil {
+				count++
+				continue
+			}
+		}
+		err = file.write(inputDir, outputDir, rendered)
+		if err != nil {
+			return manifest, WriteError{Written: count, Err: FileError{FilePath: file.FilePath, Err: err}}
+		}
+		count++
+		if key != "" {
+			written[key] = filepath.Join(outputDir, rendered.FilePath)
 		}
 	}
 
-	return err
+	return manifest, nil
+}
+
+// RenamedPaths renders the path of every file of the template in inputDir
+// without writing any file.  The rendered path of each file whose path
+// changes when rendered, such as {{.ProjectName}}/main.go or a file named with
+// the template suffix, is returned keyed by the path of the file in the
+// template.  Paths use forward slashes.
+func RenamedPaths(inputDir string, vars map[string]string, settings Settings) (map[string]string, error) {
+	renames := map[string]string{}
+	files, targets, err := selectFiles(inputDir, settings)
+	if err != nil {
+		return renames, err
+	}
+	helpers, err := readHelpers(inputDir, settings, vars)
+	if err != nil {
+		return renames, err
+	}
+	for i, file := range files {
+		path := SourceFile{FilePath: targets[i], FileMode: file.FileMode}
+		rendered, err := path.replaceWith(settings, vars, helpers)
+		if err != nil {
+			return renames, FileError{FilePath: file.FilePath, Err: err}
+		}
+		// a trimmed template suffix is a rename, but a source root is not
+		trimmed, _ := TrimTemplateSuffix(settings.TemplateSuffix, file.FilePath)
+		if rendered.FilePath != targets[i] || trimmed != file.FilePath {
+			renames[filepath.ToSlash(file.FilePath)] = filepath.ToSlash(rendered.FilePath)
+		}
+	}
+	return renames, nil
+}
+
+// Read the helper templates of the template in inputDir, which are parsed
+// once so that a helper that cannot be parsed is reported rather than each
+// file that would use it
+func readHelpers(inputDir string, settings Settings, vars map[string]string) (Helpers, error) {
+	if settings.Engine == PlaceholderEngine {
+		return nil, nil
+	}
+	helpers, err := ReadHelpers(inputDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(helpers) != 0 {
+		if _, err := newTemplate(vars, helpers, settings.Functions); err != nil {
+			return nil, err
+		}
+	}
+	return helpers, nil
 }
 
-func findTransformableFiles(dir string) ([]SourceFile, error) {
+// Select the files of the template in inputDir that are written to the
+// output folder, returning each file with its path in the output folder
+// before rendering
+func selectFiles(inputDir string, settings Settings) ([]SourceFile, []string, error) {
+	found, skipped, err := findTransformableFiles(inputDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find files in input folder: %s %s", inputDir, err)
+	}
+	if err := CheckEngine(settings.Engine); err != nil {
+		return nil, nil, err
+	}
+	roots, err := ParseRoots(settings.Roots)
+	if err != nil {
+		return nil, nil, err
+	}
+	exclusions := settings.Exclusions()
+	files := []SourceFile{}
+	targets := []string{}
+	for _, file := range found {
+		if isHelper(file.FilePath) {
+			skipped = append(skipped, SkippedFile{FilePath: file.FilePath, Reason: "helper template"})
+			continue
+		}
+		if IsExcluded(exclusions, roots, file.FilePath) {
+			skipped = append(skipped, SkippedFile{FilePath: file.FilePath, Reason: "excluded by the settings"})
+			continue
+		}
+		target, ok := MapRoot(roots, file.FilePath)
+		if !ok {
+			skipped = append(skipped, SkippedFile{FilePath: file.FilePath, Reason: "outside of the source roots"})
+			continue
+		}
+		target, rendered := TrimTemplateSuffix(settings.TemplateSuffix, target)
+		// raw files are moved into place in the same way as binary files
+		if !rendered || IsRaw(settings.Raw, file.FilePath) {
+			file.FileContent = ""
+		}
+		files = append(files, file)
+		targets = append(targets, target)
+	}
+	if len(files) == 0 {
+		return nil, nil, EmptyOutputError{InputDir: inputDir, Skipped: skipped}
+	}
+	return files, targets, nil
+}
+
+// Find the files to render in dir and the files that are skipped
+func findTransformableFiles(dir string) ([]SourceFile, []SkippedFile, error) {
 	files := []SourceFile{}
+	skipped := []SkippedFile{}
 	err := filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
-		if info.IsDir() && util.Contains(IgnoredDirectories, info.Name()) {
-			return filepath.SkipDir
+		if err != nil {
+			return err
+		}
+		relPath := strings.TrimPrefix(path, dir+"/")
+		// git submodules contain a .git file rather than a .git directory
+		if IsIgnoredDirectory(info.Name()) {
+			skipped = append(skipped, SkippedFile{FilePath: relPath, Reason: "ignored directory"})
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 
 		if !info.IsDir() {
 			// Ignore all prompts.toml files and any top-level README.md
 			rootReadme := filepath.Join(dir, "README")
-			if util.Contains(IgnoredNames, info.Name()) || strings.HasPrefix(path, rootReadme) {
+			if IsIgnoredName(info.Name()) {
+				skipped = append(skipped, SkippedFile{FilePath: relPath, Reason: "scafall configuration file"})
+				return nil
+			}
+			if strings.HasPrefix(path, rootReadme) {
+				skipped = append(skipped, SkippedFile{FilePath: relPath, Reason: "top-level README of the project template"})
 				return nil
 			}
 
-			relPath := strings.TrimPrefix(path, dir+"/")
+			fileInfo, err := info.Info()
+			if err != nil {
+				return err
+			}
+			fileMode := fileInfo.Mode().Perm()
 			if isTextfile(path) {
 				fileContent, err := ReadFile(path)
 				if err != nil {
 					return err
 				}
-				fileMode := info.Type().Perm()
 				files = append(files, SourceFile{FilePath: relPath, FileContent: fileContent, FileMode: fileMode})
 			} else {
-				files = append(files, SourceFile{FilePath: relPath, FileContent: ""})
+				files = append(files, SourceFile{FilePath: relPath, FileContent: "", FileMode: fileMode})
 			}
 		}
 		return nil
 	})
 
-	return files, err
+	return files, skipped, err
 }
 
 func isTextfile(path string) bool {
@@ -108,6 +378,7 @@ func isTextfile(path string) bool {
 	if err != nil {
 		return false
 	}
+	defer fd.Close()
 	mtype, err := mimetype.DetectReader(fd)
 	if err != nil {
 		return false
@@ -115,3 +386,16 @@ func isTextfile(path string) bool {
 
 	return strings.HasPrefix(mtype.String(), "text")
 }
+
+// Hard link the output of file to original, an already written file with the
+// same content
+func linkFile(original string, outputDir string, file SourceFile) error {
+	target := filepath.Join(outputDir, file.FilePath)
+	if err := os.MkdirAll(filepath.Dir(target), 0744); err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Link(original, target)
+}
@@ -1,10 +1,12 @@
 package internal_test
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/buildpacks/scafall/pkg/internal"
 
@@ -27,7 +29,7 @@ func testApply(t *testing.T, when spec.G, it spec.S) {
 			f.Close()
 			vars := map[string]string{"Foo": "Bar"}
 
-			err = internal.Apply(tmpDir, vars, outputDir)
+			err = internal.Apply(tmpDir, vars, outputDir, internal.Settings{})
 			h.AssertNil(t, err)
 
 			bar, err := os.Open(filepath.Join(outputDir, "/Bar/Bar/Bar.txt"))
@@ -40,6 +42,22 @@ func testApply(t *testing.T, when spec.G, it spec.S) {
 			h.AssertContains(t, c, "Bar")
 		})
 	})
+
+	when("Rendering the paths of a filesystem", func() {
+		it("lists the renamed paths without writing any file", func() {
+			tmpDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(tmpDir)
+			h.AssertNil(t, os.MkdirAll(filepath.Join(tmpDir, "{{.Foo}}"), 0766))
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "{{.Foo}}", "main.go"), []byte("{{.Foo}}"), 0600))
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module {{.Foo}}"), 0600))
+
+			renames, err := internal.RenamedPaths(tmpDir, map[string]string{"Foo": "Bar"}, internal.Settings{})
+			h.AssertNil(t, err)
+			h.AssertEq(t, renames, map[string]string{"{{.Foo}}/main.go": "Bar/main.go"})
+			_, err = os.Stat(filepath.Join(tmpDir, "{{.Foo}}", "main.go"))
+			h.AssertNil(t, err)
+		})
+	})
 }
 
 func testApplyNoArgument(t *testing.T, when spec.G, it spec.S) {
@@ -53,7 +71,7 @@ func testApplyNoArgument(t *testing.T, when spec.G, it spec.S) {
 			content := "{{ .Foo }}"
 			os.WriteFile(testFile, []byte(content), 0600)
 
-			err := internal.Apply(tmpDir, nil, outputDir)
+			err := internal.Apply(tmpDir, nil, outputDir, internal.Settings{})
 			h.AssertNil(t, err)
 
 			c, err := internal.ReadFile(filepath.Join(outputDir, "test.txt"))
@@ -76,7 +94,7 @@ func testApplyNoArgument(t *testing.T, when spec.G, it spec.S) {
 			f.Close()
 			vars := map[string]string{"Bar": "bar"}
 
-			err = internal.Apply(tmpDir, vars, outputDir)
+			err = internal.Apply(tmpDir, vars, outputDir, internal.Settings{})
 			h.AssertNil(t, err)
 
 			fooTxt := filepath.Join(outputDir, "/{{.Foo}}/{{.Foo}}/{{.Foo}}.txt")
@@ -91,3 +109,406 @@ func testApplyNoArgument(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 }
+
+func testApplyPermissions(t *testing.T, when spec.G, it spec.S) {
+	when("Applying with permission settings", func() {
+		var (
+			tmpDir    string
+			outputDir string
+		)
+
+		it.Before(func() {
+			tmpDir, _ = ioutil.TempDir("", "test")
+			outputDir, _ = ioutil.TempDir("", "test")
+			os.MkdirAll(filepath.Join(tmpDir, "scripts"), 0755)
+			os.WriteFile(filepath.Join(tmpDir, "scripts", "{{.Foo}}.sh"), []byte("echo {{.Foo}}"), 0644)
+			os.WriteFile(filepath.Join(tmpDir, "scripts", "keep.sh"), []byte("echo keep"), 0640)
+		})
+
+		it.After(func() {
+			os.RemoveAll(tmpDir)
+			os.RemoveAll(outputDir)
+		})
+
+		it("sets the mode of matching output files", func() {
+			settings := internal.Settings{Permissions: map[string]string{"scripts/{{.Foo}}.sh": "{{.Mode}}"}}
+			vars := map[string]string{"Foo": "bar", "Mode": "0755"}
+
+			err := internal.Apply(tmpDir, vars, outputDir, settings)
+			h.AssertNil(t, err)
+
+			fi, err := os.Stat(filepath.Join(outputDir, "scripts", "bar.sh"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, fi.Mode().Perm(), os.FileMode(0755))
+		})
+
+		it("keeps the mode of other files", func() {
+			settings := internal.Settings{Permissions: map[string]string{"scripts/{{.Foo}}.sh": "0755"}}
+
+			err := internal.Apply(tmpDir, map[string]string{"Foo": "bar"}, outputDir, settings)
+			h.AssertNil(t, err)
+
+			fi, err := os.Stat(filepath.Join(outputDir, "scripts", "keep.sh"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, fi.Mode().Perm(), os.FileMode(0640))
+		})
+
+		it("fails on a mode that is not octal", func() {
+			settings := internal.Settings{Permissions: map[string]string{"scripts/*.sh": "rwxr-xr-x"}}
+
+			err := internal.Apply(tmpDir, map[string]string{"Foo": "bar"}, outputDir, settings)
+			h.AssertNotNil(t, err)
+		})
+	})
+}
+
+func testApplyEmpty(t *testing.T, when spec.G, it spec.S) {
+	when("Applying a template that renders no files", func() {
+		it("fails and lists the skipped files", func() {
+			tmpDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(tmpDir)
+			outputDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(outputDir)
+			os.WriteFile(filepath.Join(tmpDir, internal.PromptFile), []byte{}, 0600)
+			os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# A template"), 0600)
+
+			err := internal.Apply(tmpDir, nil, outputDir, internal.Settings{})
+			h.AssertNotNil(t, err)
+
+			var emptyErr internal.EmptyOutputError
+			h.AssertTrue(t, errors.As(err, &emptyErr))
+			h.AssertEq(t, len(emptyErr.Skipped), 2)
+			h.AssertContains(t, err.Error(), "README.md")
+			h.AssertContains(t, err.Error(), internal.PromptFile)
+		})
+	})
+
+	when("Applying a template containing a git submodule", func() {
+		it("skips the .git file of the submodule", func() {
+			tmpDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(tmpDir)
+			outputDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(outputDir)
+			os.MkdirAll(filepath.Join(tmpDir, "shared"), 0755)
+			os.WriteFile(filepath.Join(tmpDir, "shared", ".git"), []byte("gitdir: ../.git/modules/shared"), 0600)
+			os.WriteFile(filepath.Join(tmpDir, "shared", "logo.txt"), []byte("logo"), 0600)
+
+			err := internal.Apply(tmpDir, nil, outputDir, internal.Settings{})
+			h.AssertNil(t, err)
+			_, err = os.Stat(filepath.Join(outputDir, "shared", "logo.txt"))
+			h.AssertNil(t, err)
+			_, err = os.Stat(filepath.Join(outputDir, "shared", ".git"))
+			h.AssertNotNil(t, err)
+		})
+	})
+}
+
+func testApplyChanged(t *testing.T, when spec.G, it spec.S) {
+	when("a previous manifest is provided", func() {
+		it("only writes files whose content changed", func() {
+			tmpDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(tmpDir)
+			outputDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(outputDir)
+			os.WriteFile(filepath.Join(tmpDir, "foo.txt"), []byte("{{.Foo}}"), 0600)
+			os.WriteFile(filepath.Join(tmpDir, "bar.txt"), []byte("{{.Bar}}"), 0600)
+
+			manifest, err := internal.ApplyWithManifest(tmpDir, map[string]string{"Foo": "foo", "Bar": "bar"}, outputDir, internal.Settings{}, nil, internal.DefaultRenderTimeout, false)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(manifest.Files), 2)
+
+			past := time.Now().Add(-time.Hour).Truncate(time.Second)
+			os.Chtimes(filepath.Join(outputDir, "foo.txt"), past, past)
+			os.Chtimes(filepath.Join(outputDir, "bar.txt"), past, past)
+
+			changed, err := internal.ApplyWithManifest(tmpDir, map[string]string{"Foo": "foo", "Bar": "quack"}, outputDir, internal.Settings{}, &manifest, internal.DefaultRenderTimeout, false)
+			h.AssertNil(t, err)
+			h.AssertEq(t, changed.Files["foo.txt"], manifest.Files["foo.txt"])
+			h.AssertNotEq(t, changed.Files["bar.txt"], manifest.Files["bar.txt"])
+
+			info, err := os.Stat(filepath.Join(outputDir, "foo.txt"))
+			h.AssertNil(t, err)
+			h.AssertTrue(t, info.ModTime().Equal(past))
+			content, err := internal.ReadFile(filepath.Join(outputDir, "bar.txt"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, content, "quack")
+		})
+	})
+}
+
+func testApplyHardLinks(t *testing.T, when spec.G, it spec.S) {
+	when("hard links are requested", func() {
+		it("links identical binary files but not text files", func() {
+			tmpDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(tmpDir)
+			outputDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(outputDir)
+			binary := []byte{0x89, 'P', 'N', 'G', 0x00, 0x01, 0x02, 0xff}
+			os.MkdirAll(filepath.Join(tmpDir, "icons"), 0755)
+			os.WriteFile(filepath.Join(tmpDir, "logo.png"), binary, 0644)
+			os.WriteFile(filepath.Join(tmpDir, "icons", "logo.png"), binary, 0644)
+			os.WriteFile(filepath.Join(tmpDir, "foo.txt"), []byte("foo"), 0644)
+			os.WriteFile(filepath.Join(tmpDir, "bar.txt"), []byte("foo"), 0644)
+
+			_, err := internal.ApplyWithManifest(tmpDir, map[string]string{}, outputDir, internal.Settings{}, nil, internal.DefaultRenderTimeout, true)
+			h.AssertNil(t, err)
+
+			first, err := os.Stat(filepath.Join(outputDir, "logo.png"))
+			h.AssertNil(t, err)
+			second, err := os.Stat(filepath.Join(outputDir, "icons", "logo.png"))
+			h.AssertNil(t, err)
+			h.AssertTrue(t, os.SameFile(first, second))
+
+			foo, err := os.Stat(filepath.Join(outputDir, "foo.txt"))
+			h.AssertNil(t, err)
+			bar, err := os.Stat(filepath.Join(outputDir, "bar.txt"))
+			h.AssertNil(t, err)
+			h.AssertTrue(t, !os.SameFile(foo, bar))
+		})
+	})
+}
+
+func testApplyRoots(t *testing.T, when spec.G, it spec.S) {
+	when("source roots are declared", func() {
+		it("maps each root to its output folder and skips other files", func() {
+			tmpDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(tmpDir)
+			outputDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(outputDir)
+			os.MkdirAll(filepath.Join(tmpDir, "app"), 0755)
+			os.MkdirAll(filepath.Join(tmpDir, "ci", "github", "workflows"), 0755)
+			os.MkdirAll(filepath.Join(tmpDir, ".github", "workflows"), 0755)
+			os.WriteFile(filepath.Join(tmpDir, "app", "main.go"), []byte("package {{.Foo}}"), 0600)
+			os.WriteFile(filepath.Join(tmpDir, "ci", "github", "workflows", "build.yml"), []byte("name: build"), 0600)
+			os.WriteFile(filepath.Join(tmpDir, ".github", "workflows", "test.yml"), []byte("name: test"), 0600)
+
+			settings := internal.Settings{Roots: map[string]string{"app/": "./", "ci/github/": ".github/"}}
+			err := internal.Apply(tmpDir, map[string]string{"Foo": "main"}, outputDir, settings)
+			h.AssertNil(t, err)
+
+			content, err := internal.ReadFile(filepath.Join(outputDir, "main.go"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, content, "package main")
+			_, err = os.Stat(filepath.Join(outputDir, ".github", "workflows", "build.yml"))
+			h.AssertNil(t, err)
+			_, err = os.Stat(filepath.Join(outputDir, ".github", "workflows", "test.yml"))
+			h.AssertNotNil(t, err)
+		})
+
+		it("refuses roots outside of the output folder", func() {
+			_, err := internal.ParseRoots(map[string]string{"app": "../app"})
+			h.AssertNotNil(t, err)
+		})
+	})
+}
+
+func testApplyExclusions(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir    string
+		outputDir string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = ioutil.TempDir("", "test")
+		outputDir, _ = ioutil.TempDir("", "test")
+		for _, folder := range []string{".github", "tests", "docs"} {
+			os.MkdirAll(filepath.Join(tmpDir, folder), 0755)
+			os.WriteFile(filepath.Join(tmpDir, folder, "file.txt"), []byte(folder), 0600)
+		}
+		os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0600)
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+		os.RemoveAll(outputDir)
+	})
+
+	exists := func(folder string) bool {
+		_, err := os.Stat(filepath.Join(outputDir, folder, "file.txt"))
+		return err == nil
+	}
+
+	when("no exclusions are configured", func() {
+		it("skips the template's own CI and tests", func() {
+			err := internal.Apply(tmpDir, nil, outputDir, internal.Settings{})
+			h.AssertNil(t, err)
+			h.AssertEq(t, exists(".github"), false)
+			h.AssertEq(t, exists("tests"), false)
+			h.AssertEq(t, exists("docs"), true)
+		})
+	})
+
+	when("exclusions are configured", func() {
+		it("includes and excludes the configured paths", func() {
+			settings := internal.Settings{Include: []string{"tests/"}, Exclude: []string{"docs"}}
+			err := internal.Apply(tmpDir, nil, outputDir, settings)
+			h.AssertNil(t, err)
+			h.AssertEq(t, exists(".github"), false)
+			h.AssertEq(t, exists("tests"), true)
+			h.AssertEq(t, exists("docs"), false)
+		})
+	})
+
+	when("an excluded folder is mapped by a root", func() {
+		it("renders the mapped folder", func() {
+			settings := internal.Settings{Roots: map[string]string{".": ".", ".github": ".github"}}
+			err := internal.Apply(tmpDir, nil, outputDir, settings)
+			h.AssertNil(t, err)
+			h.AssertEq(t, exists(".github"), true)
+			h.AssertEq(t, exists("tests"), false)
+		})
+	})
+}
+
+func testApplyRaw(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir    string
+		outputDir string
+	)
+	vars := map[string]string{"Foo": "Bar"}
+
+	it.Before(func() {
+		tmpDir, _ = ioutil.TempDir("", "test")
+		outputDir, _ = ioutil.TempDir("", "test")
+		os.MkdirAll(filepath.Join(tmpDir, "assets", "js"), 0755)
+		os.WriteFile(filepath.Join(tmpDir, "assets", "js", "app.js"), []byte("const f = () => {{.Foo}}"), 0600)
+		os.MkdirAll(filepath.Join(tmpDir, "icons"), 0755)
+		os.WriteFile(filepath.Join(tmpDir, "icons", "logo.png.tpl"), []byte("{{ broken"), 0600)
+		os.WriteFile(filepath.Join(tmpDir, "{{.Foo}}.txt"), []byte("{{.Foo}}"), 0600)
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+		os.RemoveAll(outputDir)
+	})
+
+	read := func(file string) string {
+		content, err := internal.ReadFile(filepath.Join(outputDir, filepath.FromSlash(file)))
+		h.AssertNil(t, err)
+		return content
+	}
+
+	when("files are raw", func() {
+		it("copies them as they are", func() {
+			settings := internal.Settings{Raw: []string{"assets/**", "*.png.tpl", "{{.Foo}}.txt"}}
+			err := internal.Apply(tmpDir, vars, outputDir, settings)
+			h.AssertNil(t, err)
+			h.AssertEq(t, read("assets/js/app.js"), "const f = () => {{.Foo}}")
+			h.AssertEq(t, read("icons/logo.png.tpl"), "{{ broken")
+			h.AssertEq(t, read("Bar.txt"), "{{.Foo}}")
+		})
+	})
+
+	when("patterns are matched", func() {
+		it("matches names in any folder and paths with any number of folders", func() {
+			h.AssertTrue(t, internal.IsRaw([]string{"*.png.tpl"}, "icons/logo.png.tpl"))
+			h.AssertTrue(t, internal.IsRaw([]string{"assets/**"}, "assets/js/app.js"))
+			h.AssertTrue(t, internal.IsRaw([]string{"assets/**/*.js"}, "assets/js/vendor/app.js"))
+			h.AssertTrue(t, internal.IsRaw([]string{"assets"}, "assets/js/app.js"))
+			h.AssertEq(t, internal.IsRaw([]string{"assets/**"}, "src/assets.go"), false)
+			h.AssertEq(t, internal.IsRaw([]string{"assets/*.js"}, "assets/js/app.js"), false)
+		})
+	})
+}
+
+func testApplyTemplateSuffix(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir    string
+		outputDir string
+	)
+	vars := map[string]string{"Foo": "Bar"}
+
+	it.Before(func() {
+		tmpDir, _ = ioutil.TempDir("", "test")
+		outputDir, _ = ioutil.TempDir("", "test")
+		os.MkdirAll(filepath.Join(tmpDir, "config"), 0755)
+		os.WriteFile(filepath.Join(tmpDir, "config", "app.yaml.tmpl"), []byte("name: {{.Foo}}"), 0600)
+		os.WriteFile(filepath.Join(tmpDir, "deploy.sh"), []byte("echo ${{.Foo}}"), 0600)
+		os.WriteFile(filepath.Join(tmpDir, "{{.Foo}}.txt"), []byte("{{.Foo}}"), 0600)
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+		os.RemoveAll(outputDir)
+	})
+
+	read := func(file string) string {
+		content, err := internal.ReadFile(filepath.Join(outputDir, filepath.FromSlash(file)))
+		h.AssertNil(t, err)
+		return content
+	}
+
+	when("a template suffix is set", func() {
+		it("renders only the files with the suffix and writes them without it", func() {
+			settings := internal.Settings{TemplateSuffix: ".tmpl"}
+			err := internal.Apply(tmpDir, vars, outputDir, settings)
+			h.AssertNil(t, err)
+			h.AssertEq(t, read("config/app.yaml"), "name: Bar")
+			_, err = os.Stat(filepath.Join(outputDir, "config", "app.yaml.tmpl"))
+			h.AssertTrue(t, os.IsNotExist(err))
+			h.AssertEq(t, read("deploy.sh"), "echo ${{.Foo}}")
+			h.AssertEq(t, read("Bar.txt"), "{{.Foo}}")
+		})
+
+		it("reports the renamed files", func() {
+			settings := internal.Settings{TemplateSuffix: ".tmpl"}
+			renames, err := internal.RenamedPaths(tmpDir, vars, settings)
+			h.AssertNil(t, err)
+			h.AssertEq(t, renames["config/app.yaml.tmpl"], "config/app.yaml")
+		})
+	})
+
+	when("suffixes are trimmed", func() {
+		it("only renders files named with more than the suffix", func() {
+			target, rendered := internal.TrimTemplateSuffix(".tmpl", "config/app.yaml.tmpl")
+			h.AssertEq(t, target, "config/app.yaml")
+			h.AssertTrue(t, rendered)
+			_, rendered = internal.TrimTemplateSuffix(".tmpl", "config/.tmpl")
+			h.AssertEq(t, rendered, false)
+			_, rendered = internal.TrimTemplateSuffix(".tmpl", "deploy.sh")
+			h.AssertEq(t, rendered, false)
+			target, rendered = internal.TrimTemplateSuffix("", "deploy.sh")
+			h.AssertEq(t, target, "deploy.sh")
+			h.AssertTrue(t, rendered)
+		})
+	})
+}
+
+func testApplyTimeout(t *testing.T, when spec.G, it spec.S) {
+	when("a file takes longer than the timeout to render", func() {
+		it("reports the file that exceeded the timeout", func() {
+			tmpDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(tmpDir)
+			outputDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(outputDir)
+			// ten million iterations take far longer than the timeout, but
+			// the abandoned render still finishes within a second
+			os.WriteFile(filepath.Join(tmpDir, "slow.txt"), []byte("{{range until 10000}}{{range until 1000}}{{end}}{{end}}"), 0600)
+
+			_, err := internal.ApplyWithManifest(tmpDir, map[string]string{}, outputDir, internal.Settings{}, nil, 10*time.Millisecond, false)
+			h.AssertNotNil(t, err)
+			var fileErr internal.FileError
+			h.AssertTrue(t, errors.As(err, &fileErr))
+			h.AssertEq(t, fileErr.FilePath, "slow.txt")
+			var timeoutErr internal.RenderTimeoutError
+			h.AssertTrue(t, errors.As(err, &timeoutErr))
+			h.AssertEq(t, timeoutErr.Timeout, 10*time.Millisecond)
+		})
+	})
+
+	when("the timeout is zero", func() {
+		it("does not limit rendering", func() {
+			tmpDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(tmpDir)
+			outputDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(outputDir)
+			os.WriteFile(filepath.Join(tmpDir, "foo.txt"), []byte("{{.Foo}}"), 0600)
+
+			_, err := internal.ApplyWithManifest(tmpDir, map[string]string{"Foo": "foo"}, outputDir, internal.Settings{}, nil, 0, false)
+			h.AssertNil(t, err)
+			content, err := os.ReadFile(filepath.Join(outputDir, "foo.txt"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(content), "foo")
+		})
+	})
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// Create the client used for every HTTP request made while fetching a
+// template.  Unless a proxy is provided, the proxy is read from the
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.  Certificates
+// in the CA bundle are trusted in addition to the system certificates.
+func newHTTPClient(opts FetchOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.Proxy != "" {
+		proxyURL, err := neturl.Parse(opts.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %s: %s", opts.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if opts.CABundle != "" {
+		bundle, err := os.ReadFile(opts.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("%s does not contain any PEM encoded certificates", opts.CABundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// gitTransport guards the HTTP transports that go-git shares between every
+// clone.  Concurrent clones with the same proxy and CA bundle share the
+// installed transports, a clone with other settings waits until they finish.
+// The transports installed before the first clone are put back once the last
+// clone finishes, so that other users of go-git in the same program are only
+// affected while a template is being fetched.
+type gitTransport struct {
+	mu       sync.Mutex
+	idle     *sync.Cond
+	key      string
+	users    int
+	previous map[string]transport.Transport
+}
+
+var sharedGitTransport = newGitTransport()
+
+func newGitTransport() *gitTransport {
+	t := &gitTransport{}
+	t.idle = sync.NewCond(&t.mu)
+	return t
+}
+
+// Install the HTTP transports for opts, returning a function that must be
+// called once the clone is finished
+func acquireGitTransport(opts FetchOptions) (func(), error) {
+	return sharedGitTransport.acquire(opts)
+}
+
+func (t *gitTransport) acquire(opts FetchOptions) (func(), error) {
+	key := opts.Proxy + "\x00" + opts.CABundle
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for t.users != 0 && t.key != key {
+		t.idle.Wait()
+	}
+	if t.users == 0 {
+		previous, err := installGitTransport(opts)
+		if err != nil {
+			return nil, err
+		}
+		t.key = key
+		t.previous = previous
+	}
+	t.users++
+	return t.release, nil
+}
+
+func (t *gitTransport) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.users--
+	if t.users == 0 {
+		for scheme, previous := range t.previous {
+			client.InstallProtocol(scheme, previous)
+		}
+		t.previous = nil
+		t.idle.Broadcast()
+	}
+}
+
+// go-git chooses a transport by url scheme, so the HTTP transports are
+// replaced by ones that use the proxy and CA bundle of opts, returning the
+// transports they replaced.  The transports of go-git already read the proxy
+// from the environment, so they are left alone when opts sets neither.
+func installGitTransport(opts FetchOptions) (map[string]transport.Transport, error) {
+	if opts.Proxy == "" && opts.CABundle == "" {
+		return nil, nil
+	}
+	httpClient, err := newHTTPClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	gitClient := githttp.NewClient(httpClient)
+	previous := map[string]transport.Transport{}
+	for _, scheme := range []string{"http", "https"} {
+		previous[scheme] = client.Protocols[scheme]
+		client.InstallProtocol(scheme, gitClient)
+	}
+	return previous, nil
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// TreeEntry describes a file or folder of a rendered project
+type TreeEntry struct {
+	// Path is the slash separated path of the entry within the project
+	Path string
+	// Size is the number of bytes of a file, folders have no size
+	Size int64
+	Mode os.FileMode
+}
+
+// IsDir reports whether the entry is a folder
+func (e TreeEntry) IsDir() bool {
+	return e.Mode.IsDir()
+}
+
+// ReadTree lists every file and folder in dir, each folder is followed by
+// its contents in the order of their names
+func ReadTree(dir string) ([]TreeEntry, error) {
+	entries := []TreeEntry{}
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if file == dir {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		treeEntry := TreeEntry{Path: filepath.ToSlash(relPath), Mode: info.Mode()}
+		if !info.IsDir() {
+			treeEntry.Size = info.Size()
+		}
+		entries = append(entries, treeEntry)
+		return nil
+	})
+	return entries, err
+}
+
+// WriteTree draws entries, as listed by ReadTree, below root in the manner
+// of the tree command, showing the mode of every entry and the size of every
+// file
+func WriteTree(w io.Writer, root string, entries []TreeEntry) error {
+	if _, err := fmt.Fprintf(w, "%s/\n", strings.TrimSuffix(filepath.ToSlash(root), "/")); err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		depth := strings.Count(entry.Path, "/")
+		prefix := ""
+		for level := 0; level < depth; level++ {
+			if hasLaterSibling(entries[i+1:], ancestor(entry.Path, level+1)) {
+				prefix += "│   "
+			} else {
+				prefix += "    "
+			}
+		}
+		branch := "├── "
+		if !hasLaterSibling(entries[i+1:], entry.Path) {
+			branch = "└── "
+		}
+		name := path.Base(entry.Path)
+		details := fmt.Sprintf("%s  %s", entry.Mode, formatSize(entry.Size))
+		if entry.IsDir() {
+			name += "/"
+			details = entry.Mode.String()
+		}
+		if _, err := fmt.Fprintf(w, "%s%s%s  %s\n", prefix, branch, name, details); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The ancestor of a slash separated path with depth parts, such as a for
+// a/b/c and a depth of 1
+func ancestor(p string, depth int) string {
+	parts := strings.Split(p, "/")
+	return strings.Join(parts[:depth], "/")
+}
+
+// Whether a later entry is in the same folder as p, the entries of other
+// folders at the same depth end the search
+func hasLaterSibling(later []TreeEntry, p string) bool {
+	parent := path.Dir(p)
+	for _, entry := range later {
+		if path.Dir(entry.Path) == parent {
+			return true
+		}
+		if parent != "." && !strings.HasPrefix(entry.Path, parent+"/") {
+			return false
+		}
+	}
+	return false
+}
+
+// Format a number of bytes in the largest unit in which it is at least one
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size) / unit
+	for _, suffix := range []string{"KiB", "MiB", "GiB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TiB", value)
+}
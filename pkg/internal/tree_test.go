@@ -0,0 +1,62 @@
+package internal_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testTree(t *testing.T, when spec.G, it spec.S) {
+	var tmpDir string
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+		h.AssertNil(t, os.MkdirAll(filepath.Join(tmpDir, "cmd", "app"), 0755))
+		h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "cmd", "app", "main.go"), []byte("package main\n"), 0644))
+		h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), make([]byte, 2048), 0644))
+		h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "run.sh"), []byte("#!/bin/sh\n"), 0755))
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a project is listed", func() {
+		it("lists each folder before its contents", func() {
+			entries, err := internal.ReadTree(tmpDir)
+			h.AssertNil(t, err)
+			paths := []string{}
+			for _, entry := range entries {
+				paths = append(paths, entry.Path)
+			}
+			h.AssertEq(t, paths, []string{"cmd", "cmd/app", "cmd/app/main.go", "go.mod", "run.sh"})
+			h.AssertTrue(t, entries[0].IsDir())
+			h.AssertEq(t, entries[3].Size, int64(2048))
+		})
+	})
+
+	when("a project is drawn", func() {
+		it("shows the modes of entries and the sizes of files", func() {
+			entries, err := internal.ReadTree(tmpDir)
+			h.AssertNil(t, err)
+			var out bytes.Buffer
+			h.AssertNil(t, internal.WriteTree(&out, "my-service", entries))
+			h.AssertEq(t, out.String(), strings.Join([]string{
+				"my-service/",
+				"├── cmd/  drwxr-xr-x",
+				"│   └── app/  drwxr-xr-x",
+				"│       └── main.go  -rw-r--r--  13 B",
+				"├── go.mod  -rw-r--r--  2.0 KiB",
+				"└── run.sh  -rwxr-xr-x  10 B",
+				"",
+			}, "\n"))
+		})
+	})
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// Validator checks an answer against rules that cannot be written as a
+// pattern, such as the rules for a buildpack ID, and describes why an invalid
+// answer is rejected
+type Validator func(value string) error
+
+// Validators are keyed by the name of the prompt whose answers they check
+type Validators map[string]Validator
+
+// Check value, the normalized answer to the prompt name
+func (v Validators) Check(name string, value string) error {
+	validator, ok := v[name]
+	if !ok || validator == nil {
+		return nil
+	}
+	return validator(value)
+}
+
+// Add the validator of prompt, if it has one, to question so that the
+// end-user is asked again when an answer is rejected
+func (v Validators) addTo(question *survey.Question, prompt Prompt) {
+	if _, ok := v[prompt.Name]; !ok {
+		return
+	}
+	locale := CurrentLocale()
+	validate := func(ans interface{}) error {
+		typed := answerValue(prompt, ans)
+		value, err := Normalize(prompt, typed, TypedLocale(prompt, typed, locale))
+		if err != nil {
+			return err
+		}
+		return v.Check(prompt.Name, value)
+	}
+	if question.Validate != nil {
+		validate = survey.ComposeValidators(question.Validate, validate)
+	}
+	question.Validate = validate
+}
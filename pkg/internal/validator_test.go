@@ -0,0 +1,98 @@
+package internal_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+var buildpackID = regexp.MustCompile(`^[a-z0-9\-.]+/[a-z0-9\-.]+$`)
+
+// A validator for buildpack IDs, which cannot be written as a single pattern
+// because IDs using the reserved config and app namespaces are rejected
+func validateBuildpackID(value string) error {
+	if !buildpackID.MatchString(value) {
+		return fmt.Errorf("%s is not a buildpack ID of the form namespace/name", value)
+	}
+	if namespace := strings.Split(value, "/")[0]; namespace == "config" || namespace == "app" {
+		return fmt.Errorf("the %s namespace is reserved", namespace)
+	}
+	return nil
+}
+
+func testValidators(t *testing.T, when spec.G, it spec.S) {
+	promptFile := `[[prompt]]
+name = "BuildpackID"
+prompt = "Buildpack ID"
+default = "example/buildpack"
+`
+	var (
+		template   internal.Template
+		validators internal.Validators
+	)
+
+	it.Before(func() {
+		var err error
+		template, err = internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), nil, nil)
+		h.AssertNil(t, err)
+		validators = internal.Validators{"BuildpackID": validateBuildpackID}
+	})
+
+	when("a default is valid", func() {
+		it("is used", func() {
+			values, err := template.Validate(validators).Defaults()
+			h.AssertNil(t, err)
+			h.AssertEq(t, values["BuildpackID"], "example/buildpack")
+		})
+	})
+
+	when("an argument is rejected by a validator", func() {
+		it("reports the reason", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), map[string]string{"BuildpackID": "app/node"}, nil)
+			h.AssertNil(t, err)
+			_, err = template.Validate(validators).Defaults()
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "invalid value for BuildpackID: the app namespace is reserved")
+		})
+	})
+
+	when("an answer is rejected by a validator", func() {
+		it("is listed with the other invalid answers", func() {
+			_, err := template.Validate(validators).Answer(map[string]interface{}{"BuildpackID": "Node"})
+			var answerErr internal.AnswerError
+			h.AssertTrue(t, errors.As(err, &answerErr))
+			h.AssertEq(t, answerErr.Problems, []string{"BuildpackID: Node is not a buildpack ID of the form namespace/name"})
+		})
+	})
+
+	when("the end-user gives an answer rejected by a validator", func() {
+		it("asks again", func() {
+			test := func(stdio terminal.Stdio) (map[string]string, error) {
+				return template.Validate(validators).Ask(survey.WithStdio(stdio.In, stdio.Out, stdio.Err))
+			}
+			RunTest(t, func(c expectConsole) {
+				c.ExpectString("Buildpack ID")
+				c.SendLine("config/env")
+				c.ExpectString("the config namespace is reserved")
+				c.SendLine("acme/env")
+				c.ExpectEOF()
+			}, test, map[string]string{"BuildpackID": "acme/env"})
+		})
+	})
+
+	when("a prompt has no validator", func() {
+		it("accepts any answer allowed by the prompt", func() {
+			h.AssertNil(t, validators.Check("Other", "anything"))
+		})
+	})
+}
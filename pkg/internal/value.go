@@ -0,0 +1,287 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	StringType string = "string"
+	NumberType string = "number"
+	DateType   string = "date"
+	// TextType is a string that may span several lines
+	TextType string = "text"
+	// ListType is a list of strings, written one item per line
+	ListType string = "list"
+	// PathType is the path of a file or directory, which may be required to
+	// exist or not to exist
+	PathType string = "path"
+
+	// CanonicalDateLayout is the form in which all dates are made available to templates
+	CanonicalDateLayout string = "2006-01-02"
+
+	// ListsVariable names the list variables, which are given to templates as
+	// a slice of their items
+	ListsVariable string = ReservedPrefix + "Lists"
+)
+
+var PromptTypes = []string{StringType, NumberType, DateType, TextType, ListType, PathType}
+
+// Locale describes how numbers and dates are written by the end-user
+type Locale struct {
+	Decimal     string
+	Group       string
+	DateLayouts []string
+}
+
+var (
+	defaultLocale = Locale{Decimal: ".", Group: ","}
+	usLocale      = Locale{Decimal: ".", Group: ",", DateLayouts: []string{"01/02/2006", "1/2/2006"}}
+	ukLocale      = Locale{Decimal: ".", Group: ",", DateLayouts: []string{"02/01/2006", "2/1/2006"}}
+
+	// locales are keyed by language, or by language and territory where the
+	// territory differs from the language default
+	locales = map[string]Locale{
+		"en":    usLocale,
+		"en_AU": ukLocale,
+		"en_GB": ukLocale,
+		"en_IE": ukLocale,
+		"en_IN": ukLocale,
+		"en_NZ": ukLocale,
+		"de":    {Decimal: ",", Group: ".", DateLayouts: []string{"02.01.2006", "2.1.2006"}},
+		"de_CH": {Decimal: ".", Group: "'", DateLayouts: []string{"02.01.2006", "2.1.2006"}},
+		"es":    {Decimal: ",", Group: ".", DateLayouts: []string{"02/01/2006", "2/1/2006"}},
+		"fr":    {Decimal: ",", Group: " ", DateLayouts: []string{"02/01/2006", "2/1/2006"}},
+		"it":    {Decimal: ",", Group: ".", DateLayouts: []string{"02/01/2006", "2/1/2006"}},
+		"ja":    {Decimal: ".", Group: ",", DateLayouts: []string{"2006/01/02", "2006/1/2"}},
+		"nl":    {Decimal: ",", Group: ".", DateLayouts: []string{"02-01-2006", "2-1-2006"}},
+		"pl":    {Decimal: ",", Group: " ", DateLayouts: []string{"02.01.2006", "2.1.2006"}},
+		"pt":    {Decimal: ",", Group: ".", DateLayouts: []string{"02/01/2006", "2/1/2006"}},
+		"ru":    {Decimal: ",", Group: " ", DateLayouts: []string{"02.01.2006", "2.1.2006"}},
+		"zh":    {Decimal: ".", Group: ",", DateLayouts: []string{"2006/01/02", "2006/1/2"}},
+	}
+)
+
+// LookupLocale finds the Locale for a POSIX locale name such as de_DE.UTF-8
+func LookupLocale(name string) Locale {
+	name = strings.SplitN(name, ".", 2)[0]
+	name = strings.SplitN(name, "@", 2)[0]
+	if l, ok := locales[name]; ok {
+		return l
+	}
+	language := strings.SplitN(name, "_", 2)[0]
+	if l, ok := locales[language]; ok {
+		return l
+	}
+	return defaultLocale
+}
+
+// CurrentLocale finds the Locale of the end-user from the environment
+func CurrentLocale() Locale {
+	for _, env := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if name := os.Getenv(env); name != "" {
+			return LookupLocale(name)
+		}
+	}
+	return defaultLocale
+}
+
+// TypedLocale is the locale in which value, an answer typed by the end-user
+// to prompt, is written.  The default and choices of a prompt are written by
+// the author of the template in canonical form, so that a template renders
+// the same in every locale, and are parsed in canonical form when accepted.
+func TypedLocale(prompt Prompt, value string, locale Locale) Locale {
+	if value == prompt.Default {
+		return defaultLocale
+	}
+	for _, choice := range prompt.Choices {
+		if value == choice {
+			return defaultLocale
+		}
+	}
+	return locale
+}
+
+// Normalize parses a value provided for a typed prompt and returns the value
+// in canonical form.  Numbers are written without grouping and with a "."
+// decimal separator, dates are written as YYYY-MM-DD, lists are written
+// one item per line and paths beginning with ~ are written from the home
+// directory.
+func Normalize(prompt Prompt, value string, locale Locale) (string, error) {
+	switch prompt.Type {
+	case NumberType:
+		return normalizeNumber(value, locale)
+	case DateType:
+		return normalizeDate(value, prompt.Format, locale)
+	case ListType:
+		return strings.Join(SplitList(value), "\n"), nil
+	case PathType:
+		return normalizePath(value), nil
+	}
+	return value, nil
+}
+
+// SplitList splits the value of a list prompt into its items.  Items are
+// written one per line or, on a single line such as an argument, separated
+// by commas.  Space around each item and empty items are removed.
+func SplitList(value string) []string {
+	separator := "\n"
+	if !strings.Contains(value, separator) {
+		separator = ","
+	}
+	items := []string{}
+	for _, item := range strings.Split(value, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func normalizeNumber(value string, locale Locale) (string, error) {
+	number := strings.TrimSpace(value)
+	if number == "" {
+		return "", nil
+	}
+
+	integer, fraction := number, ""
+	if i := strings.LastIndex(number, locale.Decimal); i >= 0 {
+		integer, fraction = number[:i], number[i+len(locale.Decimal):]
+	}
+
+	groups := splitGroups(integer, locale.Group)
+	for i, group := range groups {
+		if i > 0 && len(group) != 3 {
+			return "", fmt.Errorf("%s is not a number", value)
+		}
+	}
+	canonical := strings.Join(groups, "")
+	if fraction != "" {
+		canonical = canonical + "." + fraction
+	}
+
+	f, err := strconv.ParseFloat(canonical, 64)
+	if err != nil {
+		return "", fmt.Errorf("%s is not a number", value)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
+
+// Split the integer part of a number at group separators.  Where the group
+// separator is a space then any kind of space is accepted.
+func splitGroups(integer string, group string) []string {
+	if group == " " {
+		return strings.FieldsFunc(integer, func(r rune) bool {
+			return r == ' ' || r == '\u00a0' || r == '\u202f'
+		})
+	}
+	return strings.Split(integer, group)
+}
+
+func normalizeDate(value string, format string, locale Locale) (string, error) {
+	date := strings.TrimSpace(value)
+	if date == "" {
+		return "", nil
+	}
+
+	layouts := append([]string{CanonicalDateLayout}, locale.DateLayouts...)
+	if format != "" {
+		layouts = []string{format}
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.Format(CanonicalDateLayout), nil
+		}
+	}
+	return "", fmt.Errorf("%s is not a date; expected a date such as %s", value, time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC).Format(layouts[len(layouts)-1]))
+}
+
+// CheckPattern reports a value that does not match the pattern of a prompt in
+// full, each item of a list must match.  Empty values are left to the
+// required check.
+func CheckPattern(prompt Prompt, value string) error {
+	if prompt.Pattern == "" || value == "" {
+		return nil
+	}
+	pattern, err := compilePattern(prompt.Pattern)
+	if err != nil {
+		return fmt.Errorf("pattern %s of %s is not a regular expression: %s", prompt.Pattern, prompt.Name, err)
+	}
+	items := []string{value}
+	if prompt.Type == ListType {
+		items = SplitList(value)
+	}
+	for _, item := range items {
+		if pattern.MatchString(item) {
+			continue
+		}
+		if prompt.PatternMessage != "" {
+			return errors.New(prompt.PatternMessage)
+		}
+		return prompt.invalid(fmt.Errorf("%s does not match the pattern %s", item, prompt.Pattern))
+	}
+	return nil
+}
+
+// CheckLength reports a value that is shorter than the minimum length, or
+// longer than the maximum length, of a prompt.  Lengths are counted in
+// characters and each item of a list is checked.  An empty value is too short
+// for a prompt with a minimum length.
+func CheckLength(prompt Prompt, value string) error {
+	if prompt.MinLength == 0 && prompt.MaxLength == 0 {
+		return nil
+	}
+	items := []string{value}
+	if prompt.Type == ListType {
+		items = SplitList(value)
+	}
+	for _, item := range items {
+		length := utf8.RuneCountInString(item)
+		if length < prompt.MinLength {
+			return fmt.Errorf("%q is shorter than %d characters", item, prompt.MinLength)
+		}
+		if prompt.MaxLength != 0 && length > prompt.MaxLength {
+			return fmt.Errorf("%q is longer than %d characters", item, prompt.MaxLength)
+		}
+	}
+	return nil
+}
+
+// Normalize value and check it against the pattern, length and path
+// existence of the prompt
+func (p Prompt) check(value string, locale Locale) (string, error) {
+	normalized, err := Normalize(p, value, locale)
+	if err != nil {
+		return "", p.invalid(err)
+	}
+	if err := CheckPattern(p, normalized); err != nil {
+		return "", err
+	}
+	if err := CheckLength(p, normalized); err != nil {
+		return "", p.invalid(err)
+	}
+	if err := CheckPath(p, normalized); err != nil {
+		return "", p.invalid(err)
+	}
+	return normalized, nil
+}
+
+// The reason that an answer is invalid, the error message of the prompt is
+// reported in place of err when there is one
+func (p Prompt) invalid(err error) error {
+	if err == nil || p.ErrorMessage == "" {
+		return err
+	}
+	return errors.New(p.ErrorMessage)
+}
+
+// Compile a pattern so that it only matches a whole value
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
@@ -0,0 +1,213 @@
+package internal_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testNormalize(t *testing.T, when spec.G, it spec.S) {
+	type TestCase struct {
+		prompt   internal.Prompt
+		locale   string
+		value    string
+		expected string
+	}
+	number := internal.Prompt{Name: "Count", Prompt: "How many", Type: internal.NumberType}
+	date := internal.Prompt{Name: "Start", Prompt: "When", Type: internal.DateType}
+	formattedDate := internal.Prompt{Name: "Start", Prompt: "When", Type: internal.DateType, Format: "Jan 2, 2006"}
+	list := internal.Prompt{Name: "Services", Prompt: "Which services", Type: internal.ListType}
+
+	testCases := []TestCase{
+		{number, "en_US.UTF-8", "1,234.5", "1234.5"},
+		{number, "de_DE.UTF-8", "1.234,5", "1234.5"},
+		{number, "fr_FR.UTF-8", "1 234,5", "1234.5"},
+		{number, "de_CH.UTF-8", "1'234.5", "1234.5"},
+		{number, "C", "42", "42"},
+		{date, "en_US.UTF-8", "12/31/2022", "2022-12-31"},
+		{date, "en_GB.UTF-8", "31/12/2022", "2022-12-31"},
+		{date, "de_DE.UTF-8", "31.12.2022", "2022-12-31"},
+		{date, "de_DE.UTF-8", "2022-12-31", "2022-12-31"},
+		{formattedDate, "de_DE.UTF-8", "Dec 31, 2022", "2022-12-31"},
+		{list, "C", "api\n  web \n\n", "api\nweb"},
+		{list, "C", "api, web", "api\nweb"},
+		{list, "C", "a, b\nc", "a, b\nc"},
+	}
+	for _, testCase := range testCases {
+		current := testCase
+		when("a typed value is normalized", func() {
+			it("produces the canonical form", func() {
+				value, err := internal.Normalize(current.prompt, current.value, internal.LookupLocale(current.locale))
+				h.AssertNil(t, err)
+				h.AssertEq(t, value, current.expected)
+			})
+		})
+	}
+
+	invalidCases := []TestCase{
+		{number, "en_US.UTF-8", "1.234,5", ""},
+		{number, "en_US.UTF-8", "many", ""},
+		{date, "en_US.UTF-8", "31/12/2022", ""},
+		{formattedDate, "en_US.UTF-8", "2022-12-31", ""},
+	}
+	for _, testCase := range invalidCases {
+		current := testCase
+		when("an invalid typed value is normalized", func() {
+			it("fails", func() {
+				_, err := internal.Normalize(current.prompt, current.value, internal.LookupLocale(current.locale))
+				h.AssertNotNil(t, err)
+			})
+		})
+	}
+}
+
+func testCheckPattern(t *testing.T, when spec.G, it spec.S) {
+	module := internal.Prompt{Name: "Module", Prompt: "Go module path", Pattern: `[a-z0-9.-]+(/[A-Za-z0-9._-]+)*`}
+	k8sName := internal.Prompt{Name: "Name", Prompt: "Service name", Pattern: `[a-z0-9]([-a-z0-9]*[a-z0-9])?`, PatternMessage: "use lower case letters, digits and dashes"}
+
+	when("a value matches the pattern", func() {
+		it("is accepted", func() {
+			h.AssertNil(t, internal.CheckPattern(module, "github.com/org/repo"))
+			h.AssertNil(t, internal.CheckPattern(k8sName, "my-service"))
+			h.AssertNil(t, internal.CheckPattern(k8sName, ""))
+		})
+	})
+
+	when("a value does not match the whole pattern", func() {
+		it("reports the pattern", func() {
+			err := internal.CheckPattern(module, "github.com/org/repo with spaces")
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "does not match the pattern")
+		})
+
+		it("reports the custom message", func() {
+			err := internal.CheckPattern(k8sName, "My_Service")
+			h.AssertNotNil(t, err)
+			h.AssertEq(t, err.Error(), "use lower case letters, digits and dashes")
+		})
+	})
+
+	when("an item of a list does not match the pattern", func() {
+		it("reports the item", func() {
+			services := internal.Prompt{Name: "Services", Prompt: "Which services", Type: internal.ListType, Pattern: `[a-z]+`}
+			h.AssertNil(t, internal.CheckPattern(services, "api\nweb"))
+			err := internal.CheckPattern(services, "api\nWeb UI")
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "Web UI does not match the pattern")
+		})
+	})
+}
+
+func testCheckLength(t *testing.T, when spec.G, it spec.S) {
+	identifier := internal.Prompt{Name: "ID", Prompt: "Identifier", MinLength: 3, MaxLength: 8}
+
+	when("a value is within the length limits", func() {
+		it("is accepted", func() {
+			h.AssertNil(t, internal.CheckLength(identifier, "abc"))
+			h.AssertNil(t, internal.CheckLength(identifier, "abcdefgh"))
+			h.AssertNil(t, internal.CheckLength(identifier, "äöüß"))
+		})
+	})
+
+	when("a value is outside the length limits", func() {
+		it("reports the limit", func() {
+			err := internal.CheckLength(identifier, "")
+			h.AssertNotNil(t, err)
+			h.AssertEq(t, err.Error(), `"" is shorter than 3 characters`)
+			err = internal.CheckLength(identifier, "abcdefghi")
+			h.AssertNotNil(t, err)
+			h.AssertEq(t, err.Error(), `"abcdefghi" is longer than 8 characters`)
+		})
+	})
+
+	when("an item of a list is outside the length limits", func() {
+		it("reports the item", func() {
+			services := internal.Prompt{Name: "Services", Prompt: "Which services", Type: internal.ListType, MaxLength: 3}
+			h.AssertNil(t, internal.CheckLength(services, "api\nweb"))
+			err := internal.CheckLength(services, "api\nworker")
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), `"worker" is longer than 3 characters`)
+		})
+	})
+
+	when("a prompts file has length limits that cannot be met", func() {
+		it("is reported", func() {
+			_, err := internal.NewTemplate(io.NopCloser(strings.NewReader(`[[prompt]]
+name = "ID"
+prompt = "Identifier"
+min-length = 5
+max-length = 3
+`)), nil, nil)
+			var fileErr internal.PromptFileError
+			h.AssertTrue(t, errors.As(err, &fileErr))
+			h.AssertEq(t, fileErr.Problems[0].Message, "min-length 5 of prompt ID is greater than its max-length 3")
+		})
+	})
+}
+
+func testErrorMessage(t *testing.T, when spec.G, it spec.S) {
+	promptFile := `[[prompt]]
+name = "Replicas"
+prompt = "Number of replicas"
+type = "number"
+required = true
+error-message = "give a whole number, such as 3"
+`
+
+	when("an answer is invalid", func() {
+		it("is reported with the error message", func() {
+			prompt := internal.Prompt{Name: "Replicas", Prompt: "Number of replicas", Type: internal.NumberType, Required: true, ErrorMessage: "give a whole number, such as 3"}
+			question := internal.NewQuestion(prompt)
+			err := question.Validate("")
+			h.AssertNotNil(t, err)
+			h.AssertEq(t, err.Error(), "give a whole number, such as 3")
+			err = question.Validate("three")
+			h.AssertNotNil(t, err)
+			h.AssertEq(t, err.Error(), "give a whole number, such as 3")
+			h.AssertNil(t, question.Validate("3"))
+		})
+	})
+
+	when("a prompt has a pattern message", func() {
+		it("is reported for an answer that does not match", func() {
+			prompt := internal.Prompt{Name: "ID", Prompt: "Identifier", Pattern: "[a-z]+", PatternMessage: "use lower case letters", ErrorMessage: "invalid identifier"}
+			err := internal.CheckPattern(prompt, "ID")
+			h.AssertNotNil(t, err)
+			h.AssertEq(t, err.Error(), "use lower case letters")
+		})
+	})
+
+	when("an argument is invalid", func() {
+		it("is asked for again", func() {
+			test := func(stdio terminal.Stdio) (map[string]string, error) {
+				template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), map[string]string{"Replicas": "three"}, nil)
+				if err != nil {
+					return nil, err
+				}
+				return template.Ask(survey.WithStdio(stdio.In, stdio.Out, stdio.Err))
+			}
+			RunTest(t, func(c expectConsole) {
+				c.ExpectString("the value given for Replicas is invalid: give a whole number, such as 3")
+				c.ExpectString("Number of replicas")
+				c.SendLine("3")
+				c.ExpectEOF()
+			}, test, map[string]string{"Replicas": "3"})
+		})
+
+		it("fails when not interactive", func() {
+			template, err := internal.NewTemplate(io.NopCloser(strings.NewReader(promptFile)), map[string]string{"Replicas": "three"}, nil)
+			h.AssertNil(t, err)
+			_, err = template.Defaults()
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "invalid value for Replicas: give a whole number, such as 3")
+		})
+	})
+}
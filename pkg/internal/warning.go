@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Warning reports a part of a template that is skipped, or that is written
+// differently than it is in the template, so the project may be incomplete
+type Warning struct {
+	// Path is the path of the part within the template
+	Path    string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Path, w.Message)
+}
+
+// Report a skipped part of a template, when warnings are wanted
+func (opts FetchOptions) warn(path string, message string) {
+	if opts.Warn != nil {
+		opts.Warn(Warning{Path: filepath.ToSlash(filepath.Clean(path)), Message: message})
+	}
+}
+
+// FindWarnings lists the parts of the template in dir that are not rendered
+// as they are written.  A symbolic link to a text file is rendered, so it is
+// written as a copy of the file it links to.
+func FindWarnings(dir string) ([]Warning, error) {
+	warnings := []Warning{}
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && IsIgnoredDirectory(entry.Name()) {
+			return filepath.SkipDir
+		}
+		if entry.Type()&os.ModeSymlink == 0 || !isTextfile(path) {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		warnings = append(warnings, Warning{
+			Path:    filepath.ToSlash(relPath),
+			Message: fmt.Sprintf("symbolic link to %s is written as a copy of the file", target),
+		})
+		return nil
+	})
+	return warnings, err
+}
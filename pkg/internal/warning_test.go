@@ -0,0 +1,68 @@
+package internal_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testWarnings(t *testing.T, when spec.G, it spec.S) {
+	var tmpDir string
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("a template stream contains links", func() {
+		it("warns that each link is skipped", func() {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: "main.go", Mode: 0644, Size: 12, Typeflag: tar.TypeReg}))
+			_, err := tw.Write([]byte("package main"))
+			h.AssertNil(t, err)
+			h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: "link.go", Linkname: "main.go", Typeflag: tar.TypeSymlink}))
+			h.AssertNil(t, tw.Close())
+
+			warnings := []internal.Warning{}
+			opts := internal.FetchOptions{Reader: &buf, Warn: func(w internal.Warning) {
+				warnings = append(warnings, w)
+			}}
+			root, err := internal.URLToFs("", tmpDir, opts)
+			h.AssertNil(t, err)
+			h.AssertEq(t, warnings, []internal.Warning{{Path: "link.go", Message: "link to main.go is skipped"}})
+			_, err = os.Lstat(filepath.Join(root, "link.go"))
+			h.AssertNotNil(t, err)
+		})
+	})
+
+	when("a template contains a symbolic link to a text file", func() {
+		it("warns that the link is written as a copy", func() {
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# {{.Name}}"), 0600))
+			h.AssertNil(t, os.Mkdir(filepath.Join(tmpDir, "docs"), 0700))
+			h.AssertNil(t, os.Symlink("../README.md", filepath.Join(tmpDir, "docs", "index.md")))
+
+			warnings, err := internal.FindWarnings(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, warnings, []internal.Warning{{Path: "docs/index.md", Message: "symbolic link to ../README.md is written as a copy of the file"}})
+		})
+
+		it("does not warn about a template without links", func() {
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# {{.Name}}"), 0600))
+
+			warnings, err := internal.FindWarnings(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(warnings), 0)
+		})
+	})
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"golang.org/x/term"
+)
+
+// minimumWidth is the fewest columns that prompts are fitted to, narrower
+// terminals are treated as this wide so that choices remain recognisable
+const minimumWidth = 20
+
+// Ellipsis ends text that is truncated to fit the terminal
+const Ellipsis = "…"
+
+// The number of columns of the terminal that out writes to, or of COLUMNS
+// when out is not a terminal, such as the log of a CI job.  The width is 0
+// when it is not known.
+func terminalWidth(out terminal.FileWriter) int {
+	if width, _, err := term.GetSize(int(out.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
+// Fit prompt to a terminal of width columns: the text of the prompt and its
+// help wrap at the last space that fits and the labels of choices are
+// truncated, so that survey redraws each line in place.  A prompt is
+// unchanged when the width is not known.
+func (p Prompt) fit(width int) Prompt {
+	if width <= 0 {
+		return p
+	}
+	if width < minimumWidth {
+		width = minimumWidth
+	}
+	// the prompt and help follow an icon and a space
+	p.Prompt = WrapText(p.Prompt, width-2)
+	p.Help = WrapText(p.Help, width-2)
+	if len(p.Choices) != 0 {
+		// options follow the cursor, and the check box of a multi-select
+		indent := 2
+		if p.Type == ListType {
+			indent = 4
+		}
+		p.Labels = p.Options()
+		for i, label := range p.Labels {
+			p.Labels[i] = TruncateText(label, width-indent)
+		}
+	}
+	return p
+}
+
+// TruncateText shortens each line of text to at most width characters, a
+// line that is shortened ends with Ellipsis
+func TruncateText(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if width <= 0 || utf8.RuneCountInString(line) <= width {
+			continue
+		}
+		runes := []rune(line)
+		lines[i] = strings.TrimRight(string(runes[:width-1]), " ") + Ellipsis
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WrapText breaks each line of text at the last space before width
+// characters, a word longer than width is truncated with Ellipsis
+func WrapText(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+	wrapped := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		if utf8.RuneCountInString(line) <= width {
+			// lines that fit keep their spacing
+			wrapped = append(wrapped, line)
+			continue
+		}
+		current := ""
+		for _, word := range strings.Fields(line) {
+			word = TruncateText(word, width)
+			if current != "" && utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > width {
+				wrapped = append(wrapped, current)
+				current = ""
+			}
+			if current != "" {
+				current += " "
+			}
+			current += word
+		}
+		wrapped = append(wrapped, current)
+	}
+	return strings.Join(wrapped, "\n")
+}
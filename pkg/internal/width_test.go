@@ -0,0 +1,52 @@
+package internal_test
+
+import (
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testWidth(t *testing.T, when spec.G, it spec.S) {
+	when("text is truncated", func() {
+		it("keeps a line that fits", func() {
+			h.AssertEq(t, internal.TruncateText("Go modules", 10), "Go modules")
+		})
+
+		it("ends a line that is too long with an ellipsis", func() {
+			h.AssertEq(t, internal.TruncateText("Go modules with workspaces", 12), "Go modules…")
+		})
+
+		it("counts characters rather than bytes", func() {
+			h.AssertEq(t, internal.TruncateText("überschrift", 6), "übers…")
+		})
+
+		it("truncates each line", func() {
+			h.AssertEq(t, internal.TruncateText("first line\nsecond line", 6), "first…\nsecon…")
+		})
+
+		it("leaves text unchanged when the width is not known", func() {
+			h.AssertEq(t, internal.TruncateText("Go modules", 0), "Go modules")
+		})
+	})
+
+	when("text is wrapped", func() {
+		it("breaks at the last space that fits", func() {
+			h.AssertEq(t, internal.WrapText("Which license should the project use?", 16), "Which license\nshould the\nproject use?")
+		})
+
+		it("keeps the spacing of lines that fit", func() {
+			h.AssertEq(t, internal.WrapText("  indented\nline", 16), "  indented\nline")
+		})
+
+		it("truncates a word longer than the width", func() {
+			h.AssertEq(t, internal.WrapText("see https://example.com/licenses", 16), "see\nhttps://example…")
+		})
+
+		it("leaves text unchanged when the width is not known", func() {
+			h.AssertEq(t, internal.WrapText("Which license should the project use?", 0), "Which license should the project use?")
+		})
+	})
+}
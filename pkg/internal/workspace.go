@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// Component is a template scaffolded into a folder of a workspace
+type Component struct {
+	URL      string `toml:"url"`
+	SubPath  string `toml:"sub-path"`
+	Ref      string `toml:"ref"`
+	Template string `toml:"template"`
+	// Path is the folder of the workspace, such as api, in which the
+	// component is created
+	Path      string            `toml:"path"`
+	Arguments map[string]string `toml:"arguments"`
+}
+
+// Workspace describes a single project, such as a repository with api, web
+// and infra folders, created from several templates in one invocation
+type Workspace struct {
+	// OutputFolder is the folder in which the components are created
+	OutputFolder string `toml:"output-folder"`
+	// Variables are shared by every component
+	Variables  map[string]string `toml:"variables"`
+	Components []Component       `toml:"component"`
+}
+
+func ReadWorkspace(workspaceFile string) (Workspace, error) {
+	workspace := Workspace{}
+	workspaceData, err := ReadFile(workspaceFile)
+	if err != nil {
+		return workspace, err
+	}
+
+	if _, err := toml.Decode(workspaceData, &workspace); err != nil {
+		return workspace, errors.Wrap(err, fmt.Sprintf("%s file does not match required format", workspaceFile))
+	}
+	if workspace.Variables == nil {
+		workspace.Variables = map[string]string{}
+	}
+
+	paths := map[string]int{}
+	for i, component := range workspace.Components {
+		if component.URL == "" {
+			return workspace, fmt.Errorf("%s file contains component %d with missing required field; url required", workspaceFile, i+1)
+		}
+		if component.Path == "" {
+			return workspace, fmt.Errorf("%s file contains component %d with missing required field; path required", workspaceFile, i+1)
+		}
+		componentPath := path.Clean(filepath.ToSlash(component.Path))
+		if isOutside(componentPath) {
+			return workspace, fmt.Errorf("%s file contains component %d with path %s outside of the workspace", workspaceFile, i+1, component.Path)
+		}
+		if earlier, ok := paths[componentPath]; ok {
+			return workspace, fmt.Errorf("%s file contains components %d and %d with the same path %s", workspaceFile, earlier+1, i+1, component.Path)
+		}
+		paths[componentPath] = i
+		workspace.Components[i].Path = filepath.FromSlash(componentPath)
+		if component.Arguments == nil {
+			workspace.Components[i].Arguments = map[string]string{}
+		}
+	}
+	return workspace, nil
+}
@@ -0,0 +1,84 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	h "github.com/buildpacks/pack/testhelpers"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+func testReadWorkspace(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir        string
+		workspaceFile string
+	)
+
+	it.Before(func() {
+		tmpDir, _ = os.MkdirTemp("", "test")
+		workspaceFile = filepath.Join(tmpDir, "workspace.toml")
+	})
+
+	it.After(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	when("Reading a workspace file", func() {
+		it("reads the shared variables and each component", func() {
+			content := `output-folder = "shop"
+variables = { ProjectName = "shop" }
+
+[[component]]
+url = "https://github.com/org/templates"
+sub-path = "go-api"
+path = "api/"
+
+[[component]]
+url = "https://github.com/org/web-template"
+ref = "v2"
+path = "web"
+arguments = { Framework = "react" }
+`
+			h.AssertNil(t, os.WriteFile(workspaceFile, []byte(content), 0600))
+
+			w, err := internal.ReadWorkspace(workspaceFile)
+			h.AssertNil(t, err)
+			h.AssertEq(t, w.OutputFolder, "shop")
+			h.AssertEq(t, w.Variables, map[string]string{"ProjectName": "shop"})
+			h.AssertEq(t, len(w.Components), 2)
+			h.AssertEq(t, w.Components[0].Path, "api")
+			h.AssertEq(t, w.Components[0].SubPath, "go-api")
+			h.AssertEq(t, w.Components[0].Arguments, map[string]string{})
+			h.AssertEq(t, w.Components[1].Ref, "v2")
+			h.AssertEq(t, w.Components[1].Arguments, map[string]string{"Framework": "react"})
+		})
+
+		it("fails when a component has no path", func() {
+			h.AssertNil(t, os.WriteFile(workspaceFile, []byte("[[component]]\nurl = \"testdata/one\""), 0600))
+
+			_, err := internal.ReadWorkspace(workspaceFile)
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "path required")
+		})
+
+		it("fails when a component is outside of the workspace", func() {
+			h.AssertNil(t, os.WriteFile(workspaceFile, []byte("[[component]]\nurl = \"testdata/one\"\npath = \"../api\""), 0600))
+
+			_, err := internal.ReadWorkspace(workspaceFile)
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "outside of the workspace")
+		})
+
+		it("fails when two components share a path", func() {
+			content := "[[component]]\nurl = \"testdata/one\"\npath = \"api\"\n\n[[component]]\nurl = \"testdata/two\"\npath = \"./api\"\n"
+			h.AssertNil(t, os.WriteFile(workspaceFile, []byte(content), 0600))
+
+			_, err := internal.ReadWorkspace(workspaceFile)
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "components 1 and 2 with the same path ./api")
+		})
+	})
+}
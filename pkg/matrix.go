@@ -0,0 +1,125 @@
+package scafall
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+
+	cp "github.com/otiai10/copy"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+// MatrixResult reports the outcome of rendering a template with a single
+// combination of answers.
+type MatrixResult struct {
+	// Arguments are the answers from the matrix file
+	Arguments map[string]string
+	// OutputFolder contains the rendered project when rendering or validation
+	// failed, it is removed otherwise
+	OutputFolder string
+	// Output of the validation command that failed
+	Output string
+	Err    error
+}
+
+// TestMatrix renders the template once for every combination of answers in
+// matrixFile and runs the validation commands of matrixFile in each rendered
+// project.  Prompts without an answer in the matrix take their default value.
+// A result is returned for every combination, failed combinations do not
+// prevent the remaining combinations from running.
+func (s Scafall) TestMatrix(matrixFile string) ([]MatrixResult, error) {
+	matrix, err := internal.ReadMatrix(matrixFile)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.clone()
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(s.CloneCache)
+	chosen, err := s.chooseTemplate()
+	if err != nil {
+		return nil, err
+	}
+	inFs := path.Join(s.CloneCache, chosen)
+	err = s.checkPolicy(inFs)
+	if err != nil {
+		return nil, err
+	}
+
+	combinations := matrix.Combinations()
+	results := make([]MatrixResult, len(combinations))
+	for i, combination := range combinations {
+		results[i] = s.testCombination(inFs, combination, matrix.Validate)
+	}
+	return results, nil
+}
+
+func (s Scafall) testCombination(inFs string, combination map[string]string, validate []string) MatrixResult {
+	result := MatrixResult{Arguments: combination}
+	arguments := map[string]string{}
+	for k, v := range s.arguments() {
+		arguments[k] = v
+	}
+	for k, v := range combination {
+		arguments[k] = v
+	}
+
+	// Apply moves binary files out of the template, so render from a copy
+	templateDir, err := os.MkdirTemp("", "scafall")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer os.RemoveAll(templateDir)
+	err = cp.Copy(inFs, templateDir)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.OutputFolder, err = os.MkdirTemp("", "scafall-matrix")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	template, err := s.readTemplate(templateDir, arguments)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	values, err := template.Defaults()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if s.StrictVars {
+		if err := internal.CheckUndeclared(templateDir, values, template.Settings()); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	_, err = internal.ApplyWithManifest(templateDir, values, result.OutputFolder, template.Settings(), nil, s.RenderTimeout, s.HardLinks)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	for _, command := range validate {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = result.OutputFolder
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			result.Output = string(output)
+			result.Err = fmt.Errorf("validation %s failed: %s", command, err)
+			return result
+		}
+	}
+
+	os.RemoveAll(result.OutputFolder)
+	result.OutputFolder = ""
+	return result
+}
@@ -0,0 +1,38 @@
+package scafall
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+// PolicyFile is the file, within the user config folder, that restricts the
+// templates that may be used
+const PolicyFile string = "scafall/policy.toml"
+
+// Policy restricts the templates that may be used by host and path, such as
+// github.com/acme/*.
+type Policy = internal.Policy
+
+// FunctionPolicy restricts the functions that templates may call.
+type FunctionPolicy = internal.FunctionPolicy
+
+// FunctionErrors reports every template file calling a function refused by
+// the FunctionPolicy.
+type FunctionErrors = internal.FunctionErrors
+
+// ReadPolicy reads a Policy from policyFile.  A missing file allows every
+// template.
+func ReadPolicy(policyFile string) (Policy, error) {
+	return internal.ReadPolicy(policyFile)
+}
+
+// Read the policy in the user config folder
+func defaultPolicy() (Policy, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return Policy{}, nil
+	}
+	return internal.ReadPolicy(filepath.Join(configDir, PolicyFile))
+}
@@ -0,0 +1,163 @@
+package scafall
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+// ResumeFile is the file, within the user cache folder, that records the last
+// scaffold that failed while its project was written
+const ResumeFile string = "scafall/resume.toml"
+
+// Checkpoint records a scaffold that failed while its project was written.
+type Checkpoint = internal.Checkpoint
+
+// ResumableError reports a scaffold that failed while its project was
+// written, the project can be finished with Resume.
+type ResumableError struct {
+	Err error
+}
+
+func (e ResumableError) Error() string {
+	return fmt.Sprintf("%s; once the cause is fixed finish the project with scafall resume", e.Err)
+}
+
+func (e ResumableError) Unwrap() error {
+	return e.Err
+}
+
+// Record a scaffold that fails while its project is written, such as when
+// the disk is full, in the ResumeFile of the user cache folder, keeping the
+// fetched template so that the project can be finished by Resume.  Only a
+// failure to write a file, once other files of the project were written, is
+// recorded.
+func WithCheckpoints(checkpoints bool) Option {
+	return func(s *Scafall) {
+		s.Checkpoints = checkpoints
+	}
+}
+
+func resumeFile() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, ResumeFile), nil
+}
+
+// FailedScaffold reads the Checkpoint of the last scaffold that failed while
+// its project was written, ok is false when there is none.
+func FailedScaffold() (checkpoint Checkpoint, ok bool, err error) {
+	file, err := resumeFile()
+	if err != nil {
+		return checkpoint, false, err
+	}
+	if _, err := os.Stat(file); err != nil {
+		return checkpoint, false, nil
+	}
+	checkpoint, err = internal.ReadCheckpoint(file)
+	return checkpoint, err == nil, err
+}
+
+// Resume finishes the last scaffold that failed while its project was
+// written, with the template that was fetched and the values that were given
+// for it, so the template is not fetched and no prompt is asked.  Only the
+// files that were not written are rendered.  Options, such as WithArguments
+// for the answers to prompts marked record = false, control how the project
+// is written.
+func Resume(opts ...Option) (Result, error) {
+	checkpoint, ok, err := FailedScaffold()
+	if err != nil {
+		return Result{}, err
+	}
+	if !ok {
+		return Result{}, fmt.Errorf("there is no failed scaffold to resume")
+	}
+	result := Result{OutputFolder: checkpoint.OutputFolder, Template: checkpoint.Template}
+	if _, err := os.Stat(checkpoint.Staged); err != nil {
+		if file, err := resumeFile(); err == nil {
+			os.Remove(file)
+		}
+		return result, fmt.Errorf("the template fetched for %s no longer exists; scaffold the project again", checkpoint.URL)
+	}
+
+	s, err := NewScafall(checkpoint.URL, append(opts, WithOutputFolder(checkpoint.OutputFolder))...)
+	if err != nil {
+		return result, err
+	}
+	s.CloneCache = checkpoint.Staged
+	inFs := path.Join(s.CloneCache, checkpoint.Template)
+	values, err := s.planValues(inFs, checkpoint.Variables)
+	if err != nil {
+		return result, err
+	}
+	result.Warnings, err = s.warnings(inFs)
+	if err != nil {
+		return result, err
+	}
+	err = s.apply(inFs, values)
+	if err != nil {
+		err = errors.Wrap(err, "failed to resume scaffold")
+		if s.checkpoint(checkpoint.Template, values, err) {
+			return result, ResumableError{Err: err}
+		}
+		return result, err
+	}
+
+	if file, err := resumeFile(); err == nil {
+		os.Remove(file)
+	}
+	s.cleanUp()
+	result.Variables = checkpoint.Variables
+	return result, nil
+}
+
+// Record that the project could not be written from the chosen template with
+// values, keeping the fetched template so that the project can be finished by
+// Resume.  Failures that resuming cannot fix, such as a template that cannot
+// be rendered, or that happen before any file is written are not recorded.  A
+// checkpoint that cannot be recorded is not reported, scaffolding then fails
+// with err alone.
+func (s *Scafall) checkpoint(chosen string, values map[string]string, err error) bool {
+	var writeErr internal.WriteError
+	if !s.Checkpoints || !errors.As(err, &writeErr) || writeErr.Written == 0 {
+		return false
+	}
+	file, fileErr := resumeFile()
+	if fileErr != nil {
+		return false
+	}
+	recorded, recordErr := s.recordedValues(path.Join(s.CloneCache, chosen), values)
+	if recordErr != nil {
+		return false
+	}
+	outputFolder, absErr := filepath.Abs(s.OutputFolder)
+	if absErr != nil {
+		return false
+	}
+
+	// a failed scaffold that is not resumed is abandoned by the next failure
+	if previous, readErr := internal.ReadCheckpoint(file); readErr == nil && previous.Staged != s.CloneCache {
+		os.RemoveAll(previous.Staged)
+	}
+	checkpoint := Checkpoint{
+		URL:          s.URL,
+		Template:     chosen,
+		Staged:       s.CloneCache,
+		OutputFolder: outputFolder,
+		Variables:    recorded,
+		Error:        err.Error(),
+	}
+	if internal.WriteCheckpoint(checkpoint, file) != nil {
+		return false
+	}
+	// the fetched template is kept for Resume
+	s.CloneCache = ""
+	return true
+}
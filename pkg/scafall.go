@@ -4,30 +4,228 @@
 package scafall
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	texttemplate "text/template"
+	"time"
 
 	"github.com/buildpacks/scafall/pkg/internal"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/pkg/errors"
 )
 
 // Scafall allows programmatic control over the default values for variables.
 // Any provided Arguments cause prompts for the same variable name to be skipped.
 type Scafall struct {
-	URL          string
-	Arguments    map[string]string
-	OutputFolder string
-	SubPath      string
-	CloneCache   string
+	URL           string
+	Mirrors       []string
+	Arguments     map[string]string
+	Answers       map[string]interface{}
+	OutputFolder  string
+	SubPath       string
+	Ref           string
+	HTTPUsername  string
+	HTTPPassword  string
+	TemplateCache string
+	Offline       bool
+	FS            fs.FS
+	Reader        io.Reader
+	ManifestFile  string
+	ChangedOnly   bool
+	Submodules    bool
+	Proxy         string
+	CABundle      string
+	HostKeyMode   string
+	Template      string
+	NoPrompt      bool
+	Checksum      string
+	Policy        Policy
+	FetchProgress func(FetchEvent)
+	CloneCache    string
+	RenderTimeout time.Duration
+	HardLinks     bool
+	StrictVars    bool
+	DryRun        bool
+	Seed          *int64
+	TemplateFuncs texttemplate.FuncMap
+	FileMode      os.FileMode
+	DirMode       os.FileMode
+	ClampModes    bool
+	PromptOutput  *os.File
+	Context       context.Context
+	Providers     map[string]ValueProvider
+	Validators    map[string]Validator
+	Matching      ChoiceMatching
+	Language      string
+	Confirm       bool
+	ShowRenames   bool
+	Checkpoints   bool
+	// the parts of the fetched template that were skipped
+	fetchWarnings []Warning
 }
 
 type Option func(*Scafall)
 
-// Set the output folder in which to create scaffold a template.
+// Result describes a project created by Scaffold.
+type Result struct {
+	// OutputFolder is the folder in which the project was created
+	OutputFolder string
+	// Template is the template chosen from a collection, empty otherwise
+	Template string
+	// Variables contains the value of every template variable, except the
+	// answers to prompts marked record = false
+	Variables map[string]string
+	// Renames contains the output path of every file whose path uses
+	// template variables, keyed by the path of the file in the template
+	Renames map[string]string
+	// Warnings lists the parts of the template that were skipped, or that
+	// were written differently than they are in the template
+	Warnings []Warning
+	// Tree lists every file and folder of the project in a dry run, in which
+	// the project is not written to the output folder
+	Tree []TreeEntry
+}
+
+// Prompt describes a question asked by a template.
+type Prompt = internal.Prompt
+
+// PromptFileError lists every mistake found in the prompts.toml file of a
+// template, each with its line and the field in error.
+type PromptFileError = internal.PromptFileError
+
+// AnswerError lists every answer provided by WithAnswers that is not a valid
+// answer to its prompt.
+type AnswerError = internal.AnswerError
+
+// ErrPromptAborted is returned when the end-user dismisses a prompt, stdin is
+// closed before a prompt is answered or the context of a Scafall is cancelled
+// while prompting.
+var ErrPromptAborted = internal.ErrPromptAborted
+
+// Code identifies a user-facing message, such as SCFL-0305 for
+// ErrPromptAborted, so that it can be searched for whatever language the
+// message is written in.
+type Code = internal.Code
+
+// CodeOf finds the code of the message of err, errors that are not described
+// by the catalogue of messages have no code.
+func CodeOf(err error) (Code, bool) {
+	return internal.CodeOf(err)
+}
+
+// AskError reports the prompt that could not be asked, such as when there is
+// no terminal to ask it on.
+type AskError = internal.AskError
+
+// MissingValuesError lists every required variable that has no value when
+// prompting is disabled by WithNoPrompt.
+type MissingValuesError = internal.MissingValuesError
+
+// AuthError reports that a template could not be fetched because the server
+// requires credentials, or rejected the credentials provided by WithHTTPAuth.
+type AuthError = internal.AuthError
+
+// HostKeyError reports that the key of an SSH server is not in known_hosts,
+// or does not match its key in known_hosts.
+type HostKeyError = internal.HostKeyError
+
+// The ways in which the keys of SSH servers are checked by WithHostKeyChecking.
+const (
+	HostKeyStrict    = internal.HostKeyStrict
+	HostKeyAcceptNew = internal.HostKeyAcceptNew
+	HostKeyOff       = internal.HostKeyOff
+)
+
+// ValueProvider resolves the key of a provider:key reference, used in place
+// of a value in arguments, answers and .override.toml, at scaffold time.
+type ValueProvider = internal.ValueProvider
+
+// EnvProvider resolves env:NAME to the value of the environment variable
+// NAME.
+type EnvProvider = internal.EnvProvider
+
+// FileProvider resolves file:PATH to the content of the file at PATH.
+type FileProvider = internal.FileProvider
+
+// VaultProvider resolves vault:PATH#FIELD to a field of a secret read from a
+// HashiCorp Vault server.
+type VaultProvider = internal.VaultProvider
+
+// Validator checks the answer to a prompt, returning an error that describes
+// why an invalid answer is rejected.
+type Validator = internal.Validator
+
+// ChoiceMatching controls how arguments and answers are matched against the
+// choices of a prompt.
+type ChoiceMatching = internal.ChoiceMatching
+
+// Plan records the values of all template variables for later use by
+// ApplyPlan.
+type Plan = internal.Plan
+
+// ReadPlan reads a Plan from planFile.
+func ReadPlan(planFile string) (Plan, error) {
+	return internal.ReadPlan(planFile)
+}
+
+// WritePlan writes plan to planFile.
+func WritePlan(plan Plan, planFile string) error {
+	return internal.WritePlan(plan, planFile)
+}
+
+// ReadAnswers reads answers, for use with WithAnswers, from a TOML file of
+// name = answer pairs such as a file written by WriteAnswers.
+func ReadAnswers(answersFile string) (map[string]interface{}, error) {
+	return internal.ReadAnswers(answersFile)
+}
+
+// FetchEvent reports progress while a template is fetched.
+type FetchEvent = internal.FetchEvent
+
+// Warning reports a part of a template that is skipped, or that is written
+// differently than it is in the template, so the project may be incomplete.
+type Warning = internal.Warning
+
+// FileError reports the template file that caused scaffolding to fail.
+type FileError = internal.FileError
+
+// UndeclaredVariableError lists the variables used by the files of a template
+// that are neither prompted for nor given as arguments, it is reported by
+// WithStrictVariables.
+type UndeclaredVariableError = internal.UndeclaredVariableError
+
+// VariableUse is a variable used by a file of a template.
+type VariableUse = internal.VariableUse
+
+// SpaceError reports that the filesystem of the output folder has too little
+// space, or too few inodes, for the project.  It is reported before any file
+// is written.
+type SpaceError = internal.SpaceError
+
+// TreeEntry describes a file or folder of a project rendered in a dry run.
+type TreeEntry = internal.TreeEntry
+
+// WriteTree draws the files and folders of a project rendered in a dry run
+// below root, with the mode of each and the size of each file.
+func WriteTree(w io.Writer, root string, entries []TreeEntry) error {
+	return internal.WriteTree(w, root, entries)
+}
+
+// DefaultRenderTimeout bounds the time taken to render each file of a template.
+const DefaultRenderTimeout = internal.DefaultRenderTimeout
+
+// Set the output folder in which to create scaffold a template.  The folder
+// may use template variables, such as ./{{.ProjectName}}, which are rendered
+// after prompting.  When no output folder is set, the project is created in a
+// folder named after the ProjectName variable, or otherwise the template.
 func WithOutputFolder(folder string) Option {
 	return func(s *Scafall) {
 		s.OutputFolder = folder
@@ -41,25 +239,389 @@ func WithArguments(arguments map[string]string) Option {
 	}
 }
 
+// Answer prompts before they are asked.  Unlike arguments, every answer is
+// checked in the same way as an answer given by the end-user: it must be one
+// of the choices, match the type and pattern of its prompt, and name a prompt
+// of the template.  Scaffolding fails with an AnswerError listing every
+// invalid answer before any prompt is asked.  Answers may be strings, bools,
+// numbers or, for date prompts, a time.Time.
+func WithAnswers(answers map[string]interface{}) Option {
+	return func(s *Scafall) {
+		s.Answers = answers
+	}
+}
+
 // Use a sub folder within the template repository as the source for a template.
+// A sub folder can also be given in the url, such as
+// https://github.com/org/templates//go/cli, this sub path is then relative to
+// the sub folder in the url.
 func WithSubPath(subPath string) Option {
 	return func(s *Scafall) {
 		s.SubPath = subPath
 	}
 }
 
+// Check out a git branch, tag or commit of the template repository.  A ref can
+// also be given as a url fragment, such as https://github.com/org/repo#v1.0.0,
+// this option takes precedence over the url fragment.
+func WithGitRef(ref string) Option {
+	return func(s *Scafall) {
+		s.Ref = ref
+	}
+}
+
+// Authenticate HTTPS clones of the template repository with a username and
+// password or personal access token.  When no credentials are provided, a
+// token is read from GITHUB_TOKEN or GITLAB_TOKEN for repositories hosted on
+// GitHub or GitLab.
+func WithHTTPAuth(username string, password string) Option {
+	return func(s *Scafall) {
+		s.HTTPUsername = username
+		s.HTTPPassword = password
+	}
+}
+
+// Cache fetched templates in dir.  Templates are cached under the user cache
+// folder by default, an empty dir disables caching.
+func WithTemplateCache(dir string) Option {
+	return func(s *Scafall) {
+		s.TemplateCache = dir
+	}
+}
+
+// Use templates from the template cache rather than fetching them.  Local
+// template folders are always used directly.
+func WithOffline(offline bool) Option {
+	return func(s *Scafall) {
+		s.Offline = offline
+	}
+}
+
+// Write a manifest, containing a checksum of every created file, to
+// manifestFile.
+func WithManifest(manifestFile string) Option {
+	return func(s *Scafall) {
+		s.ManifestFile = manifestFile
+	}
+}
+
+// Only write files whose content has changed since the manifest was written.
+// Unchanged files are left untouched so that their modification times are
+// stable.  Requires WithManifest.
+func WithChangedOnly(changedOnly bool) Option {
+	return func(s *Scafall) {
+		s.ChangedOnly = changedOnly
+	}
+}
+
+// Clone the git submodules of the template repository.  Submodules are cloned
+// by default.
+func WithSubmodules(submodules bool) Option {
+	return func(s *Scafall) {
+		s.Submodules = submodules
+	}
+}
+
+// Fetch templates through the HTTP proxy at proxyURL.  When no proxy is set,
+// the proxy is read from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment
+// variables.
+func WithProxy(proxyURL string) Option {
+	return func(s *Scafall) {
+		s.Proxy = proxyURL
+	}
+}
+
+// Trust the PEM encoded certificates in caBundleFile, in addition to the
+// system certificates, when fetching templates.  This allows templates to be
+// fetched from git servers with self-signed certificates.
+func WithCABundle(caBundleFile string) Option {
+	return func(s *Scafall) {
+		s.CABundle = caBundleFile
+	}
+}
+
+// Check the key of an SSH server against known_hosts, as named by
+// SSH_KNOWN_HOSTS or ~/.ssh/known_hosts, before cloning a template over SSH.
+// HostKeyStrict, the default, rejects a server that is not in known_hosts,
+// HostKeyAcceptNew adds the key of such a server to known_hosts and
+// HostKeyOff accepts any key.  A server whose key has changed is rejected
+// unless checking is off.
+func WithHostKeyChecking(mode string) Option {
+	return func(s *Scafall) {
+		s.HostKeyMode = mode
+	}
+}
+
+// Use template, a folder within a collection of templates, rather than asking
+// the end-user to choose a template from the collection.
+func WithTemplate(template string) Option {
+	return func(s *Scafall) {
+		s.Template = template
+	}
+}
+
+// Never prompt the end-user.  Variables that are not provided as arguments
+// take their default value, and a template must be chosen using WithTemplate
+// when the url points to a collection.  This allows projects to be created
+// headlessly, such as by a server.
+func WithNoPrompt(noPrompt bool) Option {
+	return func(s *Scafall) {
+		s.NoPrompt = noPrompt
+	}
+}
+
+// WithNoInput is WithNoPrompt, named after the --no-input flag.  Scaffolding
+// fails with a MissingValuesError, listing every required variable that has
+// no value, rather than waiting for input that will never arrive.
+func WithNoInput(noInput bool) Option {
+	return WithNoPrompt(noInput)
+}
+
+// Pin the template to checksum, a digest such as sha256:<hex> of the chosen
+// template as recorded in a Plan.  Scaffolding fails if the fetched template
+// does not match the checksum.
+func WithChecksum(checksum string) Option {
+	return func(s *Scafall) {
+		s.Checksum = checksum
+	}
+}
+
+// Restrict the templates that may be used to those allowed by policy.  By
+// default the policy is read from PolicyFile in the user config folder.
+func WithPolicy(policy Policy) Option {
+	return func(s *Scafall) {
+		s.Policy = policy
+	}
+}
+
+// Call progress as the template is fetched, such as to show a progress bar
+// while a large repository is cloned.  Local and embedded templates report no
+// progress.
+func WithFetchProgress(progress func(FetchEvent)) Option {
+	return func(s *Scafall) {
+		s.FetchProgress = progress
+	}
+}
+
+// Fetch the template from each of mirrors, in order, when it cannot be
+// fetched from the url.  Like the url, a mirror may name a ref and a sub path,
+// such as https://mirror.example.com/templates.git//python#v1.0.0.
+func WithMirrors(mirrors ...string) Option {
+	return func(s *Scafall) {
+		s.Mirrors = mirrors
+	}
+}
+
+// Restrict the functions that templates may call, such as to refuse env or
+// getHostByName.  Templates calling a refused function are rejected before
+// prompting.  This replaces the functions section of the policy.
+func WithFunctionPolicy(functions FunctionPolicy) Option {
+	return func(s *Scafall) {
+		s.Policy.Functions = functions
+	}
+}
+
+// Bound the time taken to render each file of the template, so that a
+// pathological template does not hold up scaffolding indefinitely.
+// Scaffolding fails with a FileError naming the file that exceeded the limit.
+// Files are given one minute by default, a timeout of zero disables the limit.
+//
+// The timeout is not a safety boundary.  A render cannot be interrupted, so
+// the file that exceeded the limit keeps rendering in the background, using
+// CPU and memory without limit, until it completes or the program exits.
+// Programs that scaffold untrusted templates should run them in a separate
+// process with limits of its own.
+func WithRenderTimeout(timeout time.Duration) Option {
+	return func(s *Scafall) {
+		s.RenderTimeout = timeout
+	}
+}
+
+// Write binary files with the same content once and hard link the duplicates,
+// reducing the disk used by templates with many identical assets.  Text files
+// are always written separately.
+func WithHardLinks(hardLinks bool) Option {
+	return func(s *Scafall) {
+		s.HardLinks = hardLinks
+	}
+}
+
+// Fail, before any file is written, when the files of the template use
+// variables that are neither prompted for in prompts.toml nor given as
+// arguments.  Without strict variables such a variable is left in place.
+func WithStrictVariables(strict bool) Option {
+	return func(s *Scafall) {
+		s.StrictVars = strict
+	}
+}
+
+// Seed the random functions of templates, such as randAlphaNum and uuidv4,
+// so that the same answers create the same project on every run.  Each file
+// draws its own sequence, so a file is unchanged when files are added to or
+// removed from the template.  The seed is recorded in plans.
+func WithSeed(seed int64) Option {
+	return func(s *Scafall) {
+		s.Seed = &seed
+	}
+}
+
+// Add funcs to the functions that templates can call, such as helpers for
+// buildpacks.  The functions are merged with those of sprig before any file,
+// path or prompt is rendered, and take the place of functions with the same
+// name.
+func WithTemplateFuncs(funcs texttemplate.FuncMap) Option {
+	return func(s *Scafall) {
+		if s.TemplateFuncs == nil {
+			s.TemplateFuncs = texttemplate.FuncMap{}
+		}
+		for name, function := range funcs {
+			s.TemplateFuncs[name] = function
+		}
+	}
+}
+
+// Render the project into a temporary folder, which is removed, rather than
+// the output folder, so that the files and folders it would have are listed
+// in Result.Tree without writing the project.  No manifest is written.
+func WithDryRun(dryRun bool) Option {
+	return func(s *Scafall) {
+		s.DryRun = dryRun
+	}
+}
+
+// Set the permissions of every generated file to mode, such as 0644, rather
+// than keeping the permissions of the template.
+func WithFileMode(mode os.FileMode) Option {
+	return func(s *Scafall) {
+		s.FileMode = mode
+	}
+}
+
+// Set the permissions of every generated folder to mode, such as 0755.  The
+// permissions of the output folder itself are unchanged.
+func WithDirMode(mode os.FileMode) Option {
+	return func(s *Scafall) {
+		s.DirMode = mode
+	}
+}
+
+// Treat the modes of WithFileMode and WithDirMode as the most permissive
+// permissions allowed, so that permissions outside of them are removed
+// while, for example, executable scripts stay executable.
+func WithClampModes(clamp bool) Option {
+	return func(s *Scafall) {
+		s.ClampModes = clamp
+	}
+}
+
+// ParseMode parses an octal file mode, such as 0644, for use with
+// WithFileMode and WithDirMode.
+func ParseMode(mode string) (os.FileMode, error) {
+	return internal.ParseMode(mode)
+}
+
+// Write prompts to out rather than to stdout, so that stdout can be kept for
+// machine readable output.  Answers are still read from stdin.
+func WithPromptOutput(out *os.File) Option {
+	return func(s *Scafall) {
+		s.PromptOutput = out
+	}
+}
+
+// Abandon any pending prompt when ctx is cancelled, for example when an IDE
+// dismisses the wizard that drives scafall.  Scaffolding then fails with
+// ErrPromptAborted and no project is created.
+func WithContext(ctx context.Context) Option {
+	return func(s *Scafall) {
+		s.Context = ctx
+	}
+}
+
+// Resolve values of the form name:key, in arguments, answers and the
+// .override.toml file of the template, using provider, so that secrets and
+// organisation constants need not be written to answer files.  Values that do
+// not name a registered provider are used as they are.
+func WithValueProvider(name string, provider ValueProvider) Option {
+	return func(s *Scafall) {
+		providers := map[string]ValueProvider{name: provider}
+		for n, p := range s.Providers {
+			if n != name {
+				providers[n] = p
+			}
+		}
+		s.Providers = providers
+	}
+}
+
+// Check the answers to the prompt name with validator, in addition to the
+// choices, type and pattern of the prompt, such as to enforce the rules for a
+// buildpack ID.  The validator is given the normalized answer: numbers and
+// dates in canonical form and list items one per line.  Answers typed by the
+// end-user are asked again when rejected; arguments, answers and defaults
+// that are rejected fail scaffolding.
+func WithValidator(name string, validator func(string) error) Option {
+	return func(s *Scafall) {
+		validators := map[string]Validator{name: validator}
+		for n, v := range s.Validators {
+			if n != name {
+				validators[n] = v
+			}
+		}
+		s.Validators = validators
+	}
+}
+
+// Match arguments, answers and facts about an existing project against the
+// choices of prompts with matching, such as ignoring case, so that values
+// provided by CI systems that differ from a choice only in case or white
+// space are used rather than rejected or asked for.  A matched value is
+// replaced by the choice as written in the template.
+func WithChoiceMatching(matching ChoiceMatching) Option {
+	return func(s *Scafall) {
+		s.Matching = matching
+	}
+}
+
+// Ask prompts in language, such as fr or pt-BR, using the translations in the
+// labels of the prompts.  A prompt without a translation into language, or
+// into the language without its region, is asked as it is written.  When no
+// language is set, the language is read from LC_ALL, LC_MESSAGES or LANG.
+func WithLanguage(language string) Option {
+	return func(s *Scafall) {
+		s.Language = language
+	}
+}
+
+// Resolve env:, file: and vault: references using the built in providers.
+// The vault provider reads VAULT_ADDR and VAULT_TOKEN.
+func WithDefaultValueProviders() Option {
+	return func(s *Scafall) {
+		for name, provider := range internal.DefaultValueProviders() {
+			WithValueProvider(name, provider)(s)
+		}
+	}
+}
+
 // Create a new Scafall with the given options.
 func NewScafall(url string, opts ...Option) (Scafall, error) {
 	var (
-		defaultArguments    = map[string]string{}
-		defaultOutputFolder = "."
+		defaultArguments = map[string]string{}
 	)
 
 	s := Scafall{
-		URL:          url,
-		Arguments:    defaultArguments,
-		OutputFolder: defaultOutputFolder,
+		URL:           url,
+		Arguments:     defaultArguments,
+		Submodules:    true,
+		RenderTimeout: internal.DefaultRenderTimeout,
 	}
+	if userCache, err := os.UserCacheDir(); err == nil {
+		s.TemplateCache = filepath.Join(userCache, internal.DefaultCacheDir)
+	}
+	policy, err := defaultPolicy()
+	if err != nil {
+		return s, err
+	}
+	s.Policy = policy
 
 	for _, opt := range opts {
 		opt(&s)
@@ -68,65 +630,262 @@ func NewScafall(url string, opts ...Option) (Scafall, error) {
 	return s, nil
 }
 
+// Create a new Scafall that reads the project template, or collection of
+// project templates, from fsys rather than from a url.  This allows templates
+// to be embedded in a program using go:embed; use the all: prefix so that
+// files such as .override.toml are embedded.
+func NewScafallFromFS(fsys fs.FS, opts ...Option) (Scafall, error) {
+	s, err := NewScafall("", opts...)
+	if err != nil {
+		return s, err
+	}
+	s.FS = fsys
+	return s, nil
+}
+
+// Create a new Scafall that reads the project template, or collection of
+// project templates, from a tar stream, which may be gzip compressed.  This
+// allows templates to be piped between programs.
+func NewScafallFromReader(r io.Reader, opts ...Option) (Scafall, error) {
+	s, err := NewScafall("", opts...)
+	if err != nil {
+		return s, err
+	}
+	s.Reader = r
+	return s, nil
+}
+
 // Scaffold accepts url containing project templates and creates an output
 // project.  The url can either point to a project template or a collection of
 // project templates.
 func (s Scafall) Scaffold() error {
+	_, err := s.ScaffoldWithResult()
+	return err
+}
+
+// ScaffoldWithResult creates an output project in the same way as Scaffold
+// and describes the created project.
+func (s Scafall) ScaffoldWithResult() (Result, error) {
+	result := Result{OutputFolder: s.OutputFolder}
 	err := s.clone()
 	if err != nil {
-		s.cleanUp()
-		return err
+		return result, err
 	}
-	inFs := s.CloneCache
-	if isCollection, options := internal.IsCollection(inFs); isCollection {
-		question := survey.Select{
-			Message: "choose a project template",
-			Options: options,
+	// the fetched template is removed however scaffolding ends, including
+	// when the end-user aborts a prompt
+	defer s.cleanUp()
+	chosen, err := s.chooseTemplate()
+	if err != nil {
+		return result, err
+	}
+	inFs := path.Join(s.CloneCache, chosen)
+	result.Template = chosen
+	err = s.verifyChecksum(inFs)
+	if err != nil {
+		return result, err
+	}
+	err = s.checkPolicy(inFs)
+	if err != nil {
+		return result, err
+	}
+
+	values, err := s.values(inFs)
+	if err != nil {
+		return result, err
+	}
+	err = s.resolveOutputFolder(values)
+	if err != nil {
+		return result, err
+	}
+	result.OutputFolder = s.OutputFolder
+	// paths are rendered before binary files are moved out of the template
+	renames, err := s.renamedPaths(inFs, values)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to scaffold new project")
+	}
+	err = s.confirm(inFs, values, renames)
+	if err != nil {
+		return result, err
+	}
+	// links are found before the files of the template are moved
+	result.Warnings, err = s.warnings(inFs)
+	if err != nil {
+		return result, err
+	}
+	if s.DryRun {
+		result.Tree, err = s.dryRun(inFs, values)
+		if err != nil {
+			return result, errors.Wrap(err, "failed to scaffold new project")
 		}
-		response := struct {
-			Template string
-		}{
-			Template: "",
+		result.Renames = renames
+		result.Variables, err = s.recordedValues(inFs, values)
+		return result, err
+	}
+	_, statErr := os.Stat(s.OutputFolder)
+	created := os.IsNotExist(statErr)
+	err = s.apply(inFs, values)
+	if err != nil {
+		err = errors.Wrap(err, "failed to scaffold new project")
+		if s.checkpoint(chosen, values, err) {
+			return result, ResumableError{Err: err}
 		}
-		err := survey.AskOne(&question, response, survey.WithValidator(survey.Required))
-		if err != nil {
-			s.cleanUp()
-			return err
+		// a half-written project that cannot be resumed is removed, unless
+		// it was written into an existing folder
+		if created {
+			os.RemoveAll(s.OutputFolder)
 		}
-		inFs = path.Join(s.CloneCache, response.Template)
+		return result, err
+	}
+	result.Renames = renames
+	result.Variables, err = s.recordedValues(inFs, values)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Plan prompts for the value of every template variable and records the
+// values, together with a digest of the template, in a Plan.  No project is
+// created; use ApplyPlan to create the project.
+func (s Scafall) Plan() (Plan, error) {
+	plan := Plan{URL: s.URL, Ref: s.Ref, SubPath: s.SubPath}
+	err := s.clone()
+	if err != nil {
+		return plan, err
 	}
+	defer os.RemoveAll(s.CloneCache)
 
-	err = internal.Create(inFs, s.Arguments, s.OutputFolder)
+	chosen, err := s.chooseTemplate()
 	if err != nil {
-		s.cleanUp()
+		return plan, err
 	}
+	inFs := path.Join(s.CloneCache, chosen)
+	plan.Template = chosen
 
-	return err
+	err = s.verifyChecksum(inFs)
+	if err != nil {
+		return plan, err
+	}
+	err = s.checkPolicy(inFs)
+	if err != nil {
+		return plan, err
+	}
+	plan.Digest, err = internal.Digest(inFs)
+	if err != nil {
+		return plan, err
+	}
+	values, err := s.values(inFs)
+	if err != nil {
+		return plan, err
+	}
+	plan.Variables, err = s.recordedValues(inFs, values)
+	return plan, err
 }
 
-// TemplateArguments returns a list of variable names that can be passed to the template
-func (s Scafall) TemplateArguments() (string, []string, error) {
+// CheckAnswers checks the answers provided by WithAnswers against the
+// template without prompting or creating a project.  Every answer that is not
+// a valid answer to its prompt, and every required prompt that is neither
+// answered nor has a default, is listed in an AnswerError.  A template of a
+// collection must be chosen using WithTemplate.
+func (s Scafall) CheckAnswers() error {
 	err := s.clone()
 	if err != nil {
-		return "", nil, err
+		return err
 	}
-	inFs := s.CloneCache
-	if isCollection, choices := internal.IsCollection(inFs); isCollection {
-		return "templates available in collection", choices, nil
+	defer os.RemoveAll(s.CloneCache)
+
+	s.NoPrompt = true
+	chosen, err := s.chooseTemplate()
+	if err != nil {
+		return err
+	}
+	inFs := path.Join(s.CloneCache, chosen)
+	err = s.checkPolicy(inFs)
+	if err != nil {
+		return err
 	}
+	return internal.CheckAnswers(inFs, s.arguments(), s.Answers, s.Providers, s.Validators, s.Matching, internal.Functions(s.TemplateFuncs))
+}
 
-	promptFile := filepath.Join(inFs, internal.PromptFile)
-	p, err := os.Open(promptFile)
+// ApplyPlan creates the project recorded in plan without prompting.  The
+// template is fetched again and no project is created if the template no
+// longer matches the digest recorded in the plan.  Answers to prompts marked
+// record = false are not in the plan and are provided with WithArguments.
+func ApplyPlan(plan Plan, opts ...Option) (Result, error) {
+	opts = append([]Option{WithSubPath(plan.SubPath), WithGitRef(plan.Ref)}, opts...)
+	s, err := NewScafall(plan.URL, opts...)
 	if err != nil {
-		s.cleanUp()
-		return "", nil, err
+		return Result{}, err
+	}
+	result := Result{OutputFolder: s.OutputFolder, Template: plan.Template}
+
+	err = s.clone()
+	if err != nil {
+		return result, err
+	}
+	defer s.cleanUp()
+	// the template of the plan is checked in the same way as one chosen
+	// with WithTemplate, so a plan cannot name a folder outside the template
+	s.Template = plan.Template
+	s.NoPrompt = true
+	chosen, err := s.chooseTemplate()
+	if err != nil {
+		return result, err
+	}
+	inFs := path.Join(s.CloneCache, chosen)
+
+	digest, err := internal.Digest(inFs)
+	if err != nil {
+		return result, err
+	}
+	if digest != plan.Digest {
+		return result, fmt.Errorf("template %s has changed since it was planned: expected digest %s, found %s", plan.URL, plan.Digest, digest)
 	}
-	template, err := internal.NewTemplate(p, nil, nil)
+	err = s.checkPolicy(inFs)
+	if err != nil {
+		return result, err
+	}
+
+	values, err := s.planValues(inFs, plan.Variables)
+	if err != nil {
+		return result, err
+	}
+	err = s.resolveOutputFolder(values)
+	if err != nil {
+		return result, err
+	}
+	result.OutputFolder = s.OutputFolder
+	renames, err := s.renamedPaths(inFs, values)
+	if err != nil {
+		return result, err
+	}
+	result.Warnings, err = s.warnings(inFs)
+	if err != nil {
+		return result, err
+	}
+	err = s.apply(inFs, values)
+	if err != nil {
+		if s.checkpoint(plan.Template, values, err) {
+			return result, ResumableError{Err: err}
+		}
+		return result, err
+	}
+	result.Variables = plan.Variables
+	result.Renames = renames
+	return result, nil
+}
+
+// TemplateArguments returns a list of variable names that can be passed to the template
+func (s Scafall) TemplateArguments() (string, []string, error) {
+	choices, prompts, err := s.TemplatePrompts()
 	if err != nil {
-		s.cleanUp()
 		return "", nil, err
 	}
-	prompts := template.Arguments()
+	if choices != nil {
+		return "templates available in collection", choices, nil
+	}
+
 	argsStrings := make([]string, len(prompts))
 	for i, p := range prompts {
 		if len(p.Choices) == 0 {
@@ -135,14 +894,397 @@ func (s Scafall) TemplateArguments() (string, []string, error) {
 			cString := strings.Join(p.Choices, ", ")
 			argsStrings[i] = fmt.Sprintf("%s=%s (default: %s)", p.Name, cString, p.Choices[0])
 		}
+		if p.Help != "" {
+			argsStrings[i] += "\n\t\t" + p.Help
+		}
 	}
 	return "arguments offered by template", argsStrings, nil
 }
 
+// TemplatePrompts returns the prompts of the template.  Where the url points
+// to a collection of templates the names of the templates are returned
+// instead.
+func (s Scafall) TemplatePrompts() ([]string, []Prompt, error) {
+	err := s.clone()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer s.cleanUp()
+	inFs := s.CloneCache
+	if err := s.checkTemplate(); err != nil {
+		return nil, nil, err
+	}
+	if isCollection, choices := internal.IsCollection(inFs); isCollection {
+		return choices, nil, nil
+	}
+
+	template, err := internal.ReadTemplate(inFs, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, template.Arguments(), nil
+}
+
+// WriteAnswers writes an answers file for the template to w.  Every prompt
+// is listed with its help text and choices, and with its default answer
+// commented out, as a starting point for answers read by ReadAnswers.  Where
+// the url points to a collection of templates, the names of the templates are
+// returned and nothing is written.
+func (s Scafall) WriteAnswers(w io.Writer) ([]string, error) {
+	choices, prompts, err := s.TemplatePrompts()
+	if err != nil || choices != nil {
+		return choices, err
+	}
+	return nil, internal.WriteAnswers(w, s.URL, prompts)
+}
+
+// Ask the end-user to choose a template when the url points to a collection of
+// templates.  Returns the folder of the chosen template, or an empty string
+// when the url points to a single template.
+func (s Scafall) chooseTemplate() (string, error) {
+	if err := s.checkTemplate(); err != nil {
+		return "", err
+	}
+	isCollection, options := internal.IsCollection(s.CloneCache)
+	if !isCollection {
+		if s.Template != "" {
+			return "", fmt.Errorf("cannot use template %s, %s is not a collection of templates", s.Template, s.URL)
+		}
+		return "", nil
+	}
+	if s.Template != "" {
+		for _, option := range options {
+			if option == s.Template {
+				return option, nil
+			}
+		}
+		return "", fmt.Errorf("collection does not contain template %s; expected one of %s", s.Template, strings.Join(options, ", "))
+	}
+	if s.NoPrompt {
+		return "", fmt.Errorf("url points to a collection of templates, choose one of %s", strings.Join(options, ", "))
+	}
+
+	question := survey.Select{
+		Message: "choose a project template",
+		Options: options,
+	}
+	template := ""
+	err := s.ask(func() error {
+		return survey.AskOne(&question, &template, append(s.askOptions(), survey.WithValidator(survey.Required))...)
+	})
+	if err != nil {
+		return "", err
+	}
+	return template, nil
+}
+
+// Ask questions, abandoning them when the context of s is cancelled.  An
+// abandoned question is left waiting for stdin, so question must not write
+// to anything read after ask returns ErrPromptAborted.
+func (s Scafall) ask(question func() error) error {
+	if s.Context == nil {
+		return internal.PromptError(question())
+	}
+	if s.Context.Err() != nil {
+		return ErrPromptAborted
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- question()
+	}()
+	select {
+	case err := <-done:
+		return internal.PromptError(err)
+	case <-s.Context.Done():
+		return ErrPromptAborted
+	}
+}
+
+// Options passed to every prompt
+func (s Scafall) askOptions() []survey.AskOpt {
+	if s.PromptOutput == nil {
+		return nil
+	}
+	return []survey.AskOpt{survey.WithStdio(os.Stdin, s.PromptOutput, os.Stderr)}
+}
+
+// Verify that the template in inFs matches the pinned checksum, if any
+func (s Scafall) verifyChecksum(inFs string) error {
+	if s.Checksum == "" {
+		return nil
+	}
+	if !strings.HasPrefix(s.Checksum, internal.DigestAlgorithm+":") {
+		return fmt.Errorf("unsupported checksum %s; expected %s:<hex>", s.Checksum, internal.DigestAlgorithm)
+	}
+	digest, err := internal.Digest(inFs)
+	if err != nil {
+		return err
+	}
+	if digest != s.Checksum {
+		return fmt.Errorf("template %s does not match the pinned checksum: expected %s, found %s", s.URL, s.Checksum, digest)
+	}
+	return nil
+}
+
+// Check that the template in inFs requires only capabilities that are
+// provided and not disabled by the policy, and calls only the functions
+// allowed by the policy
+func (s Scafall) checkPolicy(inFs string) error {
+	template, err := internal.ReadTemplate(inFs, nil)
+	if err != nil {
+		return err
+	}
+	err = internal.CheckCapabilities(template.Requires(), s.Policy.Disable)
+	if err != nil {
+		return err
+	}
+	// placeholder templates cannot call functions
+	if template.Settings().Engine == internal.PlaceholderEngine {
+		return nil
+	}
+	return internal.CheckFunctions(inFs, s.Policy.Functions)
+}
+
+// The arguments together with the seed of the random functions, if any
+func (s Scafall) arguments() map[string]string {
+	if s.Seed == nil {
+		return s.Arguments
+	}
+	arguments := make(map[string]string, len(s.Arguments)+1)
+	for name, value := range s.Arguments {
+		arguments[name] = value
+	}
+	arguments[internal.SeedVariable] = strconv.FormatInt(*s.Seed, 10)
+	return arguments
+}
+
+// Find the value of every template variable, prompting for those that are
+// not provided as arguments unless prompting is disabled
+func (s Scafall) values(inFs string) (map[string]string, error) {
+	if s.NoPrompt {
+		return internal.DefaultValues(inFs, s.arguments(), s.Answers, s.Providers, s.Validators, s.Matching, internal.Functions(s.TemplateFuncs), s.facts())
+	}
+	var values map[string]string
+	err := s.ask(func() error {
+		var err error
+		language := s.Language
+		if language == "" {
+			language = internal.CurrentLanguage()
+		}
+		values, err = internal.AskValues(inFs, s.arguments(), s.Answers, s.Providers, s.Validators, s.Matching, internal.Functions(s.TemplateFuncs), s.facts(), language, s.askOptions()...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// The output path of every file of the template in inFs whose path uses
+// template variables
+func (s Scafall) renamedPaths(inFs string, values map[string]string) (map[string]string, error) {
+	template, err := s.readTemplate(inFs, values)
+	if err != nil {
+		return nil, err
+	}
+	return internal.RenamedPaths(inFs, values, template.Settings())
+}
+
+// Remove the answers to prompts that must not be recorded, such as
+// passwords, from values before they are reported or written to a plan
+func (s Scafall) recordedValues(inFs string, values map[string]string) (map[string]string, error) {
+	template, err := internal.ReadTemplate(inFs, nil)
+	if err != nil {
+		return nil, err
+	}
+	return internal.RecordedValues(values, template.Arguments()), nil
+}
+
+// Add the answers to prompts that are not recorded in a plan to the recorded
+// values.  The answers are provided as arguments or take the default of their
+// prompt, a required prompt must be provided.
+func (s Scafall) planValues(inFs string, recorded map[string]string) (map[string]string, error) {
+	template, err := s.readTemplate(inFs, nil)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for name, value := range recorded {
+		values[name] = value
+	}
+	missing := []string{}
+	for _, prompt := range template.Arguments() {
+		if _, ok := values[prompt.Name]; ok || prompt.Recorded() {
+			continue
+		}
+		if value, ok := s.Arguments[prompt.Name]; ok {
+			values[prompt.Name] = value
+			continue
+		}
+		if prompt.Required {
+			missing = append(missing, prompt.Name)
+			continue
+		}
+		values[prompt.Name], err = internal.RenderStringWith(prompt.Default, values, template.Settings().Functions)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(missing) != 0 {
+		return nil, fmt.Errorf("the plan does not record %s; provide the values as arguments", strings.Join(missing, ", "))
+	}
+	return values, nil
+}
+
+// Render the template in inFs to the output folder, writing a manifest when
+// requested
+func (s Scafall) apply(inFs string, values map[string]string) error {
+	if s.ChangedOnly && s.ManifestFile == "" {
+		return fmt.Errorf("only writing changed files requires a manifest")
+	}
+	template, err := s.readTemplate(inFs, values)
+	if err != nil {
+		return err
+	}
+	if s.StrictVars {
+		if err := internal.CheckUndeclared(inFs, values, template.Settings()); err != nil {
+			return err
+		}
+	}
+
+	var previous *internal.Manifest
+	if _, err := os.Stat(s.ManifestFile); s.ChangedOnly && err == nil {
+		manifest, err := internal.ReadManifest(s.ManifestFile)
+		if err != nil {
+			return err
+		}
+		previous = &manifest
+	}
+	manifest, err := internal.ApplyWithManifest(inFs, values, s.OutputFolder, template.Settings(), previous, s.RenderTimeout, s.HardLinks)
+	if err != nil {
+		return err
+	}
+	generated := make([]string, 0, len(manifest.Files)+1)
+	for file := range manifest.Files {
+		generated = append(generated, file)
+	}
+	if provenance := template.Settings().Provenance; provenance != nil {
+		if err := s.writeProvenance(inFs, *provenance, values, template.Settings().Engine); err != nil {
+			return err
+		}
+		file := provenance.File
+		if file == "" {
+			file = internal.DefaultProvenanceFile
+		}
+		if _, err := os.Stat(filepath.Join(s.OutputFolder, file)); err == nil {
+			generated = append(generated, filepath.ToSlash(file))
+		}
+	}
+	// a preview is rendered into a temporary folder, which is not the folder
+	// whose owners the entry would name
+	if codeOwners := template.Settings().CodeOwners; codeOwners != nil && !s.DryRun {
+		if err := internal.WriteCodeOwners(s.OutputFolder, *codeOwners, values); err != nil {
+			return err
+		}
+	}
+	policy := internal.ModePolicy{FileMode: s.FileMode, DirMode: s.DirMode, Clamp: s.ClampModes}
+	if err := internal.ApplyModePolicy(s.OutputFolder, generated, policy); err != nil {
+		return err
+	}
+	if s.ManifestFile != "" {
+		return internal.WriteManifest(manifest, s.ManifestFile)
+	}
+	return nil
+}
+
+// Render the template in inFs to a temporary folder and list the files and
+// folders of the project
+func (s Scafall) dryRun(inFs string, values map[string]string) ([]TreeEntry, error) {
+	tmpDir, err := os.MkdirTemp("", "scafall-dry-run")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	s.OutputFolder = tmpDir
+	s.ManifestFile = ""
+	s.ChangedOnly = false
+	if err := s.apply(inFs, values); err != nil {
+		return nil, err
+	}
+	return internal.ReadTree(tmpDir)
+}
+
+// Record the template in inFs in the provenance section of the output.  The
+// template is identified by its url and sub path, and by its ref or, when no
+// ref is given, by its digest.
+func (s Scafall) writeProvenance(inFs string, provenance internal.Provenance, values map[string]string, engine string) error {
+	url := s.URL
+	if url == "" {
+		url = "an embedded template"
+	}
+	subPath := s.SubPath
+	if chosen, err := filepath.Rel(s.CloneCache, inFs); err == nil && chosen != "." {
+		subPath = path.Join(subPath, filepath.ToSlash(chosen))
+	}
+	if subPath != "" {
+		url = url + "//" + subPath
+	}
+	ref := s.Ref
+	if ref == "" {
+		digest, err := internal.Digest(inFs)
+		if err != nil {
+			return err
+		}
+		ref = digest
+	}
+	return internal.WriteProvenance(s.OutputFolder, provenance, values, engine, url, ref)
+}
+
+// Read the template in inFs, whose files, paths and prompts can call the
+// functions added by WithTemplateFuncs
+func (s Scafall) readTemplate(inFs string, arguments map[string]string) (internal.Template, error) {
+	template, err := internal.ReadTemplate(inFs, arguments)
+	if err != nil {
+		return nil, err
+	}
+	return template.AddFunctions(internal.Functions(s.TemplateFuncs)), nil
+}
+
+// Facts about the project in the output folder, if there is one
+func (s Scafall) facts() map[string]string {
+	if s.OutputFolder == "" {
+		return map[string]string{}
+	}
+	return internal.Introspect(s.OutputFolder)
+}
+
+// Render the output folder using values, or name it after the project
+func (s *Scafall) resolveOutputFolder(values map[string]string) error {
+	if s.OutputFolder == "" {
+		s.OutputFolder = internal.DefaultOutputFolder(values, s.URL)
+		return nil
+	}
+	folder, err := internal.RenderStringWith(s.OutputFolder, values, internal.Functions(s.TemplateFuncs))
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to render output folder %s", s.OutputFolder))
+	}
+	s.OutputFolder = folder
+	return nil
+}
+
+// Explain when the url is neither a template nor a collection of templates
+func (s Scafall) checkTemplate() error {
+	err := internal.CheckTemplate(s.CloneCache)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("%s is neither a project template nor a collection of project templates", s.URL))
+	}
+	return nil
+}
+
+// Remove the fetched template
 func (s *Scafall) cleanUp() {
-	s.CloneCache = ""
 	os.RemoveAll(s.CloneCache)
-	os.RemoveAll(s.OutputFolder)
+	s.CloneCache = ""
 }
 
 func (s *Scafall) clone() error {
@@ -150,15 +1292,137 @@ func (s *Scafall) clone() error {
 		return nil
 	}
 
+	inFs, warnings, err := s.fetch(s.URL)
+	retry, askErr := s.askCredentials(err)
+	if askErr != nil {
+		return askErr
+	}
+	if retry {
+		inFs, warnings, err = s.fetch(s.URL)
+	}
+	if err == nil || len(s.Mirrors) == 0 {
+		s.CloneCache = inFs
+		s.fetchWarnings = warnings
+		return err
+	}
+	failures := []string{fmt.Sprintf("%s: %s", s.URL, err)}
+	for _, mirror := range s.Mirrors {
+		inFs, warnings, err = s.fetch(mirror)
+		if err == nil {
+			s.CloneCache = inFs
+			s.fetchWarnings = warnings
+			return nil
+		}
+		failures = append(failures, fmt.Sprintf("%s: %s", mirror, err))
+	}
+	return fmt.Errorf("failed to fetch template from any mirror\n%s", strings.Join(failures, "\n"))
+}
+
+// Ask the end-user for credentials when the template cannot be fetched
+// without them, reporting whether the fetch should be retried.  Credentials
+// are not asked for when prompting is disabled, were already provided or
+// would not be sent, as the template is not fetched over https.
+func (s *Scafall) askCredentials(err error) (bool, error) {
+	var authErr AuthError
+	if s.NoPrompt || s.HTTPPassword != "" || !errors.As(err, &authErr) || authErr.Authenticated || !strings.HasPrefix(authErr.URL, "https://") {
+		return false, nil
+	}
+
+	tokenHelp := "A personal access token or the password of your account"
+	if authErr.Variable != "" {
+		tokenHelp += fmt.Sprintf("; set %s to avoid this prompt", authErr.Variable)
+	}
+	credentials := struct {
+		Username string
+		Password string
+	}{}
+	questions := []*survey.Question{
+		{
+			Name:   "Username",
+			Prompt: &survey.Input{Message: fmt.Sprintf("%s requires authentication, username", authErr.URL), Help: "Leave empty when using an access token"},
+		},
+		{
+			Name:     "Password",
+			Prompt:   &survey.Password{Message: "Password or access token", Help: tokenHelp},
+			Validate: survey.Required,
+		},
+	}
+	askErr := s.ask(func() error {
+		return survey.Ask(questions, &credentials, s.askOptions()...)
+	})
+	if errors.Is(askErr, ErrPromptAborted) {
+		return false, askErr
+	}
+	if askErr != nil {
+		// without a terminal the credentials cannot be asked for, so the
+		// AuthError explains how to provide them
+		return false, nil
+	}
+	s.HTTPUsername = credentials.Username
+	s.HTTPPassword = credentials.Password
+	return true, nil
+}
+
+// Fetch the template at rawURL, which may contain a ref and sub path, into a
+// temporary folder, listing the parts of the template that were skipped
+func (s Scafall) fetch(rawURL string) (string, []Warning, error) {
+	url, ref := internal.SplitRef(rawURL)
+	url, subPath := internal.SplitSubPath(url)
+	url, webRef, webSubPath, err := internal.NormalizeURL(internal.ExpandURL(url))
+	if err != nil {
+		return "", nil, err
+	}
+	if ref == "" {
+		ref = webRef
+	}
+	subPath = path.Join(webSubPath, subPath)
+	if s.Ref != "" {
+		ref = s.Ref
+	}
+	// local, embedded and streamed templates are not fetched, so are not
+	// restricted
+	if _, local := internal.LocalPath(url); s.FS == nil && s.Reader == nil && !local {
+		if err := s.Policy.Check(url); err != nil {
+			return "", nil, err
+		}
+	}
+
 	tmpDir, err := os.MkdirTemp("", "scafall")
 	if err != nil {
-		return err
+		return "", nil, err
+	}
+	warnings := []Warning{}
+	inFs, err := internal.URLToFs(url, tmpDir, internal.FetchOptions{
+		SubPath:         path.Join(subPath, s.SubPath),
+		Ref:             ref,
+		Username:        s.HTTPUsername,
+		Password:        s.HTTPPassword,
+		CacheDir:        s.TemplateCache,
+		Offline:         s.Offline,
+		FS:              s.FS,
+		Reader:          s.Reader,
+		Submodules:      s.Submodules,
+		Proxy:           s.Proxy,
+		CABundle:        s.CABundle,
+		Progress:        s.FetchProgress,
+		HostKeyChecking: s.HostKeyMode,
+		Warn: func(warning Warning) {
+			warnings = append(warnings, warning)
+		},
+	})
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return "", nil, err
 	}
+	return inFs, warnings, nil
+}
 
-	fs, err := internal.URLToFs(s.URL, s.SubPath, tmpDir)
+// List the parts of the template in inFs that were skipped as it was fetched,
+// or that are written differently than they are in the template
+func (s Scafall) warnings(inFs string) ([]Warning, error) {
+	found, err := internal.FindWarnings(inFs)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	s.CloneCache = fs
-	return nil
+	return append(append([]Warning{}, s.fetchWarnings...), found...), nil
 }
@@ -0,0 +1,102 @@
+package scafall
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/scafall/pkg/internal"
+)
+
+// WorkspaceResult reports the outcome of a single component of a workspace.
+type WorkspaceResult struct {
+	URL string
+	// Path is the folder of the workspace in which the component is created
+	Path   string
+	Result Result
+	Err    error
+}
+
+// ScaffoldWorkspace reads a workspace file, which maps several templates to
+// folders of a single project such as api, web and infra, and creates every
+// component in its folder of the workspace.  The variables of the workspace
+// file, and the answers given for earlier components, answer the prompts of
+// the same name of later components so that a shared variable, such as the
+// project name, is asked at most once.  Arguments provided by WithArguments
+// take precedence over the variables of the workspace file, the arguments of
+// a component take precedence over both.
+//
+// Options, such as WithNoPrompt, apply to every component and
+// WithOutputFolder names the folder of the workspace in place of the
+// output-folder of the workspace file.  Components are created in order and
+// scaffolding stops at the first component that fails, a result is returned
+// for every component that was attempted.
+func ScaffoldWorkspace(workspaceFile string, opts ...Option) ([]WorkspaceResult, error) {
+	workspace, err := internal.ReadWorkspace(workspaceFile)
+	if err != nil {
+		return nil, err
+	}
+	common := Scafall{}
+	for _, opt := range opts {
+		opt(&common)
+	}
+	root := common.OutputFolder
+	if root == "" {
+		root = workspace.OutputFolder
+	}
+	if root == "" {
+		return nil, fmt.Errorf("%s file does not name an output folder; set output-folder or provide one", workspaceFile)
+	}
+
+	shared := map[string]string{}
+	for name, value := range workspace.Variables {
+		shared[name] = value
+	}
+	for name, value := range common.Arguments {
+		shared[name] = value
+	}
+
+	results := []WorkspaceResult{}
+	for _, component := range workspace.Components {
+		result := WorkspaceResult{URL: component.URL, Path: component.Path}
+		arguments := map[string]string{}
+		for name, value := range shared {
+			arguments[name] = value
+		}
+		for name, value := range component.Arguments {
+			arguments[name] = value
+		}
+
+		componentOpts := append([]Option{}, opts...)
+		componentOpts = append(componentOpts,
+			WithArguments(arguments),
+			WithSubPath(component.SubPath),
+			WithGitRef(component.Ref),
+			WithTemplate(component.Template),
+			WithOutputFolder(filepath.Join(root, component.Path)),
+		)
+		s, err := NewScafall(component.URL, componentOpts...)
+		if err == nil {
+			result.Result, err = s.ScaffoldWithResult()
+		}
+		result.Err = err
+		results = append(results, result)
+		if err != nil {
+			return results, errors.Wrap(err, fmt.Sprintf("failed to create component %s of the workspace", component.Path))
+		}
+
+		// answers are shared with later components, unless provided for
+		// this component alone
+		for name, value := range result.Result.Variables {
+			if _, ok := component.Arguments[name]; ok || strings.HasPrefix(name, internal.ReservedPrefix) {
+				continue
+			}
+			if _, ok := shared[name]; !ok {
+				shared[name] = value
+			}
+		}
+	}
+	return results, nil
+}
@@ -8,6 +8,8 @@ import (
 )
 
 func TestIntegration(t *testing.T) {
+	// templates and failed scaffolds are not cached in the cache of the user
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
 	// Run in sequence as the tests change the pwd
 	suite := spec.New("scafall integration", spec.Sequential(), spec.Report(report.Terminal{}))
 	suite("scafall", testIntegration)
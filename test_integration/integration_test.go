@@ -1,6 +1,7 @@
 package scafall_integration_test
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -101,6 +102,20 @@ func testIntegration(t *testing.T, when spec.G, it spec.S) {
 			h.AssertNotEq(t, 0, fi)
 		})
 
+		it("reports the renamed paths", func() {
+			s, _ := scafall.NewScafall(
+				"testdata/template_folder",
+				scafall.WithArguments(map[string]string{"duck": "quack", "crow": "caw"}),
+				scafall.WithOutputFolder(outputDir),
+			)
+			result, err := s.ScaffoldWithResult()
+			h.AssertNil(t, err)
+			h.AssertEq(t, result.Renames, map[string]string{
+				"{{.duck}}/{{.duck}}.go":  "quack/quack.go",
+				"{{.duck}}/{{.duck}}.jpg": "quack/quack.jpg",
+			})
+		})
+
 		it.After(func() {
 			os.RemoveAll(outputDir)
 		})
@@ -135,6 +150,119 @@ func testIntegration(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 
+	when("A collection is used without prompting", func() {
+		var (
+			outputDir string
+		)
+
+		it.Before(func() {
+			outputDir, _ = ioutil.TempDir("", "test")
+		})
+
+		it("creates a project from the chosen template", func() {
+			s, _ := scafall.NewScafall(
+				"testdata/collection",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithTemplate("one"),
+				scafall.WithArguments(map[string]string{"TestPrompt": "test"}),
+				scafall.WithNoPrompt(true),
+			)
+			result, err := s.ScaffoldWithResult()
+			h.AssertNil(t, err)
+			h.AssertEq(t, result.Template, "one")
+			h.AssertEq(t, result.OutputFolder, outputDir)
+
+			data, _ := ioutil.ReadFile(filepath.Join(outputDir, "template.go"))
+			h.AssertContains(t, string(data), "this is not a test")
+		})
+
+		it("fails for a template that is not in the collection", func() {
+			s, _ := scafall.NewScafall(
+				"testdata/collection",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithTemplate("three"),
+				scafall.WithNoPrompt(true),
+			)
+			_, err := s.ScaffoldWithResult()
+			h.AssertNotNil(t, err)
+		})
+
+		it.After(func() {
+			os.RemoveAll(outputDir)
+		})
+	})
+
+	when("A checksum is pinned", func() {
+		var (
+			outputDir string
+		)
+
+		it.Before(func() {
+			outputDir, _ = ioutil.TempDir("", "test")
+		})
+
+		it("creates a project from a matching template", func() {
+			s, _ := scafall.NewScafall("testdata/str_prompts")
+			plan, err := s.Plan()
+			h.AssertNil(t, err)
+
+			s, _ = scafall.NewScafall("testdata/str_prompts",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithChecksum(plan.Digest))
+			h.AssertNil(t, s.Scaffold())
+			_, err = os.Stat(filepath.Join(outputDir, "template.go"))
+			h.AssertNil(t, err)
+		})
+
+		it("does not create a project from a changed template", func() {
+			projectDir := filepath.Join(outputDir, "project")
+			s, _ := scafall.NewScafall("testdata/str_prompts",
+				scafall.WithOutputFolder(projectDir),
+				scafall.WithChecksum("sha256:0000"))
+			h.AssertNotNil(t, s.Scaffold())
+			_, err := os.Stat(filepath.Join(projectDir, "template.go"))
+			h.AssertNotNil(t, err)
+		})
+
+		it.After(func() {
+			os.RemoveAll(outputDir)
+		})
+	})
+
+	when("Mirrors are given", func() {
+		var (
+			outputDir string
+		)
+
+		it.Before(func() {
+			outputDir, _ = ioutil.TempDir("", "test")
+		})
+
+		it("uses a mirror when the url cannot be fetched", func() {
+			s, _ := scafall.NewScafall("testdata/missing",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithMirrors("testdata/also-missing", "testdata/str_prompts"))
+			h.AssertNil(t, s.Scaffold())
+
+			data, _ := ioutil.ReadFile(filepath.Join(outputDir, "template.go"))
+			h.AssertContains(t, string(data), "this is not a test")
+		})
+
+		it("reports every failure when no mirror can be fetched", func() {
+			s, _ := scafall.NewScafall("testdata/missing",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithMirrors("testdata/also-missing"))
+			err := s.Scaffold()
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "testdata/missing")
+			h.AssertContains(t, err.Error(), "testdata/also-missing")
+		})
+
+		it.After(func() {
+			os.RemoveAll(outputDir)
+		})
+	})
+
 	when("An invalid template is passed", func() {
 		it("reports template errors and does not output a project", func() {
 			brokenTemplate := "testdata/broken"
@@ -148,5 +276,264 @@ func testIntegration(t *testing.T, when spec.G, it spec.S) {
 			_, err = os.Stat(templateFile)
 			h.AssertNotNil(t, err)
 		})
+
+		it("does not offer to resume the scaffold", func() {
+			outputDir, _ := ioutil.TempDir("", "test")
+			defer os.RemoveAll(outputDir)
+
+			s, _ := scafall.NewScafall("testdata/broken", scafall.WithOutputFolder(outputDir), scafall.WithCheckpoints(true))
+			_, err := s.ScaffoldWithResult()
+			h.AssertNotNil(t, err)
+			var resumable scafall.ResumableError
+			h.AssertEq(t, errors.As(err, &resumable), false)
+			_, ok, err := scafall.FailedScaffold()
+			h.AssertNil(t, err)
+			h.AssertEq(t, ok, false)
+		})
+	})
+
+	when("A plan is applied", func() {
+		var (
+			outputDir string
+			planFile  string
+		)
+
+		it.Before(func() {
+			outputDir, _ = ioutil.TempDir("", "test")
+			planFile = filepath.Join(outputDir, "plan.toml")
+		})
+
+		it("creates the planned project", func() {
+			s, _ := scafall.NewScafall("testdata/str_prompts")
+			plan, err := s.Plan()
+			h.AssertNil(t, err)
+			h.AssertEq(t, plan.Variables, map[string]string{"TestPrompt": "test"})
+			h.AssertNil(t, scafall.WritePlan(plan, planFile))
+
+			plan, err = scafall.ReadPlan(planFile)
+			h.AssertNil(t, err)
+			projectDir := filepath.Join(outputDir, "project")
+			_, err = scafall.ApplyPlan(plan, scafall.WithOutputFolder(projectDir))
+			h.AssertNil(t, err)
+
+			data, _ := ioutil.ReadFile(filepath.Join(projectDir, "template.go"))
+			h.AssertContains(t, string(data), "this is not a test")
+		})
+
+		it("does not record answers to prompts marked record = false", func() {
+			templateDir := filepath.Join(outputDir, "template")
+			h.AssertNil(t, os.MkdirAll(templateDir, 0755))
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(templateDir, "prompts.toml"), []byte(`
+[[prompt]]
+name = "Name"
+prompt = "Name"
+
+[[prompt]]
+name = "Token"
+prompt = "Access token"
+required = true
+record = false
+`), 0644))
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(templateDir, "config"), []byte("{{.Name}}={{.Token}}"), 0644))
+
+			s, _ := scafall.NewScafall(templateDir, scafall.WithNoPrompt(true), scafall.WithArguments(map[string]string{"Name": "api", "Token": "s3cr3t"}))
+			plan, err := s.Plan()
+			h.AssertNil(t, err)
+			h.AssertEq(t, plan.Variables, map[string]string{"Name": "api"})
+
+			projectDir := filepath.Join(outputDir, "project")
+			_, err = scafall.ApplyPlan(plan, scafall.WithOutputFolder(projectDir))
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "the plan does not record Token")
+
+			result, err := scafall.ApplyPlan(plan, scafall.WithOutputFolder(projectDir), scafall.WithArguments(map[string]string{"Token": "s3cr3t"}))
+			h.AssertNil(t, err)
+			h.AssertEq(t, result.Variables, map[string]string{"Name": "api"})
+			data, _ := ioutil.ReadFile(filepath.Join(projectDir, "config"))
+			h.AssertEq(t, string(data), "api=s3cr3t")
+		})
+
+		it("does not create a project when the template has changed", func() {
+			s, _ := scafall.NewScafall("testdata/str_prompts")
+			plan, err := s.Plan()
+			h.AssertNil(t, err)
+			plan.Digest = "sha256:0000"
+
+			projectDir := filepath.Join(outputDir, "project")
+			_, err = scafall.ApplyPlan(plan, scafall.WithOutputFolder(projectDir))
+			h.AssertNotNil(t, err)
+			_, err = os.Stat(filepath.Join(projectDir, "template.go"))
+			h.AssertNotNil(t, err)
+		})
+
+		it("does not apply a template outside the fetched template", func() {
+			s, _ := scafall.NewScafall("testdata/str_prompts")
+			plan, err := s.Plan()
+			h.AssertNil(t, err)
+			plan.Template = "../str_prompts"
+
+			projectDir := filepath.Join(outputDir, "project")
+			_, err = scafall.ApplyPlan(plan, scafall.WithOutputFolder(projectDir))
+			h.AssertError(t, err, "cannot use template ../str_prompts")
+			_, err = os.Stat(filepath.Join(projectDir, "template.go"))
+			h.AssertNotNil(t, err)
+		})
+
+		it.After(func() {
+			os.RemoveAll(outputDir)
+		})
+	})
+
+	when("Confirmation is requested", func() {
+		var outputDir string
+
+		it.Before(func() {
+			outputDir, _ = ioutil.TempDir("", "test")
+		})
+
+		it.After(func() {
+			os.RemoveAll(outputDir)
+		})
+
+		it("creates the project without asking when prompting is disabled", func() {
+			projectDir := filepath.Join(outputDir, "project")
+			s, _ := scafall.NewScafall("testdata/str_prompts", scafall.WithOutputFolder(projectDir), scafall.WithConfirmation(true), scafall.WithNoPrompt(true))
+			h.AssertNil(t, s.Scaffold())
+
+			data, _ := ioutil.ReadFile(filepath.Join(projectDir, "template.go"))
+			h.AssertContains(t, string(data), "this is not a test")
+		})
+
+		it("does not ask to confirm a dry run", func() {
+			projectDir := filepath.Join(outputDir, "project")
+			s, _ := scafall.NewScafall("testdata/str_prompts", scafall.WithOutputFolder(projectDir), scafall.WithConfirmation(true), scafall.WithDryRun(true), scafall.WithArguments(map[string]string{"TestPrompt": "test"}))
+			result, err := s.ScaffoldWithResult()
+			h.AssertNil(t, err)
+			h.AssertTrue(t, len(result.Tree) > 0)
+			_, err = os.Stat(projectDir)
+			h.AssertNotNil(t, err)
+		})
+	})
+
+	when("A workspace is scaffolded", func() {
+		var outputDir string
+
+		it.Before(func() {
+			outputDir, _ = ioutil.TempDir("", "test")
+		})
+
+		it.After(func() {
+			os.RemoveAll(outputDir)
+		})
+
+		it("creates every component in its folder with the shared variables", func() {
+			workspaceFile := filepath.Join(outputDir, "workspace.toml")
+			content := `variables = { TestPrompt = "test" }
+
+[[component]]
+url = "testdata/collection"
+sub-path = "one"
+path = "api"
+
+[[component]]
+url = "testdata/no_override"
+path = "web"
+arguments = { TestPrompt = "drill" }
+`
+			h.AssertNil(t, ioutil.WriteFile(workspaceFile, []byte(content), 0600))
+
+			projectDir := filepath.Join(outputDir, "project")
+			results, err := scafall.ScaffoldWorkspace(workspaceFile, scafall.WithOutputFolder(projectDir), scafall.WithNoPrompt(true))
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(results), 2)
+			h.AssertEq(t, results[1].Result.OutputFolder, filepath.Join(projectDir, "web"))
+
+			data, _ := ioutil.ReadFile(filepath.Join(projectDir, "api", "template.go"))
+			h.AssertContains(t, string(data), "this is not a test")
+			data, _ = ioutil.ReadFile(filepath.Join(projectDir, "web", "template.go"))
+			h.AssertContains(t, string(data), "this is not a drill")
+		})
+
+		it("stops at the first component that fails", func() {
+			workspaceFile := filepath.Join(outputDir, "workspace.toml")
+			content := `output-folder = "` + filepath.ToSlash(filepath.Join(outputDir, "project")) + `"
+
+[[component]]
+url = "testdata/broken"
+path = "api"
+
+[[component]]
+url = "testdata/noprompts"
+path = "web"
+`
+			h.AssertNil(t, ioutil.WriteFile(workspaceFile, []byte(content), 0600))
+
+			results, err := scafall.ScaffoldWorkspace(workspaceFile, scafall.WithNoPrompt(true))
+			h.AssertNotNil(t, err)
+			h.AssertContains(t, err.Error(), "failed to create component api of the workspace")
+			h.AssertEq(t, len(results), 1)
+			_, err = os.Stat(filepath.Join(outputDir, "project", "web"))
+			h.AssertTrue(t, os.IsNotExist(err))
+		})
+	})
+
+	when("A matrix is tested", func() {
+		var (
+			matrixDir string
+		)
+
+		it.Before(func() {
+			matrixDir, _ = ioutil.TempDir("", "test")
+		})
+
+		it("reports the combinations that fail validation", func() {
+			matrixFile := filepath.Join(matrixDir, "matrix.toml")
+			ioutil.WriteFile(matrixFile, []byte(`
+validate = ["test -f quack/quack.go"]
+
+[matrix]
+duck = ["quack", "moo"]
+`), 0600)
+
+			s, _ := scafall.NewScafall("testdata/template_folder")
+			results, err := s.TestMatrix(matrixFile)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(results), 2)
+			h.AssertNil(t, results[0].Err)
+			h.AssertNotNil(t, results[1].Err)
+			h.AssertEq(t, results[1].Arguments, map[string]string{"duck": "moo"})
+			os.RemoveAll(results[1].OutputFolder)
+		})
+
+		it.After(func() {
+			os.RemoveAll(matrixDir)
+		})
+	})
+
+	when("A collection is browsed", func() {
+		it("lists each template of the collection", func() {
+			entries, err := scafall.ReadRegistry("testdata/collection")
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(entries), 2)
+			h.AssertEq(t, entries[0].Template, "one")
+			h.AssertEq(t, entries[1].URL, "testdata/collection")
+		})
+
+		it("previews a template of the collection", func() {
+			s, err := scafall.NewScafall("testdata/collection", scafall.WithTemplate("one"))
+			h.AssertNil(t, err)
+			preview, err := s.Preview()
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(preview.Templates), 0)
+			h.AssertTrue(t, len(preview.Prompts) != 0)
+		})
+
+		it("lists the templates when none is chosen", func() {
+			s, err := scafall.NewScafall("testdata/collection")
+			h.AssertNil(t, err)
+			preview, err := s.Preview()
+			h.AssertNil(t, err)
+			h.AssertEq(t, preview.Templates, []string{"one", "two"})
+		})
 	})
 }
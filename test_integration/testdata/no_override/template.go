@@ -0,0 +1 @@
+this is not a {{.TestPrompt}}
\ No newline at end of file